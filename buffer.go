@@ -14,6 +14,8 @@ type buffer struct {
 	last  States
 	state States
 	class Classes
+
+	limit int // maximum count of elements of Array or Object, no limit if it's not positive
 }
 
 const __ = -1
@@ -49,6 +51,7 @@ const (
 	ampersand    byte = '&'
 	pipe         byte = '|'
 	question     byte = '?'
+	tilde        byte = '~'
 )
 
 type (
@@ -305,6 +308,10 @@ tokenLoop:
 		case len(stack) != 0:
 			find = true
 			continue
+		case c == tilde && find && b.keysOperator(): // keys operator at the end of the path: `@.*~`
+			continue
+		case c == caret && find && b.parentOperator(): // parent operator after the dot: `@.a.^.b`
+			continue
 		case c == minus || c == plus:
 			if !find {
 				find = true
@@ -333,6 +340,25 @@ tokenLoop:
 	return nil
 }
 
+// keysOperator returns true if the tilde at the current position ends the path or its part, like `@.*~ == 'a'` or `@.*~.length`,
+// so it's not a part of the operator, like `~=`
+func (b *buffer) keysOperator() bool {
+	if b.index+1 >= b.length {
+		return true
+	}
+	switch b.data[b.index+1] {
+	case skipS, skipN, skipR, skipT, dot, bracketL, bracketR, parenthesesR, coma:
+		return true
+	}
+	return false
+}
+
+// parentOperator returns true if the caret at the current position is the parent step of the path, like `@.^.name`,
+// so it's not the operator, like `@.a ^ 1`
+func (b *buffer) parentOperator() bool {
+	return b.index > 0 && b.data[b.index-1] == dot && b.keysOperator()
+}
+
 // Builder for `Reverse Polish notation`
 func (b *buffer) rpn() (result rpn, err error) {
 	var (
@@ -351,7 +377,7 @@ func (b *buffer) rpn() (result rpn, err error) {
 			break
 		}
 		switch true {
-		case c == asterisk || c == division || c == minus || c == plus || c == caret || c == ampersand || c == pipe || c == signL || c == signG || c == signE || c == exclamation: // operations
+		case priorityChar[c] && isOperatorChar(c): // operations
 			if variable {
 				variable = false
 				current = string(c)
@@ -391,6 +417,13 @@ func (b *buffer) rpn() (result rpn, err error) {
 				stack = append(stack, current)
 				break
 			}
+			if c == exclamation { // unary negation, example: !@.foo, !(@.foo == 1 || @.bar == 2)
+				if b.index+1 < b.length && b.data[b.index+1] == signE {
+					return nil, b.errorSymbol()
+				}
+				stack = append(stack, "not")
+				break
+			}
 			if c != minus && c != plus {
 				return nil, b.errorSymbol()
 			}
@@ -451,6 +484,22 @@ func (b *buffer) rpn() (result rpn, err error) {
 			if !found { // have no parenthesesL
 				return nil, errorRequest("formula has no left parentheses")
 			}
+		case c == coma: // arguments separator: distance(@.from, @.to)
+			variable = false
+			found = false
+			for len(stack) > 0 {
+				temp = stack[len(stack)-1]
+				if temp == "(" {
+					found = true
+					break
+				}
+				stack = stack[:len(stack)-1]
+				result = append(result, temp)
+			}
+			if !found { // have no parenthesesL
+				return nil, errorRequest("formula has no left parentheses")
+			}
+			stack = append(stack, string(c))
 		default: // prefix functions or etc.
 			start = b.index
 			variable = true
@@ -469,7 +518,7 @@ func (b *buffer) rpn() (result rpn, err error) {
 			current = strings.ToLower(string(b.data[start:b.index]))
 			b.index--
 			if !variable {
-				if _, found = functions[current]; !found {
+				if !isFunction(current) {
 					return nil, errorRequest("wrong formula, '%s' is not a function", current)
 				}
 				stack = append(stack, current)
@@ -491,8 +540,7 @@ func (b *buffer) rpn() (result rpn, err error) {
 
 	for len(stack) > 0 {
 		temp = stack[len(stack)-1]
-		_, ok := functions[temp]
-		if priority[temp] == 0 && !ok { // operations only
+		if priority[temp] == 0 && !isFunction(temp) { // operations only
 			return nil, errorRequest("wrong formula, '%s' is not an operation or function", temp)
 		}
 		result = append(result, temp)
@@ -521,7 +569,7 @@ func (b *buffer) tokenize() (result tokens, err error) {
 			break
 		}
 		switch true {
-		case priorityChar[c]: // operations
+		case priorityChar[c] && isOperatorChar(c): // operations
 			if variable || (c != minus && c != plus) {
 				variable = false
 				current = string(c)
@@ -756,6 +804,42 @@ func (t tokens) slice(find string) []string {
 	return result
 }
 
+// isQuoted returns true if key is a quoted string, like: 'foo' or "foo"
+func isQuoted(key string) bool {
+	size := len(key)
+	return size > 1 && ((key[0] == quotes && key[size-1] == quotes) || (key[0] == quote && key[size-1] == quote))
+}
+
+// isGlob returns true if key is a bare key with the glob symbols: `*` or `?`
+func isGlob(key string) bool {
+	return key != "*" && !isQuoted(key) && !strings.HasPrefix(key, "(") && strings.ContainsAny(key, "*?")
+}
+
+// matchGlob reports whether name matches the glob pattern: `*` matches any sequence of characters, `?` matches any single character
+func matchGlob(pattern, name string) bool {
+	p, n := []rune(pattern), []rune(name)
+	i, j, star, next := 0, 0, -1, 0
+	for j < len(n) {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == n[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*': // remember the position to backtrack
+			star, next = i, j
+			i++
+		case star >= 0: // let the last star match one more character
+			next++
+			i, j = star+1, next
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
 func str(key string) (string, bool) {
 	bString := []byte(key)
 	from := len(bString)
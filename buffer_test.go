@@ -92,8 +92,14 @@ func TestBuffer_RPN(t *testing.T) {
 		{name: "example_8", value: "@.length-1", expected: []string{"@.length", "1", "-"}},
 		{name: "example_9", value: "@.length+-1", expected: []string{"@.length", "-1", "+"}},
 		{name: "example_10", value: "@.length/e", expected: []string{"@.length", "e", "/"}},
+		{name: "example_11", value: "@.price % 2 == 1", expected: []string{"@.price", "2", "%", "1", "=="}},
 		{name: "example_12", value: "123.456", expected: []string{"123.456"}},
 		{name: "example_13", value: " 123.456 ", expected: []string{"123.456"}},
+		{name: "negation", value: "!@.foo", expected: []string{"@.foo", "not"}},
+		{name: "negation group", value: "!(@.a == 1 || @.b == 2)", expected: []string{"@.a", "1", "==", "@.b", "2", "==", "||", "not"}},
+		{name: "negation double", value: "!!@.foo", expected: []string{"@.foo", "not", "not"}},
+		{name: "negation precedence", value: "!@.a && @.b", expected: []string{"@.a", "not", "@.b", "&&"}},
+		{name: "negation right", value: "@.a || !(@.b != 2)", expected: []string{"@.a", "@.b", "2", "!=", "not", "||"}},
 
 		{name: "1 /", value: "1 /", expected: []string{"1", "/"}},
 		{name: "1 + ", value: "1 + ", expected: []string{"1", "+"}},
@@ -139,6 +145,8 @@ func TestBuffer_RPNError(t *testing.T) {
 		{value: "e + q"},
 		{value: "foo(e)"},
 		{value: "++2"},
+		{value: "!= 2"},
+		{value: "(!= 2)"},
 		{value: ""},
 	}
 	for _, test := range tests {
@@ -241,3 +249,36 @@ func TestBuffer_Numeric(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "b*", name: "book", expected: true},
+		{pattern: "b*", name: "b", expected: true},
+		{pattern: "b*", name: "abook", expected: false},
+		{pattern: "*k", name: "book", expected: true},
+		{pattern: "*o*", name: "book", expected: true},
+		{pattern: "b*k", name: "bk", expected: true},
+		{pattern: "b*k", name: "books", expected: false},
+		{pattern: "b?ok", name: "book", expected: true},
+		{pattern: "b?ok", name: "bok", expected: false},
+		{pattern: "?", name: "é", expected: true},
+		{pattern: "??", name: "é", expected: false},
+		{pattern: "*a*b", name: "aaab", expected: true},
+		{pattern: "*a*b", name: "aaba", expected: false},
+		{pattern: "**", name: "", expected: true},
+		{pattern: "?*", name: "", expected: false},
+		{pattern: "[a]*", name: "[a]b", expected: true},
+		{pattern: "[a]*", name: "ab", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.name, func(t *testing.T) {
+			if result := matchGlob(test.pattern, test.name); result != test.expected {
+				t.Errorf("matchGlob(%q, %q) = %v, expected %v", test.pattern, test.name, result, test.expected)
+			}
+		})
+	}
+}
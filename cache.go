@@ -0,0 +1,140 @@
+package ajson
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	// defaultParseCacheSize is the default count of parsed JSONPath expressions, stored in cache
+	defaultParseCacheSize = 256
+	// defaultRegexpCacheSize is the count of compiled patterns of the regexp functions and operators, stored in cache
+	defaultRegexpCacheSize = 256
+)
+
+// lruCache is LRU cache of the values, keyed by string: parsed JSONPath commands or compiled patterns
+type lruCache struct {
+	mutex sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+// parsedPath is the parsed JSONPath, stored in cache: the commands and the flag of the path with several results, see isMultiple
+type parsedPath struct {
+	commands []string
+	multiple bool
+}
+
+type lruCacheItem struct {
+	key   string
+	value interface{}
+}
+
+var (
+	commandsCache = newLRUCache(defaultParseCacheSize)
+	regexpCache   = newLRUCache(defaultRegexpCacheSize)
+)
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// SetParseCacheSize set the maximum count of parsed JSONPath expressions, stored in the internal LRU cache.
+// Value less or equal to zero disables the cache.
+func SetParseCacheSize(size int) {
+	commandsCache.resize(size)
+}
+
+// ClearParseCache removes all parsed JSONPath expressions from the internal cache.
+func ClearParseCache() {
+	commandsCache.clear()
+}
+
+// parseJSONPath returns parsed commands of the path from the cache, or parse it and store the result.
+// Result slice is shared between all callers, so it must not be modified.
+func parseJSONPath(path string) (commands []string, err error) {
+	parsed, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.commands, nil
+}
+
+// parsePath returns parsed path from the cache, or parse and validate it and store the result, see parseJSONPath and ValidatePath
+func parsePath(path string) (*parsedPath, error) {
+	if value, ok := commandsCache.get(path); ok {
+		return value.(*parsedPath), nil
+	}
+	commands, err := ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, cmd := range commands {
+		if err = validateCommand(cmd); err != nil {
+			return nil, err
+		}
+	}
+	parsed := &parsedPath{commands: commands, multiple: isMultiple(commands)}
+	commandsCache.set(path, parsed)
+	return parsed, nil
+}
+
+func (c *lruCache) get(key string) (value interface{}, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruCacheItem).value, true
+}
+
+func (c *lruCache) set(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if element, ok := c.items[key]; ok {
+		element.Value.(*lruCacheItem).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruCacheItem{key: key, value: value})
+	c.shrink()
+}
+
+func (c *lruCache) resize(size int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.size = size
+	c.shrink()
+}
+
+func (c *lruCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *lruCache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// shrink removes the least recently used elements, while cache is overflowed. Mutex should be locked.
+func (c *lruCache) shrink() {
+	for c.order.Len() > 0 && c.order.Len() > c.size {
+		element := c.order.Back()
+		c.order.Remove(element)
+		delete(c.items, element.Value.(*lruCacheItem).key)
+	}
+}
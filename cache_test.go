@@ -0,0 +1,104 @@
+package ajson
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestLRUCache(t *testing.T) {
+	cache := newLRUCache(2)
+	cache.set("$.a", []string{"$", "a"})
+	cache.set("$.b", []string{"$", "b"})
+	if _, ok := cache.get("$.a"); !ok {
+		t.Errorf("get(): value for '$.a' not found")
+	}
+	cache.set("$.c", []string{"$", "c"})
+	if _, ok := cache.get("$.b"); ok {
+		t.Errorf("get(): least recently used value '$.b' wasn't removed")
+	}
+	if value, ok := cache.get("$.a"); !ok || !sliceEqual(value.([]string), []string{"$", "a"}) {
+		t.Errorf("get(): wrong value for '$.a': %v", value)
+	}
+	if value, ok := cache.get("$.c"); !ok || !sliceEqual(value.([]string), []string{"$", "c"}) {
+		t.Errorf("get(): wrong value for '$.c': %v", value)
+	}
+	cache.resize(1)
+	if cache.len() != 1 {
+		t.Errorf("resize(): wrong length: %d", cache.len())
+	}
+	if _, ok := cache.get("$.c"); !ok {
+		t.Errorf("get(): most recently used value '$.c' was removed")
+	}
+	cache.resize(0)
+	cache.set("$.d", []string{"$", "d"})
+	if cache.len() != 0 {
+		t.Errorf("set(): disabled cache stores values: %d", cache.len())
+	}
+}
+
+func TestSetParseCacheSize(t *testing.T) {
+	defer SetParseCacheSize(defaultParseCacheSize)
+	ClearParseCache()
+	SetParseCacheSize(3)
+	for i := 0; i < 10; i++ {
+		if _, err := parseJSONPath("$[" + strconv.Itoa(i) + "]"); err != nil {
+			t.Errorf("parseJSONPath(): unexpected error: %s", err)
+		}
+	}
+	if commandsCache.len() != 3 {
+		t.Errorf("SetParseCacheSize(): wrong length: %d", commandsCache.len())
+	}
+	ClearParseCache()
+	if commandsCache.len() != 0 {
+		t.Errorf("ClearParseCache(): wrong length: %d", commandsCache.len())
+	}
+	if _, err := parseJSONPath("$[1"); err == nil {
+		t.Errorf("parseJSONPath(): expected error")
+	}
+	if commandsCache.len() != 0 {
+		t.Errorf("parseJSONPath(): wrong path was cached")
+	}
+}
+
+func TestParseCache_concurrent(t *testing.T) {
+	defer ClearParseCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				path := "$.store.book[" + strconv.Itoa((i+j)%5) + "].price"
+				if _, err := JSONPath(jsonPathTestData, path); err != nil {
+					t.Errorf("JSONPath(): unexpected error: %s", err)
+					return
+				}
+				if j%5 == 0 {
+					SetParseCacheSize(j % 3)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	SetParseCacheSize(defaultParseCacheSize)
+}
+
+func BenchmarkJSONPath_cached(b *testing.B) {
+	paths := []string{"$.store.book[0].title", "$['store']['bicycle']['color']", "$.store.book[-1].isbn", "$.expensive"}
+	root := Must(Unmarshal(jsonPathTestData))
+	b.Run("cache", func(b *testing.B) {
+		SetParseCacheSize(defaultParseCacheSize)
+		for i := 0; i < b.N; i++ {
+			_, _ = root.JSONPath(paths[i%len(paths)])
+		}
+	})
+	b.Run("no cache", func(b *testing.B) {
+		SetParseCacheSize(0)
+		ClearParseCache()
+		defer SetParseCacheSize(defaultParseCacheSize)
+		for i := 0; i < b.N; i++ {
+			_, _ = root.JSONPath(paths[i%len(paths)])
+		}
+	})
+}
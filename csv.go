@@ -0,0 +1,82 @@
+package ajson
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sort"
+	"strconv"
+)
+
+// ToCSV returns slice of bytes, with given Object nodes presented as CSV table.
+//
+// Header row contains sorted union of keys of all nodes. Each node produces a row, where missing keys and Null values are blank,
+// strings are written as is, and nested Array or Object values are marshaled to JSON.
+// Returns WrongType error if any node is not an Object.
+func ToCSV(nodes []*Node) (result []byte, err error) {
+	index := make(map[string]bool)
+	for _, node := range nodes {
+		if node == nil || !node.IsObject() {
+			return nil, errorType()
+		}
+		for key := range node.children {
+			index[key] = true
+		}
+	}
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := new(bytes.Buffer)
+	writer := csv.NewWriter(buf)
+	if err = writer.Write(keys); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(keys))
+	for _, node := range nodes {
+		for i, key := range keys {
+			record[i], err = csvValue(node.children[key])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err = writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func csvValue(node *Node) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+	switch node.Type() {
+	case Null:
+		return "", nil
+	case Numeric:
+		value, err := node.GetNumeric()
+		if err != nil {
+			return "", err
+		}
+		return formatNumber(value), nil
+	case String:
+		return node.GetString()
+	case Bool:
+		value, err := node.GetBool()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(value), nil
+	}
+	value, err := Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
@@ -0,0 +1,60 @@
+package ajson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestToCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "empty", json: `[]`, expected: "\n"},
+		{name: "empty objects", json: `[{},{}]`, expected: "\n\n\n"},
+		{name: "flat", json: `[{"a":1,"b":"foo"},{"a":2,"b":"bar"}]`, expected: "a,b\n1,foo\n2,bar\n"},
+		{name: "heterogeneous", json: `[{"a":1},{"b":true},{"c":null,"a":1.5e3}]`, expected: "a,b,c\n1,,\n,true,\n1500,,\n"},
+		{name: "large numbers", json: `[{"a":1234567,"b":-98765432100,"c":0.5}]`, expected: "a,b,c\n1234567,-98765432100,0.5\n"},
+		{name: "nested", json: `[{"a":[1,2],"b":{"c":"d"}},{"a":[]}]`, expected: "a,b\n\"[1,2]\",\"{\"\"c\"\":\"\"d\"\"}\"\n[],\n"},
+		{name: "escaped", json: `[{"a":"foo, bar","b":"line\nbreak","c":"\"quoted\""}]`, expected: "a,b,c\n\"foo, bar\",\"line\nbreak\",\"\"\"quoted\"\"\"\n"},
+		{name: "not object", json: `[{"a":1},[1]]`, wantErr: true},
+		{name: "scalar", json: `[1]`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes := Must(Unmarshal([]byte(test.json))).MustArray()
+			result, err := ToCSV(nodes)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ToCSV() error = %v, wantErr %v", err, test.wantErr)
+				return
+			}
+			if !test.wantErr && string(result) != test.expected {
+				t.Errorf("ToCSV() wrong result:\nExpected: %q\nActual:   %q", test.expected, string(result))
+			}
+		})
+	}
+}
+
+func TestToCSV_errors(t *testing.T) {
+	if _, err := ToCSV([]*Node{nil}); err == nil {
+		t.Errorf("ToCSV() expected error on nil")
+	}
+	if _, err := ToCSV([]*Node{ObjectNode("", map[string]*Node{"a": valueNode(nil, "", Numeric, "foo")})}); err == nil {
+		t.Errorf("ToCSV() expected error on wrong value")
+	}
+}
+
+func ExampleToCSV() {
+	root := Must(Unmarshal(jsonPathTestData))
+	nodes, _ := root.JSONPath("$.store.book[*]")
+	result, _ := ToCSV(nodes)
+	fmt.Printf("%s", result)
+	// Output:
+	// author,category,isbn,price,title
+	// Nigel Rees,reference,,8.95,Sayings of the Century
+	// Evelyn Waugh,fiction,,12.99,Sword of Honour
+	// Herman Melville,fiction,0-553-21311-3,8.99,Moby Dick
+	// J. R. R. Tolkien,fiction,0-395-19395-8,22.99,The Lord of the Rings
+}
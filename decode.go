@@ -1,6 +1,8 @@
 package ajson
 
 import (
+	"bytes"
+
 	. "github.com/spyzhov/ajson/internal"
 )
 
@@ -19,17 +21,45 @@ const (
 	ec States = -9 /* curly br. empty */
 )
 
+// UnmarshalOption is an option of parsing data in Unmarshal
+type UnmarshalOption int
+
+const (
+	// UnmarshalNonFinite means that `Infinity`, `-Infinity` and `NaN` literals are allowed as Numeric values
+	UnmarshalNonFinite UnmarshalOption = iota
+)
+
+var (
+	_infinity    = []byte("Infinity")
+	_negInfinity = []byte("-Infinity")
+	_nan         = []byte("NaN")
+	_bom         = []byte("\xef\xbb\xbf") // UTF-8 byte order mark
+)
+
 // Unmarshal parses the JSON-encoded data and return the root node of struct.
 //
 // Doesn't calculate values, just type of stored value. It will store link to the data, on all life long.
-func Unmarshal(data []byte) (root *Node, err error) {
-	buf := newBuffer(data)
+func Unmarshal(data []byte, options ...UnmarshalOption) (root *Node, err error) {
+	return unmarshal(newBuffer(data), options...)
+}
+
+func unmarshal(buf *buffer, options ...UnmarshalOption) (root *Node, err error) {
 	var (
-		state   States
-		key     *string
-		current *Node
+		state     States
+		key       *string
+		current   *Node
+		nonFinite bool
+		literal   []byte
 	)
+	for _, option := range options {
+		if option == UnmarshalNonFinite {
+			nonFinite = true
+		}
+	}
 
+	if bytes.HasPrefix(buf.data, _bom) { // leading BOM, like in the files of some Windows tools
+		buf.index = len(_bom)
+	}
 	_, err = buf.first()
 	if err != nil {
 		return nil, buf.errorEOF()
@@ -38,7 +68,21 @@ func Unmarshal(data []byte) (root *Node, err error) {
 	for {
 		state = buf.getState()
 		if state == __ {
-			return nil, buf.errorSymbol()
+			if literal = buf.nonFinite(); !nonFinite || literal == nil || (buf.last != GO && buf.last != VA && buf.last != AR) {
+				return nil, buf.errorSymbol()
+			}
+			current, err = newNode(current, buf, Numeric, &key)
+			if err != nil {
+				return
+			}
+			buf.index += len(literal)
+			current.borders[1] = buf.index
+			buf.index -= 1
+			buf.state = OK
+			if current.parent != nil {
+				current = current.parent
+			}
+			state = OK
 		}
 
 		if state >= GO {
@@ -67,7 +111,11 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				if err != nil {
 					break
 				}
-				err = buf.numeric(false)
+				if nonFinite && buf.state == MI && bytes.HasPrefix(buf.data[buf.index:], _negInfinity) {
+					buf.index += len(_negInfinity)
+				} else {
+					err = buf.numeric(false)
+				}
 				current.borders[1] = buf.index
 				buf.index -= 1
 				buf.state = OK
@@ -184,10 +232,31 @@ func Unmarshal(data []byte) (root *Node, err error) {
 }
 
 // UnmarshalSafe do the same thing as Unmarshal, but copy data to the local variable, to make it editable.
-func UnmarshalSafe(data []byte) (root *Node, err error) {
+func UnmarshalSafe(data []byte, options ...UnmarshalOption) (root *Node, err error) {
+	var safe []byte
+	safe = append(safe, data...)
+	return Unmarshal(safe, options...)
+}
+
+// UnmarshalLimited do the same thing as UnmarshalSafe, but returns an error if any Array or Object has more than limit elements.
+// It protects from the data with a huge container, even if it's not deep. Not positive limit means no limit.
+// Element over the limit is reported as WrongSymbol error with its index, like any other parse failure.
+func UnmarshalLimited(data []byte, limit int, options ...UnmarshalOption) (root *Node, err error) {
 	var safe []byte
 	safe = append(safe, data...)
-	return Unmarshal(safe)
+	buf := newBuffer(safe)
+	buf.limit = limit
+	return unmarshal(buf, options...)
+}
+
+// nonFinite returns the literal of the non-finite number at the current position: `Infinity` or `NaN`
+func (b *buffer) nonFinite() []byte {
+	for _, literal := range [][]byte{_infinity, _nan} {
+		if bytes.HasPrefix(b.data[b.index:], literal) {
+			return literal
+		}
+	}
+	return nil
 }
 
 // Must returns a Node if there was no error. Else - panic with error as the value.
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -422,6 +423,60 @@ func TestUnmarshalSafe(t *testing.T) {
 	}
 }
 
+func TestUnmarshalLimited(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		limit int
+		err   string
+	}{
+		{name: "array on the limit", input: `[1, 2, 3]`, limit: 3},
+		{name: "array over the limit", input: `[1, 2, 3, 4]`, limit: 3, err: "wrong symbol '4' at 10"},
+		{name: "object on the limit", input: `{"a": 1, "b": 2}`, limit: 2},
+		{name: "object over the limit", input: `{"a": 1, "b": 2, "c": 3}`, limit: 2, err: "wrong symbol '3' at 22"},
+		{name: "duplicated keys", input: `{"a": 1, "a": 2, "a": 3}`, limit: 1},
+		{name: "nested", input: `[[1, 2], {"a": [1, 2, 3]}]`, limit: 2, err: "wrong symbol '3' at 22"},
+		{name: "nested containers", input: `[[], {}, []]`, limit: 2, err: "wrong symbol '[' at 9"},
+		{name: "deep on the limit", input: `[[[[[[1]]]]]]`, limit: 1},
+		{name: "no limit", input: `[1, 2, 3, 4]`, limit: 0},
+		{name: "wrong data", input: `[1, 2`, limit: 10, err: "unexpected end of file"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalLimited([]byte(test.input), test.limit)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("UnmarshalLimited() wrong error: %v, expected: %s", err, test.err)
+				}
+				if root != nil {
+					t.Errorf("UnmarshalLimited() wrong result: %s", root)
+				}
+			} else if err != nil {
+				t.Errorf("UnmarshalLimited() unexpected error: %s", err)
+			} else if expected := Must(Unmarshal([]byte(test.input))); !bytes.Equal(root.Source(), expected.Source()) {
+				t.Errorf("UnmarshalLimited() wrong result: %s", root)
+			}
+		})
+	}
+
+	if _, err := UnmarshalLimited([]byte(`[1, 2, 3]`), 2); err == nil {
+		t.Errorf("UnmarshalLimited() expected error")
+	} else if value, ok := err.(Error); !ok || value.Type != WrongSymbol || value.Index != 7 || value.Char != '3' {
+		t.Errorf("UnmarshalLimited() wrong error: %#v", err)
+	}
+
+	data := []byte(`[NaN, Infinity]`)
+	if _, err := UnmarshalLimited(data, 2, UnmarshalNonFinite); err != nil {
+		t.Errorf("UnmarshalLimited() unexpected error: %s", err)
+	}
+	if _, err := UnmarshalLimited(data, 1, UnmarshalNonFinite); err == nil {
+		t.Errorf("UnmarshalLimited() expected error on the non-finite numbers")
+	}
+	if root := Must(UnmarshalLimited(data, 2, UnmarshalNonFinite)); &root.Source()[0] == &data[0] {
+		t.Errorf("UnmarshalLimited() data is not copied")
+	}
+}
+
 func TestUnmarshal_Must(t *testing.T) {
 	root, err := Unmarshal(jsonExample)
 	if err != nil {
@@ -2893,3 +2948,112 @@ line"]`)},
 		})
 	}
 }
+
+func TestUnmarshal_NonFinite(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		check func(value float64) bool
+	}{
+		{name: "Infinity", input: `Infinity`, check: func(value float64) bool { return math.IsInf(value, 1) }},
+		{name: "-Infinity", input: `-Infinity`, check: func(value float64) bool { return math.IsInf(value, -1) }},
+		{name: "NaN", input: `NaN`, check: math.IsNaN},
+		{name: "array", input: `[1, Infinity, -Infinity,NaN]`, check: math.IsNaN},
+		{name: "object", input: `{"a": -Infinity, "b": NaN, "c": Infinity }`, check: func(value float64) bool { return math.IsInf(value, 1) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Unmarshal([]byte(test.input)); err == nil {
+				t.Errorf("Unmarshal() expected error without the option")
+			}
+			root, err := Unmarshal([]byte(test.input), UnmarshalNonFinite)
+			if err != nil {
+				t.Errorf("Unmarshal() unexpected error: %s", err)
+				return
+			}
+			node := root
+			if root.IsContainer() {
+				node = root.Inheritors()[root.Size()-1]
+			}
+			if value, err := node.GetNumeric(); err != nil {
+				t.Errorf("GetNumeric() unexpected error: %s", err)
+			} else if !test.check(value) {
+				t.Errorf("GetNumeric() wrong value: %v", value)
+			}
+
+			if value, err := Marshal(root); err != nil || string(value) != test.input {
+				t.Errorf("Marshal() of the unmodified node: %s, %v", value, err)
+			}
+			if _, err := Marshal(root, MarshalNormalizeNumbers); err == nil {
+				t.Errorf("Marshal() expected error on non-finite number")
+			}
+			value, err := Marshal(root, MarshalNormalizeNumbers, MarshalNonFinite)
+			if err != nil {
+				t.Errorf("Marshal() unexpected error: %s", err)
+				return
+			}
+			again, err := Unmarshal(value, UnmarshalNonFinite)
+			if err != nil {
+				t.Errorf("Unmarshal() of the marshaled value %s: %s", value, err)
+			} else if result, err := Marshal(again); err != nil || !bytes.Equal(result, value) {
+				t.Errorf("Marshal() wrong round-trip: %s, expected %s", result, value)
+			}
+		})
+	}
+
+	errorsTests := []string{`Inf`, `infinity`, `nan`, `Infinityx`, `[NaN NaN]`, `{NaN: 1}`, `{"a" NaN}`, `- Infinity`, `1 NaN`, `"a": NaN`}
+	for _, input := range errorsTests {
+		if _, err := Unmarshal([]byte(input), UnmarshalNonFinite); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", input)
+		}
+	}
+
+	for _, value := range []float64{math.Inf(1), math.Inf(-1), math.NaN()} {
+		node := NumericNode("", value)
+		if _, err := Marshal(node); err == nil {
+			t.Errorf("Marshal(%v) expected error", value)
+		}
+		if result, err := Marshal(ArrayNode("", []*Node{node}), MarshalNonFinite); err != nil {
+			t.Errorf("Marshal(%v) unexpected error: %s", value, err)
+		} else if again := Must(Unmarshal(result, UnmarshalNonFinite)).MustIndex(0).MustNumeric(); !(again == value || (math.IsNaN(again) && math.IsNaN(value))) {
+			t.Errorf("Marshal(%v) wrong round-trip: %s", value, result)
+		}
+	}
+}
+
+func TestUnmarshal_BOM(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "object", input: `{"a": [1, "b"]}`},
+		{name: "array", input: ` [true, null] `},
+		{name: "string", input: `"\ufeff"`},
+		{name: "numeric", input: `1.5`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := Unmarshal([]byte(test.input))
+			if err != nil {
+				t.Errorf("Unmarshal() unexpected error without BOM: %s", err)
+				return
+			}
+			root, err := Unmarshal(append([]byte("\xef\xbb\xbf"), test.input...))
+			if err != nil {
+				t.Errorf("Unmarshal() unexpected error with BOM: %s", err)
+				return
+			}
+			if ok, err := root.Eq(expected); err != nil || !ok {
+				t.Errorf("Wrong value with BOM: %s, expected: %s", root, expected)
+			}
+			if string(root.Source()) != string(expected.Source()) {
+				t.Errorf("Wrong source with BOM: %s", root.Source())
+			}
+		})
+	}
+	for _, input := range []string{"\xef\xbb\xbf", "\xef\xbb\xbf\xef\xbb\xbf1", "\xef\xbb1", "1\xef\xbb\xbf"} {
+		if _, err := Unmarshal([]byte(input)); err == nil {
+			t.Errorf("Unmarshal(%q) expected error", input)
+		}
+	}
+}
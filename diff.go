@@ -0,0 +1,52 @@
+package ajson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DiffString returns a readable line-oriented report of the changes from a to b, one line per change,
+// like `+ $['added']: 1`, `- $['removed']: 2` and `~ $['changed']: 1 -> 2`.
+//
+// Paths are the same as the Node.Path returns, values are compact JSON. Objects are compared recursively by keys in sorted order,
+// Arrays - by indexes, so the report is deterministic. Any other change of the node, like a change of the type, is reported as `~`.
+// Nil node is treated as absent. Report is empty, if the nodes are equal.
+func DiffString(a, b *Node) string {
+	lines := make([]string, 0)
+	diff("$", a, b, &lines)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func diff(path string, a, b *Node, lines *[]string) {
+	switch {
+	case equalNodes(a, b):
+	case a == nil:
+		*lines = append(*lines, "+ "+path+": "+string(b.Bytes()))
+	case b == nil:
+		*lines = append(*lines, "- "+path+": "+string(a.Bytes()))
+	case a.IsObject() && b.IsObject():
+		keys := make(map[string]*Node, len(a.children)+len(b.children))
+		for _, node := range []*Node{a, b} {
+			for key, child := range node.children {
+				keys[key] = child
+			}
+		}
+		for _, key := range sortedKeys(keys) {
+			diff(path+"['"+keyReplacer.Replace(key)+"']", a.children[key], b.children[key], lines)
+		}
+	case a.IsArray() && b.IsArray():
+		size := a.Size()
+		if b.Size() > size {
+			size = b.Size()
+		}
+		for i := 0; i < size; i++ {
+			key := strconv.Itoa(i)
+			diff(path+"["+key+"]", a.children[key], b.children[key], lines)
+		}
+	default:
+		*lines = append(*lines, "~ "+path+": "+string(a.Bytes())+" -> "+string(b.Bytes()))
+	}
+}
@@ -0,0 +1,47 @@
+package ajson
+
+import "testing"
+
+func TestDiffString(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{name: "equal", a: `{"a": [1, {"b": null}]}`, b: `{"a":[1,{"b":null}]}`, expected: ""},
+		{name: "added", a: `{"a": 1}`, b: `{"a": 1, "added": {"x": [1, 2]}}`, expected: "+ $['added']: {\"x\":[1,2]}\n"},
+		{name: "removed", a: `{"a": 1, "removed": 2}`, b: `{"a": 1}`, expected: "- $['removed']: 2\n"},
+		{name: "changed", a: `{"changed": 1}`, b: `{"changed": 2}`, expected: "~ $['changed']: 1 -> 2\n"},
+		{name: "type changed", a: `{"a": {"b": 1}}`, b: `{"a": [1]}`, expected: "~ $['a']: {\"b\":1} -> [1]\n"},
+		{name: "nested", a: `{"a": {"b": {"c": "x"}}}`, b: `{"a": {"b": {"c": "y"}}}`, expected: "~ $['a']['b']['c']: \"x\" -> \"y\"\n"},
+		{name: "array elements", a: `[1, 2, 3]`, b: `[1, 5]`, expected: "~ $[1]: 2 -> 5\n- $[2]: 3\n"},
+		{name: "array appended", a: `{"list": []}`, b: `{"list": [true]}`, expected: "+ $['list'][0]: true\n"},
+		{name: "root", a: `1`, b: `"1"`, expected: "~ $: 1 -> \"1\"\n"},
+		{name: "quoted key", a: `{"it's": 1}`, b: `{}`, expected: "- $['it\\'s']: 1\n"},
+		{
+			name:     "sorted",
+			a:        `{"z": 1, "m": 2, "b": {"y": 1}}`,
+			b:        `{"a": 0, "m": 3, "b": {"x": 1, "y": 1}}`,
+			expected: "+ $['a']: 0\n+ $['b']['x']: 1\n~ $['m']: 2 -> 3\n- $['z']: 1\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := DiffString(Must(Unmarshal([]byte(test.a))), Must(Unmarshal([]byte(test.b))))
+			if actual != test.expected {
+				t.Errorf("DiffString() wrong result\nExpected: %q\nActual:   %q", test.expected, actual)
+			}
+		})
+	}
+
+	node := Must(Unmarshal([]byte(`{"a": 1}`)))
+	if actual := DiffString(nil, node); actual != "+ $: {\"a\":1}\n" {
+		t.Errorf("DiffString() wrong result of the nil node: %q", actual)
+	}
+	if actual := DiffString(node, nil); actual != "- $: {\"a\":1}\n" {
+		t.Errorf("DiffString() wrong result of the nil node: %q", actual)
+	}
+	if actual := DiffString(nil, nil); actual != "" {
+		t.Errorf("DiffString() wrong result of the nil nodes: %q", actual)
+	}
+}
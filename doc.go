@@ -6,6 +6,7 @@
 //
 // Each Node has it's own type and calculated value, which will be calculated on demand.
 // Calculated value saves in atomic.Value, so it's thread safe.
+// Read-only methods are safe for concurrent use on the unmodified tree, use Node.Freeze to make the tree immutable.
 //
 // Method JSONPath will returns slice of founded elements in current JSON data, by it's JSONPath.
 //
@@ -37,14 +38,20 @@
 //
 //    $          the root object/element
 //    @          the current object/element
+//    @root      the root object/element in the expressions, the same as `$`
+//    @parent    the parent of the current object/element in the expressions, i.e. the filtered container in `?(@.price > @parent.avg)`; null for the root
 //    . or []  child operator
-//    ..      recursive descent. JSONPath borrows this syntax from E4X.
-//    *       wildcard. All objects/elements regardless their names.
+//    ..      recursive descent. JSONPath borrows this syntax from E4X. Each node is returned only once, even for the chained descents like `$..a..b`.
+//    *       wildcard. All objects/elements regardless their names. Children of Object are selected in order of the sorted keys, like in InheritorsSorted.
+//    b*      glob in the key of object: `*` matches any sequence of characters, `?` matches any single character. Quoted keys are matched exactly.
 //    []      subscript operator. XPath uses it to iterate over element collections and for predicates. In Javascript and JSON it is the native array operator.
-//    [,]     Union operator in XPath results in a combination of node sets. JSONPath allows alternate names or array indices as a set.
+//    [,]     Union operator in XPath results in a combination of node sets. JSONPath allows alternate names, array indices or slices as a set, like `[0,2:4]`. Each matched node is returned only once.
 //    [start:end:step]  array slice operator borrowed from ES4.
 //    ?()     applies a filter (script) expression.
 //    ()      script expression, using the underlying script engine.
+//    .length pseudo-property: size of array/object or length of string. Real field of object named `length` has a priority.
+//    ~       keys operator: names of the found members of objects as strings, or indexes of the found elements of arrays as numbers, like `$.store.*~`. Quote the keys ending with `~`.
+//    ^       parent operator: parents of the found elements, like `$.store.book.^.bicycle`. Each parent is returned only once, the root element stays as is. Quote the key `^`.
 //
 //
 // JSONPath Script engine
@@ -75,6 +82,8 @@
 //
 // Package has several predefined operators. You are free to add new one with AddOperator
 //
+// Use RegisterOperator to add a binary operator with one or two symbols, like `~=`, which rejects the symbols already in use.
+//
 // Operator precedence: https://golang.org/ref/spec#Operator_precedence
 //
 //     Precedence    Operator
@@ -108,12 +117,22 @@
 //     <=  less or equals          any
 //     >   larger                  any
 //     >=  larger or equals        any
-//     =~  equals regex string     strings
+//     =~  equals regex string     strings, pattern with the flags i, m, s is wrapped into slashes: `/^a/i`
+//
+//     !   not                     any (unary, i.e. `!(@.foo == 1 || @.bar == 2)`)
+//
+// Comparison is strict by default: `"10" == 10` is false. Use JSONPathLoose or EvalLoose to compare numeric strings with numbers as numbers.
+//
+// Filter expression over the path, which is not found, is false: for the element without `a` both `@.a == 1` and `@.a != 1` are false, while `!(@.a == 1)` is true.
+//
+// Operators over the paths with several results, like `@..price > 100` or `@.* == null`, are existential: the result is true if it is true for any of the found nodes, and false if nothing is found. Functions get such paths as an array, like `avg(@..price)`.
 //
 // Supported functions
 //
 // Package has several predefined functions. You are free to add new one with AddFunction
 //
+// Functions with several comma separated arguments, like `distance(@.from, @.to)`, can be added with RegisterFunction
+//
 //     abs          math.Abs          integers, floats
 //     acos         math.Acos         integers, floats
 //     acosh        math.Acosh        integers, floats
@@ -136,6 +155,7 @@
 //     factorial    N!                unsigned integer
 //     floor        math.Floor        integers, floats
 //     gamma        math.Gamma        integers, floats
+//     index        position          any, like `index(@)`: index of the node in the parent array, or null
 //     j0           math.J0           integers, floats
 //     j1           math.J1           integers, floats
 //     length       len               array
@@ -144,10 +164,12 @@
 //     log1p        math.Log1p        integers, floats
 //     log2         math.Log2         integers, floats
 //     logb         math.Logb         integers, floats
+//     match        regexp.Match      string and pattern, like `match(@.sku, '/^a/i')`
 //     not          not               any
 //     pow10        math.Pow10        integer
 //     round        math.Round        integers, floats
 //     roundtoeven  math.RoundToEven  integers, floats
+//     search       regexp.Submatch   string and pattern: the first capture group, or the match, or null
 //     sin          math.Sin          integers, floats
 //     sinh         math.Sinh         integers, floats
 //     sqrt         math.Sqrt         integers, floats
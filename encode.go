@@ -1,11 +1,47 @@
 package ajson
 
 import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
 	"strconv"
+	"strings"
 )
 
-// Marshal returns slice of bytes, marshaled from current value
-func Marshal(node *Node) (result []byte, err error) {
+// MarshalOption is an option of marshaling values in Marshal
+type MarshalOption int
+
+const (
+	// MarshalNormalizeNumbers means that all the numbers will be formatted in the shortest form, like `1.0` as `1` and `1e2` as `100`
+	MarshalNormalizeNumbers MarshalOption = iota
+	// MarshalNonFinite means that non-finite numbers will be formatted as `Infinity`, `-Infinity` and `NaN` literals, instead of the error
+	MarshalNonFinite
+)
+
+// marshalOptions is a set of the options of Marshal
+type marshalOptions struct {
+	normalize bool
+	nonFinite bool
+}
+
+// Marshal returns slice of bytes, marshaled from current value.
+//
+// Unmodified nodes are marshaled from the source data as is, see Node.Source.
+func Marshal(node *Node, options ...MarshalOption) (result []byte, err error) {
+	var opts marshalOptions
+	for _, option := range options {
+		switch option {
+		case MarshalNormalizeNumbers:
+			opts.normalize = true
+		case MarshalNonFinite:
+			opts.nonFinite = true
+		}
+	}
+	return marshal(node, opts)
+}
+
+func marshal(node *Node, opts marshalOptions) (result []byte, err error) {
 	result = make([]byte, 0)
 	var (
 		sValue string
@@ -16,7 +52,9 @@ func Marshal(node *Node) (result []byte, err error) {
 
 	if node == nil {
 		return nil, errorUnparsed()
-	} else if node.dirty {
+	} else if node.IsMissing() {
+		return nil, errorMissing()
+	} else if node.dirty || (opts.normalize && (node._type == Numeric || node.IsContainer())) {
 		switch node._type {
 		case Null:
 			result = append(result, _null...)
@@ -25,6 +63,13 @@ func Marshal(node *Node) (result []byte, err error) {
 			if err != nil {
 				return nil, err
 			}
+			if math.IsInf(nValue, 0) || math.IsNaN(nValue) {
+				if !opts.nonFinite {
+					return nil, errorRequest("non-finite number: %v", nValue)
+				}
+				result = append(result, nonFinite(nValue)...)
+				break
+			}
 			result = append(result, strconv.FormatFloat(nValue, 'g', -1, 64)...)
 		case String:
 			sValue, err = node.GetString()
@@ -53,7 +98,7 @@ func Marshal(node *Node) (result []byte, err error) {
 				if !ok {
 					return nil, errorRequest("wrong length of array")
 				}
-				oValue, err = Marshal(child)
+				oValue, err = marshal(child, opts)
 				if err != nil {
 					return nil, err
 				}
@@ -63,7 +108,8 @@ func Marshal(node *Node) (result []byte, err error) {
 		case Object:
 			result = append(result, bracesL)
 			bValue = false
-			for key, child := range node.children {
+			for _, key := range node.orderedKeys() {
+				child := node.children[key]
 				if bValue {
 					result = append(result, coma)
 				} else {
@@ -72,7 +118,7 @@ func Marshal(node *Node) (result []byte, err error) {
 				result = append(result, quotes)
 				result = append(result, quoteString(key, true)...)
 				result = append(result, quotes, colon)
-				oValue, err = Marshal(child)
+				oValue, err = marshal(child, opts)
 				if err != nil {
 					return nil, err
 				}
@@ -88,3 +134,191 @@ func Marshal(node *Node) (result []byte, err error) {
 
 	return
 }
+
+// EncodeTo writes current node to w, the same data as Marshal returns, without building the whole result in memory.
+// Output is buffered and flushed before the return.
+func (n *Node) EncodeTo(w io.Writer) error {
+	return newEncoder(w, "", "", false, jsonFormat).encodeAll(n)
+}
+
+// EncodeIndentTo writes current node to w like EncodeTo, but each element of Array or Object begins on a new line,
+// starting with prefix followed by one or more copies of indent according to the nesting, the same as json.MarshalIndent does.
+func (n *Node) EncodeIndentTo(w io.Writer, prefix, indent string) error {
+	return newEncoder(w, prefix, indent, true, jsonFormat).encodeAll(n)
+}
+
+// encoder writes nodes to the writer in the format, see Node.EncodeTo, Node.EncodeIndentTo, Node.MarshalJSON5 and Node.PrettyPalette
+type encoder struct {
+	writer encoderWriter
+	prefix string
+	indent string
+	pretty bool
+	format encoderFormat
+	buf    []byte
+}
+
+// encoderWriter is the writer of the encoder, like *bufio.Writer or *bytes.Buffer
+type encoderWriter interface {
+	io.Writer
+	io.ByteWriter
+	WriteString(s string) (int, error)
+}
+
+// encoderFormat is the set of hooks of the output format: how to write the keys of objects and the values, which are not containers
+type encoderFormat struct {
+	key      func(result []byte, key string) []byte
+	scalar   func(result []byte, node *Node) ([]byte, error)
+	source   bool // unmodified containers are written from the source as is, unless the output is indented
+	trailing bool // each element of the container is followed by the comma, including the last one
+}
+
+// jsonFormat is the format of Marshal
+var jsonFormat = encoderFormat{
+	key: doubleQuoted,
+	scalar: func(result []byte, node *Node) ([]byte, error) {
+		value, err := marshal(node, marshalOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return append(result, value...), nil
+	},
+	source: true,
+}
+
+func newEncoder(w io.Writer, prefix, indent string, pretty bool, format encoderFormat) *encoder {
+	return &encoder{writer: bufio.NewWriter(w), prefix: prefix, indent: indent, pretty: pretty, format: format}
+}
+
+// encodeIndent returns the node in the format, each element of Array or Object begins on a new line indented by two spaces
+func encodeIndent(node *Node, format encoderFormat) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	e := &encoder{writer: buf, indent: "  ", pretty: true, format: format}
+	if err := e.encode(node, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeAll writes the node and flushes the buffered writer
+func (e *encoder) encodeAll(node *Node) error {
+	if err := e.encode(node, 0); err != nil {
+		return err
+	}
+	if writer, ok := e.writer.(*bufio.Writer); ok {
+		return writer.Flush()
+	}
+	return nil
+}
+
+// encode writes the node, unmodified containers are written from the source as is, if the format allows it
+func (e *encoder) encode(node *Node, depth int) (err error) {
+	if node == nil {
+		return errorUnparsed()
+	}
+	if !node.IsContainer() {
+		if e.buf, err = e.format.scalar(e.buf[:0], node); err != nil {
+			return err
+		}
+		_, err = e.writer.Write(e.buf)
+		return err
+	}
+	if e.format.source && !e.pretty && !node.dirty {
+		value, err := marshal(node, marshalOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = e.writer.Write(value)
+		return err
+	}
+	if node.Empty() {
+		if node.IsArray() {
+			_, err = e.writer.Write([]byte{bracketL, bracketR})
+		} else {
+			_, err = e.writer.Write([]byte{bracesL, bracesR})
+		}
+		return err
+	}
+	if node.IsArray() {
+		_ = e.writer.WriteByte(bracketL)
+		for i := 0; i < len(node.children); i++ {
+			child, ok := node.children[strconv.Itoa(i)]
+			if !ok {
+				return errorRequest("wrong length of array")
+			}
+			e.separator(i, depth+1)
+			if err = e.encode(child, depth+1); err != nil {
+				return err
+			}
+		}
+		e.separator(-1, depth)
+		return e.writer.WriteByte(bracketR)
+	}
+	_ = e.writer.WriteByte(bracesL)
+	for i, key := range node.orderedKeys() {
+		e.separator(i, depth+1)
+		e.buf = append(e.format.key(e.buf[:0], key), colon)
+		_, _ = e.writer.Write(e.buf)
+		if e.pretty {
+			_ = e.writer.WriteByte(skipS)
+		}
+		if err = e.encode(node.children[key], depth+1); err != nil {
+			return err
+		}
+	}
+	e.separator(-1, depth)
+	return e.writer.WriteByte(bracesR)
+}
+
+// separator writes the comma before the element with the index, if it's not the first one, and the new line with indentation of the depth.
+// Negative index means the end of the container, the comma is written before it only in the format with the trailing commas.
+func (e *encoder) separator(index, depth int) {
+	if index > 0 || (index < 0 && e.format.trailing) {
+		_ = e.writer.WriteByte(coma)
+	}
+	if e.pretty {
+		_ = e.writer.WriteByte(skipN)
+		_, _ = e.writer.WriteString(e.prefix)
+		_, _ = e.writer.WriteString(strings.Repeat(e.indent, depth))
+	}
+}
+
+// doubleQuoted appends the value escaped and wrapped with the double quotes
+func doubleQuoted(result []byte, value string) []byte {
+	result = append(result, quotes)
+	result = append(result, quoteString(value, true)...)
+	return append(result, quotes)
+}
+
+// nonFinite returns the literal of the non-finite number
+func nonFinite(value float64) []byte {
+	if math.IsNaN(value) {
+		return _nan
+	} else if value > 0 {
+		return _infinity
+	}
+	return _negInfinity
+}
+
+// compact returns a copy of valid JSON data without insignificant whitespaces
+func compact(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	str := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if str {
+			if c == backslash && i+1 < len(data) {
+				result = append(result, c)
+				i++
+				c = data[i]
+			} else if c == quotes {
+				str = false
+			}
+		} else if c == skipS || c == skipN || c == skipR || c == skipT {
+			continue
+		} else if c == quotes {
+			str = true
+		}
+		result = append(result, c)
+	}
+	return result
+}
@@ -1,7 +1,11 @@
 package ajson
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"testing"
 )
 
@@ -125,6 +129,38 @@ func TestMarshal_Encoded(t *testing.T) {
 	}
 }
 
+func TestMarshal_NormalizeNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "integer", input: `1`, expected: `1`},
+		{name: "integer float", input: `1.0`, expected: `1`},
+		{name: "float", input: `0.1`, expected: `0.1`},
+		{name: "exponent", input: `1e2`, expected: `100`},
+		{name: "trailing zeros", input: `-2.50`, expected: `-2.5`},
+		{name: "array", input: `[1.0, "1.0", 2E-1]`, expected: `[1,"1.0",0.2]`},
+		{name: "object", input: `{"a": 10.00}`, expected: `{"a":10}`},
+		{name: "string", input: `"one \"encoded\" string"`, expected: `"one \"encoded\" string"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := Marshal(Must(Unmarshal([]byte(test.input))), MarshalNormalizeNumbers)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if string(value) != test.expected {
+				t.Errorf("wrong result: '%s', expected '%s'", value, test.expected)
+			}
+		})
+	}
+
+	node := Must(Unmarshal([]byte(`[1.0]`)))
+	if value, err := Marshal(node); err != nil || string(value) != `[1.0]` {
+		t.Errorf("wrong result without the option: '%s', %v", value, err)
+	}
+}
+
 func TestMarshal_Errors(t *testing.T) {
 	tests := []struct {
 		name string
@@ -194,3 +230,95 @@ func TestMarshal_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestNode_EncodeTo(t *testing.T) {
+	records := make([]*Node, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		records = append(records, ObjectNode("", map[string]*Node{
+			"id":    NumericNode("", float64(i)),
+			"name":  StringNode("", "<item \""+strconv.Itoa(i)+"\">"),
+			"tags":  ArrayNode("", []*Node{StringNode("", "a"), BoolNode("", i%2 == 0), NullNode("")}),
+			"empty": ObjectNode("", map[string]*Node{}),
+		}))
+	}
+	root := ObjectNode("", map[string]*Node{
+		"records": ArrayNode("", records),
+		"source":  Must(Unmarshal([]byte(`{"b": [1, 2.50], "a": {}}`))),
+	})
+	expected, err := Marshal(root)
+	if err != nil {
+		t.Errorf("Marshal() unexpected error: %s", err)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if err = root.EncodeTo(buf); err != nil {
+		t.Errorf("EncodeTo() unexpected error: %s", err)
+		return
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("EncodeTo() result is not the same as Marshal()")
+	}
+	if again, err := Unmarshal(buf.Bytes()); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %s", err)
+	} else if ok, err := again.Eq(root); err != nil || !ok {
+		t.Errorf("EncodeTo() wrong result after reparsing")
+	}
+
+	buf.Reset()
+	if err = root.EncodeIndentTo(buf, "", "  "); err != nil {
+		t.Errorf("EncodeIndentTo() unexpected error: %s", err)
+		return
+	}
+	indented := new(bytes.Buffer)
+	if err = json.Indent(indented, expected, "", "  "); err != nil {
+		t.Errorf("json.Indent() unexpected error: %s", err)
+	}
+	if !bytes.Equal(buf.Bytes(), indented.Bytes()) {
+		t.Errorf("EncodeIndentTo() result is not the same as json.Indent()")
+	}
+	if again, err := Unmarshal(buf.Bytes()); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %s", err)
+	} else if ok, err := again.Eq(root); err != nil || !ok {
+		t.Errorf("EncodeIndentTo() wrong result after reparsing")
+	}
+}
+
+func TestNode_EncodeIndentTo(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": [1, {"b": null}, []], "c": "d"}`)))
+	tests := []struct {
+		name     string
+		prefix   string
+		indent   string
+		expected string
+	}{
+		{name: "indent", prefix: "", indent: "\t", expected: "{\n\t\"a\": [\n\t\t1,\n\t\t{\n\t\t\t\"b\": null\n\t\t},\n\t\t[]\n\t],\n\t\"c\": \"d\"\n}"},
+		{name: "prefix", prefix: "> ", indent: " ", expected: "{\n>  \"a\": [\n>   1,\n>   {\n>    \"b\": null\n>   },\n>   []\n>  ],\n>  \"c\": \"d\"\n> }"},
+		{name: "no indent", prefix: "", indent: "", expected: "{\n\"a\": [\n1,\n{\n\"b\": null\n},\n[]\n],\n\"c\": \"d\"\n}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := root.EncodeIndentTo(buf, test.prefix, test.indent); err != nil {
+				t.Errorf("EncodeIndentTo() unexpected error: %s", err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("EncodeIndentTo() wrong result\nExpected: %q\nActual:   %q", test.expected, buf.String())
+			}
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NumericNode("", 1.5).EncodeIndentTo(buf, "> ", "  "); err != nil || buf.String() != "1.5" {
+		t.Errorf("EncodeIndentTo() wrong result of the scalar: %q, %v", buf.String(), err)
+	}
+	if err := root.EncodeTo(failWriter{}); err == nil || err.Error() != "fail" {
+		t.Errorf("EncodeTo() wrong error of the writer: %v", err)
+	}
+	if err := root.Opt("missing").EncodeTo(buf); err == nil {
+		t.Errorf("EncodeTo() expected error on missing node")
+	}
+	if err := ArrayNode("", []*Node{NumericNode("", 1), NumericNode("", math.NaN())}).EncodeTo(buf); err == nil {
+		t.Errorf("EncodeTo() expected error on non-finite number")
+	}
+}
@@ -1,6 +1,9 @@
 package ajson
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // Error is common struct to provide internal errors
 type Error struct {
@@ -24,6 +27,10 @@ const (
 	WrongRequest
 	// Unparsed means that json structure wasn't parsed yet
 	Unparsed
+	// Missing means that requested node doesn't exist, see Node.Opt
+	Missing
+	// Timeout means that evaluation was aborted by the deadline, see JSONPathTimeout
+	Timeout
 )
 
 func errorSymbol(b *buffer) error {
@@ -50,10 +57,33 @@ func errorUnparsed() error {
 	return Error{Type: Unparsed}
 }
 
+func errorMissing() error {
+	return Error{Type: Missing}
+}
+
+func errorTimeout() error {
+	return Error{Type: Timeout}
+}
+
+// isTimeout returns true if err is the Timeout error
+func isTimeout(err error) bool {
+	value, ok := err.(Error)
+	return ok && value.Type == Timeout
+}
+
+func errorFrozen() error {
+	return errorRequest("node is frozen")
+}
+
 func errorRequest(format string, args ...interface{}) error {
 	return Error{Type: WrongRequest, Message: fmt.Sprintf(format, args...)}
 }
 
+// Is reports whether the error matches the target, for errors.Is: Timeout error matches context.DeadlineExceeded
+func (err Error) Is(target error) bool {
+	return err.Type == Timeout && target == context.DeadlineExceeded
+}
+
 // Error interface implementation
 func (err Error) Error() string {
 	switch err.Type {
@@ -65,6 +95,10 @@ func (err Error) Error() string {
 		return "wrong type of Node"
 	case Unparsed:
 		return "not parsed yet"
+	case Missing:
+		return "node is missing"
+	case Timeout:
+		return "timeout exceeded"
 	case WrongRequest:
 		return fmt.Sprintf("wrong request: %s", err.Message)
 	}
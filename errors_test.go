@@ -1,6 +1,9 @@
 package ajson
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestError_Error(t *testing.T) {
 	tests := []struct {
@@ -12,6 +15,8 @@ func TestError_Error(t *testing.T) {
 		{name: "UnexpectedEOF", _type: UnexpectedEOF, message: "unexpected end of file"},
 		{name: "WrongType", _type: WrongType, message: "wrong type of Node"},
 		{name: "WrongRequest", _type: WrongRequest, message: "wrong request: example error"},
+		{name: "Missing", _type: Missing, message: "node is missing"},
+		{name: "Timeout", _type: Timeout, message: "timeout exceeded"},
 		{name: "unknown", _type: -666, message: "unknown error: 'S' at 10"},
 	}
 	for _, test := range tests {
@@ -28,3 +33,12 @@ func TestError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestError_Is(t *testing.T) {
+	if !errorTimeout().(Error).Is(context.DeadlineExceeded) {
+		t.Errorf("Timeout error should match context.DeadlineExceeded")
+	}
+	if errorRequest("example error").(Error).Is(context.DeadlineExceeded) || errorTimeout().(Error).Is(context.Canceled) {
+		t.Errorf("Error should not match other errors")
+	}
+}
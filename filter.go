@@ -0,0 +1,158 @@
+package ajson
+
+import (
+	"context"
+	"strings"
+)
+
+// filterExpression is the boolean expression of the filter `[?(expr)]`: the operator `||`, `&&` or `!` over the operands,
+// or the script expression, evaluated by the script engine, if the operator is empty.
+type filterExpression struct {
+	operator string
+	operands []*filterExpression
+	script   rpn
+}
+
+// parseFilter parses the filter expression by recursive descent, from the lowest priority to the highest:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" primary | primary | script
+//	primary = "(" or ")" | "!" primary | script with the single operand, like `@.foo` or `length(@.tags)`
+//
+// Negation of the script with the operators, like `!@.a == 1`, is left to the script engine: `(!@.a) == 1`.
+// Script with the missing path is false, so `!(@.a == 1)` is true, and `@.a != 1` is false, if `@.a` is not found.
+func parseFilter(expression string) (*filterExpression, error) {
+	return parseFilterOr(expression)
+}
+
+func parseFilterOr(expression string) (*filterExpression, error) {
+	return parseFilterBinary(expression, "||", parseFilterAnd)
+}
+
+func parseFilterAnd(expression string) (*filterExpression, error) {
+	return parseFilterBinary(expression, "&&", func(expression string) (*filterExpression, error) {
+		result, _, err := parseFilterUnary(expression)
+		return result, err
+	})
+}
+
+// parseFilterBinary returns the operator over the operands, separated by it, or the single operand, parsed by next
+func parseFilterBinary(expression, operator string, next func(string) (*filterExpression, error)) (*filterExpression, error) {
+	parts := splitFilter(expression, operator)
+	if len(parts) == 1 {
+		return next(parts[0])
+	}
+	result := &filterExpression{operator: operator, operands: make([]*filterExpression, 0, len(parts))}
+	for _, part := range parts {
+		operand, err := next(part)
+		if err != nil {
+			return nil, err
+		}
+		result.operands = append(result.operands, operand)
+	}
+	return result, nil
+}
+
+// parseFilterUnary returns the unary expression, primary is true if it could be negated as a whole
+func parseFilterUnary(expression string) (result *filterExpression, primary bool, err error) {
+	expression = strings.TrimSpace(expression)
+	if expression != "" && expression[0] == exclamation && (len(expression) == 1 || expression[1] != signE) {
+		operand, primary, err := parseFilterUnary(expression[1:])
+		if err != nil {
+			return nil, false, err
+		}
+		if primary {
+			return &filterExpression{operator: string(exclamation), operands: []*filterExpression{operand}}, true, nil
+		}
+	} else if len(expression) > 1 && expression[0] == parenthesesL && groupEnd(expression) == len(expression)-1 {
+		result, err = parseFilterOr(expression[1 : len(expression)-1])
+		return result, err == nil, err
+	}
+	script, err := compileExpression(expression)
+	if err != nil {
+		return nil, false, err
+	}
+	last := script[len(script)-1]
+	_, operation := operations[last]
+	return &filterExpression{script: script}, !operation && last != string(coma), nil
+}
+
+// splitFilter returns the parts of the expression, separated by the operator outside of the strings, parentheses and brackets
+func splitFilter(expression, operator string) []string {
+	result := make([]string, 0, 1)
+	depth, start := 0, 0
+	for i := 0; i < len(expression); i++ {
+		switch c := expression[i]; c {
+		case quote, quotes:
+			i = stringEnd(expression, i)
+		case parenthesesL, bracketL:
+			depth++
+		case parenthesesR, bracketR:
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(expression[i:], operator) {
+				result = append(result, expression[start:i])
+				i += len(operator) - 1
+				start = i + 1
+			}
+		}
+	}
+	return append(result, expression[start:])
+}
+
+// groupEnd returns the index of the parenthesis, which closes the one at the start of the expression, or -1
+func groupEnd(expression string) int {
+	depth := 0
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case quote, quotes:
+			i = stringEnd(expression, i)
+		case parenthesesL, bracketL:
+			depth++
+		case parenthesesR, bracketR:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// stringEnd returns the index of the quote, which closes the string started at the index, or the end of the expression
+func stringEnd(expression string, start int) int {
+	for i := start + 1; i < len(expression); i++ {
+		if expression[i] == backslash {
+			i++
+		} else if expression[i] == expression[start] {
+			return i
+		}
+	}
+	return len(expression)
+}
+
+// match returns true if the expression is true for the node. Operators `&&` and `||` are evaluated lazily.
+func (f *filterExpression) match(ctx context.Context, node *Node, cmd string) (bool, error) {
+	switch f.operator {
+	case "||", "&&":
+		for _, operand := range f.operands {
+			ok, err := operand.match(ctx, node, cmd)
+			if err != nil {
+				return false, err
+			}
+			if ok == (f.operator == "||") {
+				return ok, nil
+			}
+		}
+		return f.operator == "&&", nil
+	case string(exclamation):
+		ok, err := f.operands[0].match(ctx, node, cmd)
+		return !ok, err
+	}
+	value, err := eval(ctx, node, f.script, cmd)
+	if err != nil || value == nil {
+		return false, err
+	}
+	ok, err := boolean(value)
+	return err == nil && ok, nil
+}
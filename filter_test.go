@@ -0,0 +1,81 @@
+package ajson
+
+import (
+	"strings"
+	"testing"
+)
+
+// filterString returns the structure of the parsed filter expression: operators with the operands in parentheses, scripts in RPN
+func filterString(filter *filterExpression) string {
+	if filter.operator == "" {
+		return "{" + strings.Join(filter.script, " ") + "}"
+	}
+	operands := make([]string, 0, len(filter.operands))
+	for _, operand := range filter.operands {
+		operands = append(operands, filterString(operand))
+	}
+	return filter.operator + "(" + strings.Join(operands, ", ") + ")"
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: `@.a == 1`, expected: "{@.a 1 ==}"},
+		{expression: `@.a == 1 || @.b == 2 && @.c`, expected: "||({@.a 1 ==}, &&({@.b 2 ==}, {@.c}))"},
+		{expression: `(@.a == 1 || @.b == 2) && @.c`, expected: "&&(||({@.a 1 ==}, {@.b 2 ==}), {@.c})"},
+		{expression: `!(@.a == 1 || @.b == 2)`, expected: "!(||({@.a 1 ==}, {@.b 2 ==}))"},
+		{expression: `!!@.a`, expected: "!(!({@.a}))"},
+		{expression: ` ! ( @.a ) `, expected: "!({@.a})"},
+		{expression: `!length(@.a)`, expected: "!({@.a length})"},
+		{expression: `!@.a == 1`, expected: "{@.a not 1 ==}"},
+		{expression: `!(@.a) + 1`, expected: "{@.a not 1 +}"},
+		{expression: `(@.a + 1) * 2 > 3`, expected: "{@.a 1 + 2 * 3 >}"},
+		{expression: `!(@.a + 1)`, expected: "!({@.a 1 +})"},
+		{expression: `@.a[?(@.b || @.c)] && '&&' == @.d`, expected: "&&({@.a[?(@.b || @.c)]}, {'&&' @.d ==})"},
+		{expression: `match(@.a, 'x') || @.b`, expected: "||({@.a 'x' , match}, {@.b})"},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			filter, err := parseFilter(test.expression)
+			if err != nil {
+				t.Errorf("parseFilter() unexpected error: %s", err)
+				return
+			}
+			if actual := filterString(filter); actual != test.expected {
+				t.Errorf("parseFilter() wrong result\nExpected: %s\nActual:   %s", test.expected, actual)
+			}
+		})
+	}
+
+	for _, expression := range []string{``, `@.a ||`, `&& @.b`, `!`, `!= 1`, `(@.a == 1`, `@.a == 1)`, `(@.a || )`} {
+		if _, err := parseFilter(expression); err == nil {
+			t.Errorf("parseFilter(%s) expected error", expression)
+		}
+	}
+}
+
+func TestParseFilter_missing(t *testing.T) {
+	document := []byte(`[{"a":1},{"b":2},{}]`)
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: `$[?(@.a != 1)]`, expected: "[]"},
+		{path: `$[?(@.a == 1)]`, expected: "[$[0]]"},
+		{path: `$[?(!(@.a == 1))]`, expected: "[$[1], $[2]]"},
+		{path: `$[?(@.a != 1 || @.b == 2)]`, expected: "[$[1]]"},
+		{path: `$[?(!(@.a != 1 || @.b == 2))]`, expected: "[$[0], $[2]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %s", err)
+			} else if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s): path doesn't match\nExpected: %s\nActual:   %s", test.path, test.expected, fullPath(result))
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package ajson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+)
+
+// gzipMagic is the header of gzip-compressed data, RFC 1952
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// UnmarshalGzip decompresses gzip-compressed data and parses the result as Unmarshal does.
+// Invalid gzip data causes WrongRequest error.
+func UnmarshalGzip(data []byte) (root *Node, err error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errorRequest("wrong gzip data: %s", err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errorRequest("wrong gzip data: %s", err)
+	}
+	return Unmarshal(body)
+}
+
+// UnmarshalAuto parses data as UnmarshalGzip if it starts with the gzip header, or as Unmarshal otherwise.
+func UnmarshalAuto(data []byte) (root *Node, err error) {
+	if bytes.HasPrefix(data, gzipMagic) {
+		return UnmarshalGzip(data)
+	}
+	return Unmarshal(data)
+}
@@ -0,0 +1,67 @@
+package ajson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("gzip error: %s", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("gzip error: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUnmarshalGzip(t *testing.T) {
+	data := []byte(`{"foo": [1, "bar", null]}`)
+	compressed := gzipData(t, data)
+	tests := []struct {
+		name  string
+		input []byte
+		auto  bool
+		err   bool
+	}{
+		{name: "compressed", input: compressed},
+		{name: "compressed auto", input: compressed, auto: true},
+		{name: "plain auto", input: data, auto: true},
+		{name: "plain", input: data, err: true},
+		{name: "broken", input: compressed[:len(compressed)-4], err: true},
+		{name: "broken auto", input: compressed[:len(compressed)-4], auto: true, err: true},
+		{name: "header only", input: compressed[:2], auto: true, err: true},
+		{name: "wrong json", input: gzipData(t, []byte(`{"foo":`)), auto: true, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				root *Node
+				err  error
+			)
+			if test.auto {
+				root, err = UnmarshalAuto(test.input)
+			} else {
+				root, err = UnmarshalGzip(test.input)
+			}
+			if test.err {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			if value, err := Marshal(root); err != nil {
+				t.Errorf("Marshal returns error: %s", err)
+			} else if string(value) != string(data) {
+				t.Errorf("wrong result: %s", value)
+			}
+		})
+	}
+}
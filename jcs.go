@@ -0,0 +1,148 @@
+package ajson
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonicalize returns current value in the JSON Canonicalization Scheme, RFC 8785: without whitespaces,
+// keys of objects are sorted by UTF-16 code units, numbers are formatted as in ECMAScript and strings are escaped minimally.
+// Non-finite numbers and invalid UTF-8 strings cause an error.
+func (n *Node) Canonicalize() ([]byte, error) {
+	return canonicalize(make([]byte, 0), n)
+}
+
+func canonicalize(result []byte, node *Node) (_ []byte, err error) {
+	if node == nil {
+		return nil, errorUnparsed()
+	} else if node.IsMissing() {
+		return nil, errorMissing()
+	}
+	switch node._type {
+	case Null:
+		return append(result, _null...), nil
+	case Bool:
+		value, err := node.GetBool()
+		if err != nil {
+			return nil, err
+		}
+		if value {
+			return append(result, _true...), nil
+		}
+		return append(result, _false...), nil
+	case Numeric:
+		value, err := node.GetNumeric()
+		if err != nil {
+			return nil, err
+		}
+		number, err := canonicalNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		return append(result, number...), nil
+	case String:
+		value, err := node.GetString()
+		if err != nil {
+			return nil, err
+		}
+		return canonicalString(result, value)
+	case Array:
+		result = append(result, bracketL)
+		for i, child := range node.Inheritors() {
+			if i != 0 {
+				result = append(result, coma)
+			}
+			if result, err = canonicalize(result, child); err != nil {
+				return nil, err
+			}
+		}
+		return append(result, bracketR), nil
+	case Object:
+		keys := make([]string, 0, len(node.children))
+		for key := range node.children {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+		result = append(result, bracesL)
+		for i, key := range keys {
+			if i != 0 {
+				result = append(result, coma)
+			}
+			if result, err = canonicalString(result, key); err != nil {
+				return nil, err
+			}
+			result = append(result, colon)
+			if result, err = canonicalize(result, node.children[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(result, bracesR), nil
+	}
+	return nil, errorType()
+}
+
+// canonicalNumber formats the number as ECMAScript Number.prototype.toString does
+func canonicalNumber(value float64) (string, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", errorRequest("non-finite number: %v", value)
+	}
+	if value == 0 { // -0 is the same as 0
+		return "0", nil
+	}
+	if abs := math.Abs(value); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	}
+	number := strconv.FormatFloat(value, 'e', -1, 64)
+	index := strings.IndexByte(number, 'e')
+	exponent := strings.TrimLeft(number[index+2:], "0") // there is no leading zeros in the exponent: `1e-7`, not `1e-07`
+	return number[:index+2] + exponent, nil
+}
+
+// canonicalString appends the quoted string, where only quotes, backslashes and control characters are escaped
+func canonicalString(result []byte, value string) ([]byte, error) {
+	if !utf8.ValidString(value) {
+		return nil, errorRequest("invalid UTF-8 string: %q", value)
+	}
+	result = append(result, quotes)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch c {
+		case quotes, backslash:
+			result = append(result, backslash, c)
+		case '\b':
+			result = append(result, backslash, 'b')
+		case '\f':
+			result = append(result, backslash, 'f')
+		case '\n':
+			result = append(result, backslash, 'n')
+		case '\r':
+			result = append(result, backslash, 'r')
+		case '\t':
+			result = append(result, backslash, 't')
+		default:
+			if c < 0x20 {
+				result = append(result, backslash, 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			} else {
+				result = append(result, c)
+			}
+		}
+	}
+	return append(result, quotes), nil
+}
+
+// lessUTF16 compares strings by UTF-16 code units
+func lessUTF16(left, right string) bool {
+	lunits, runits := utf16.Encode([]rune(left)), utf16.Encode([]rune(right))
+	for i := 0; i < len(lunits) && i < len(runits); i++ {
+		if lunits[i] != runits[i] {
+			return lunits[i] < runits[i]
+		}
+	}
+	return len(lunits) < len(runits)
+}
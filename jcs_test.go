@@ -0,0 +1,104 @@
+package ajson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNode_Canonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "RFC 8785 3.2.2",
+			input: `{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]
+			}`,
+			expected: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name: "RFC 8785 3.2.3",
+			input: `{
+				"\u20ac": "Euro Sign",
+				"\r": "Carriage Return",
+				"\ufb33": "Hebrew Letter Dalet With Dagesh",
+				"1": "One",
+				"\ud83d\ude00": "Emoji: Grinning Face",
+				"\u0080": "Control",
+				"\u00f6": "Latin Small Letter O With Diaeresis"
+			}`,
+			expected: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{name: "nested", input: `[{"b": [], "a": {}}, "\b\f\t\u001f", -0]`, expected: `[{"a":{},"b":[]},"\b\f\t\u001f",0]`},
+		{name: "scalar", input: ` "</script>\u2028" `, expected: "\"</script>\u2028\""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Must(Unmarshal([]byte(test.input))).Canonicalize()
+			if err != nil {
+				t.Errorf("Canonicalize() unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Canonicalize() wrong result\nExpected: %s\nActual:   %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestNode_Canonicalize_numbers(t *testing.T) {
+	tests := []struct {
+		bits     uint64
+		expected string
+	}{ // RFC 8785 Appendix B
+		{bits: 0x0000000000000000, expected: "0"},
+		{bits: 0x8000000000000000, expected: "0"},
+		{bits: 0x0000000000000001, expected: "5e-324"},
+		{bits: 0x8000000000000001, expected: "-5e-324"},
+		{bits: 0x7fefffffffffffff, expected: "1.7976931348623157e+308"},
+		{bits: 0xffefffffffffffff, expected: "-1.7976931348623157e+308"},
+		{bits: 0x4340000000000000, expected: "9007199254740992"},
+		{bits: 0xc340000000000000, expected: "-9007199254740992"},
+		{bits: 0x4430000000000000, expected: "295147905179352830000"},
+		{bits: 0x44b52d02c7e14af5, expected: "9.999999999999997e+22"},
+		{bits: 0x44b52d02c7e14af6, expected: "1e+23"},
+		{bits: 0x44b52d02c7e14af7, expected: "1.0000000000000001e+23"},
+		{bits: 0x444b1ae4d6e2ef4e, expected: "999999999999999700000"},
+		{bits: 0x444b1ae4d6e2ef4f, expected: "999999999999999900000"},
+		{bits: 0x444b1ae4d6e2ef50, expected: "1e+21"},
+		{bits: 0x3eb0c6f7a0b5ed8c, expected: "9.999999999999997e-7"},
+		{bits: 0x3eb0c6f7a0b5ed8d, expected: "0.000001"},
+		{bits: 0x41b3de4355555553, expected: "333333333.3333332"},
+		{bits: 0x41b3de4355555554, expected: "333333333.33333325"},
+		{bits: 0x41b3de4355555555, expected: "333333333.3333333"},
+		{bits: 0x41b3de4355555556, expected: "333333333.3333334"},
+		{bits: 0x41b3de4355555557, expected: "333333333.33333343"},
+		{bits: 0xbecbf647612f3696, expected: "-0.0000033333333333333333"},
+		{bits: 0x43143ff3c1cb0959, expected: "1424953923781206.2"},
+	}
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			result, err := NumericNode("", math.Float64frombits(test.bits)).Canonicalize()
+			if err != nil {
+				t.Errorf("Canonicalize() unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Canonicalize() wrong result: %s, expected %s", result, test.expected)
+			}
+		})
+	}
+
+	for _, bits := range []uint64{0x7fffffffffffffff, 0x7ff0000000000000, 0xfff0000000000000} {
+		if _, err := NumericNode("", math.Float64frombits(bits)).Canonicalize(); err == nil {
+			t.Errorf("Canonicalize(%x) expected error", bits)
+		}
+	}
+	if _, err := StringNode("", "\xff").Canonicalize(); err == nil {
+		t.Errorf("Canonicalize() expected error on invalid UTF-8")
+	}
+	if _, err := ArrayNode("", []*Node{NumericNode("", math.NaN())}).Canonicalize(); err == nil {
+		t.Errorf("Canonicalize() expected error on nested non-finite number")
+	}
+}
@@ -0,0 +1,383 @@
+package ajson
+
+import (
+	"bytes"
+	"math/big"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MarshalJSON5 returns current value in the JSON5 format, friendly for the human-edited files: containers are indented by two spaces
+// and each element ends with a comma, keys of objects are written as identifiers where possible, strings are single-quoted,
+// non-finite numbers are written as `Infinity`, `-Infinity` and `NaN`.
+func (n *Node) MarshalJSON5() ([]byte, error) {
+	return encodeIndent(n, json5Format)
+}
+
+// json5Format is the format of MarshalJSON5
+var json5Format = encoderFormat{
+	key: func(result []byte, key string) []byte {
+		if isIdentifier(key) {
+			return append(result, key...)
+		}
+		return singleQuoted(result, key)
+	},
+	scalar: func(result []byte, node *Node) ([]byte, error) {
+		if node.IsString() {
+			value, err := node.GetString()
+			if err != nil {
+				return nil, err
+			}
+			return singleQuoted(result, value), nil
+		}
+		value, err := Marshal(node, MarshalNonFinite)
+		if err != nil {
+			return nil, err
+		}
+		return append(result, value...), nil
+	},
+	trailing: true,
+}
+
+// isIdentifier returns true if the key could be written without quotes, as the ECMAScript identifier
+func isIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		if !isIdentifierRune(r, i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifierRune returns true if the rune could be used in the ECMAScript identifier, first is true for the first rune of it
+func isIdentifierRune(r rune, first bool) bool {
+	if r == '$' || r == '_' || unicode.IsLetter(r) {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc))
+}
+
+// singleQuoted appends the value escaped and wrapped with the single quotes
+func singleQuoted(result []byte, value string) []byte {
+	quoted := quoteString(value, false)
+	result = append(result, quote)
+	for i := 0; i < len(quoted); i++ {
+		switch c := quoted[i]; {
+		case c == backslash && i+1 < len(quoted) && quoted[i+1] == quotes:
+			result = append(result, quotes)
+			i++
+		case c == backslash && i+1 < len(quoted):
+			result = append(result, c, quoted[i+1])
+			i++
+		case c == quote:
+			result = append(result, backslash, c)
+		default:
+			result = append(result, c)
+		}
+	}
+	return append(result, quote)
+}
+
+// UnmarshalJSON5 parses the data in the JSON5 format: comments, unquoted identifier keys, single-quoted strings, trailing commas,
+// hexadecimal numbers, leading and trailing decimal points, leading plus sign, `Infinity` and `NaN` are allowed.
+//
+// The data is converted into JSON before the parsing, so source of the nodes is the converted JSON,
+// but indexes in the errors point to the positions in the original data.
+func UnmarshalJSON5(data []byte) (root *Node, err error) {
+	conv := &json5Converter{
+		data:    data,
+		result:  make([]byte, 0, len(data)),
+		offsets: make([]int, 0, len(data)),
+	}
+	if err = conv.convert(); err != nil {
+		return nil, err
+	}
+	root, err = Unmarshal(conv.result, UnmarshalNonFinite)
+	if value, ok := err.(Error); ok && (value.Type == WrongSymbol || value.Type == UnexpectedEOF) {
+		if value.Index < len(conv.offsets) {
+			value.Index = conv.offsets[value.Index]
+		} else {
+			value.Index = len(data)
+		}
+		if value.Type == WrongSymbol && value.Index < len(data) {
+			value.Char = data[value.Index]
+		}
+		return nil, value
+	}
+	return root, err
+}
+
+var (
+	_nbsp               = []byte("\u00a0")
+	_lineSeparator      = []byte("\u2028")
+	_paragraphSeparator = []byte("\u2029")
+)
+
+// json5Converter converts the JSON5 data into JSON, offsets contain the index in data of each byte of the result
+type json5Converter struct {
+	data    []byte
+	result  []byte
+	offsets []int
+}
+
+// emit appends the bytes to the result, all of them are converted from the byte of data with the index
+func (c *json5Converter) emit(index int, values ...byte) {
+	c.result = append(c.result, values...)
+	for range values {
+		c.offsets = append(c.offsets, index)
+	}
+}
+
+func (c *json5Converter) convert() error {
+	for i := 0; ; {
+		next, err := c.skip(i)
+		if err != nil {
+			return err
+		}
+		if next != i {
+			c.emit(i, skipS)
+		}
+		if i = next; i >= len(c.data) {
+			return nil
+		}
+		r, _ := utf8.DecodeRune(c.data[i:])
+		switch b := c.data[i]; {
+		case b == quotes || b == quote:
+			i, err = c.string(i)
+		case b == coma:
+			if next, err = c.skip(i + 1); err != nil {
+				return err
+			}
+			if last := bytes.TrimRight(c.result, " "); len(last) == 0 || last[len(last)-1] == bracketL || last[len(last)-1] == bracesL || last[len(last)-1] == coma {
+				return errorAt(i, coma) // comma is allowed only after the element
+			}
+			if next < len(c.data) && (c.data[next] == bracketR || c.data[next] == bracesR) { // trailing comma
+				i = next
+				continue
+			}
+			c.emit(i, coma)
+			i++
+		case b == plus || b == minus || b == dot || (b >= '0' && b <= '9'):
+			i, err = c.number(i)
+		case isIdentifierRune(r, true):
+			i, err = c.identifier(i)
+		default:
+			c.emit(i, b)
+			i++
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// skip returns the index of the next byte after the whitespaces and comments
+func (c *json5Converter) skip(i int) (int, error) {
+	for i < len(c.data) {
+		switch b := c.data[i]; {
+		case b == skipS || b == skipN || b == skipR || b == skipT || b == '\v' || b == '\f':
+			i++
+		case bytes.HasPrefix(c.data[i:], _bom):
+			i += len(_bom)
+		case bytes.HasPrefix(c.data[i:], _nbsp):
+			i += len(_nbsp)
+		case bytes.HasPrefix(c.data[i:], _lineSeparator), bytes.HasPrefix(c.data[i:], _paragraphSeparator):
+			i += len(_lineSeparator)
+		case b == division && i+1 < len(c.data) && c.data[i+1] == division:
+			for ; i < len(c.data) && c.data[i] != skipN && c.data[i] != skipR; i++ {
+			}
+		case b == division && i+1 < len(c.data) && c.data[i+1] == asterisk:
+			end := bytes.Index(c.data[i+2:], []byte("*/"))
+			if end == -1 {
+				return i, Error{Type: UnexpectedEOF, Index: i}
+			}
+			i += end + 4
+		default:
+			return i, nil
+		}
+	}
+	return i, nil
+}
+
+// string converts the single or double quoted string into the double quoted one
+func (c *json5Converter) string(i int) (int, error) {
+	start, end := i, c.data[i]
+	c.emit(i, quotes)
+	for i++; ; i++ {
+		if i >= len(c.data) {
+			return i, Error{Type: UnexpectedEOF, Index: start}
+		}
+		switch b := c.data[i]; b {
+		case end:
+			c.emit(i, quotes)
+			return i + 1, nil
+		case skipN, skipR:
+			return i, errorAt(i, b)
+		case quotes:
+			c.emit(i, backslash, quotes)
+		case skipT:
+			c.emit(i, backslash, 't')
+		case backslash:
+			if i+1 >= len(c.data) {
+				return i, Error{Type: UnexpectedEOF, Index: start}
+			}
+			i++
+			switch b = c.data[i]; {
+			case b == skipN: // line continuation
+			case b == skipR:
+				if i+1 < len(c.data) && c.data[i+1] == skipN {
+					i++
+				}
+			case bytes.HasPrefix(c.data[i:], _lineSeparator), bytes.HasPrefix(c.data[i:], _paragraphSeparator):
+				i += len(_lineSeparator) - 1
+			case b == quote:
+				c.emit(i, quote)
+			case b == 'v':
+				c.emit(i, []byte("\\u000b")...)
+			case b == '0':
+				if i+1 < len(c.data) && c.data[i+1] >= '0' && c.data[i+1] <= '9' {
+					return i, errorAt(i+1, c.data[i+1])
+				}
+				c.emit(i, []byte("\\u0000")...)
+			case b == 'x':
+				for j := i + 1; j < i+3; j++ {
+					if j >= len(c.data) {
+						return j, Error{Type: UnexpectedEOF, Index: start}
+					} else if !isHex(c.data[j]) {
+						return j, errorAt(j, c.data[j])
+					}
+				}
+				c.emit(i, backslash, 'u', '0', '0', c.data[i+1], c.data[i+2])
+				i += 2
+			case b == quotes || b == backslash || b == division || b == 'b' || b == 'f' || b == 'n' || b == 'r' || b == 't' || b == 'u':
+				c.emit(i, backslash, b)
+			case b >= '1' && b <= '9':
+				return i, errorAt(i, b)
+			default: // any other escaped character is the character itself
+				_, size := utf8.DecodeRune(c.data[i:])
+				c.emit(i, c.data[i:i+size]...)
+				i += size - 1
+			}
+		default:
+			if b < skipS { // control characters are allowed in JSON5 strings, but not in JSON
+				c.emit(i, backslash, 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			} else {
+				c.emit(i, b)
+			}
+		}
+	}
+}
+
+// number converts the number with the optional sign, hexadecimal or with the leading or trailing decimal point, or the non-finite number.
+// Number should be followed by the delimiter, like `1,` or `1]`, but not like `1+2` or `0x1.5`.
+func (c *json5Converter) number(i int) (int, error) {
+	i, err := c.numberValue(i)
+	if err != nil || i >= len(c.data) {
+		return i, err
+	}
+	if r, _ := utf8.DecodeRune(c.data[i:]); isIdentifierRune(r, false) || r == rune(plus) || r == rune(minus) || r == rune(dot) {
+		return i, errorAt(i, c.data[i])
+	}
+	return i, nil
+}
+
+// numberValue converts the number, see number
+func (c *json5Converter) numberValue(i int) (int, error) {
+	if c.data[i] == plus {
+		i++
+	} else if c.data[i] == minus {
+		c.emit(i, minus)
+		i++
+	}
+	if i < len(c.data) && (c.data[i] == plus || c.data[i] == minus) {
+		return i, errorAt(i, c.data[i])
+	}
+	for _, literal := range [][]byte{_infinity, _nan} {
+		if bytes.HasPrefix(c.data[i:], literal) {
+			c.emit(i, literal...)
+			return i + len(literal), nil
+		}
+	}
+	if i+1 < len(c.data) && c.data[i] == '0' && (c.data[i+1] == 'x' || c.data[i+1] == 'X') {
+		start := i + 2
+		for i = start; i < len(c.data) && isHex(c.data[i]); i++ {
+		}
+		if i == start {
+			if i >= len(c.data) {
+				return i, Error{Type: UnexpectedEOF, Index: i}
+			}
+			return i, errorAt(i, c.data[i])
+		}
+		value, _ := new(big.Int).SetString(string(c.data[start:i]), 16)
+		c.emit(start-2, []byte(value.String())...)
+		return i, nil
+	}
+	digits := func() (count int) {
+		for ; i < len(c.data) && c.data[i] >= '0' && c.data[i] <= '9'; i++ {
+			c.emit(i, c.data[i])
+			count++
+		}
+		return count
+	}
+	if i < len(c.data) && c.data[i] == dot {
+		c.emit(i, '0')
+	}
+	count := digits()
+	if i < len(c.data) && c.data[i] == dot {
+		c.emit(i, dot)
+		i++
+		if digits() == 0 {
+			if count == 0 {
+				return i, errorAt(i-1, dot)
+			}
+			c.emit(i-1, '0')
+		}
+	}
+	if i < len(c.data) && (c.data[i] == 'e' || c.data[i] == 'E') { // exponent is the same as in JSON
+		c.emit(i, c.data[i])
+		if i++; i < len(c.data) && (c.data[i] == plus || c.data[i] == minus) {
+			c.emit(i, c.data[i])
+			i++
+		}
+		digits()
+	}
+	return i, nil
+}
+
+// identifier converts the unquoted key of the object into the double quoted string, or checks the literal value
+func (c *json5Converter) identifier(i int) (int, error) {
+	start := i
+	for i < len(c.data) {
+		r, size := utf8.DecodeRune(c.data[i:])
+		if !isIdentifierRune(r, i == start) {
+			break
+		}
+		i += size
+	}
+	word := c.data[start:i]
+	next, err := c.skip(i)
+	if err != nil {
+		return i, err
+	}
+	if next < len(c.data) && c.data[next] == colon {
+		c.emit(start, quotes)
+		c.emit(start, word...)
+		c.emit(start, quotes)
+		return i, nil
+	}
+	for _, literal := range [][]byte{_true, _false, _null, _infinity, _nan} {
+		if bytes.Equal(word, literal) {
+			c.emit(start, word...)
+			return i, nil
+		}
+	}
+	return i, errorAt(start, c.data[start])
+}
+
+// isHex returns true if the byte is the hexadecimal digit
+func isHex(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
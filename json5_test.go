@@ -0,0 +1,186 @@
+package ajson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNode_MarshalJSON5(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"name": "it's \"foo\"", "$id": 1.50, "_x1": true, "first name": null, "1st": [1, "a\nb", {}], "a-b": {"": []}, "ключ": "\\"}`)))
+	expected := `{
+  name: 'it\'s "foo"',
+  $id: 1.50,
+  _x1: true,
+  'first name': null,
+  '1st': [
+    1,
+    'a\nb',
+    {},
+  ],
+  'a-b': {
+    '': [],
+  },
+  ключ: '\\',
+}`
+	result, err := root.MarshalJSON5()
+	if err != nil {
+		t.Errorf("MarshalJSON5() unexpected error: %s", err)
+	} else if string(result) != expected {
+		t.Errorf("Wrong result\nExpected: %s\nActual:   %s", expected, result)
+	}
+
+	array := ArrayNode("", []*Node{NumericNode("", math.Inf(-1)), NumericNode("", math.NaN()), StringNode("", "x")})
+	if result, err = array.MarshalJSON5(); err != nil || string(result) != "[\n  -Infinity,\n  NaN,\n  'x',\n]" {
+		t.Errorf("MarshalJSON5() wrong result of the non-finite numbers: %q, %v", result, err)
+	}
+
+	root = Must(Unmarshal([]byte(`{"first name": null, "1st": [1.5, -2, "a\nb\\c"], "nested": {"": [true]}}`)))
+	if err = root.MustKey("nested").MustKey("").AppendArray(StringNode("", "a'b\t")); err != nil {
+		t.Errorf("AppendArray() unexpected error: %s", err)
+	}
+	expected = `{
+  'first name': null,
+  '1st': [
+    1.5,
+    -2,
+    'a\nb\\c',
+  ],
+  nested: {
+    '': [
+      true,
+      'a\'b\t',
+    ],
+  },
+}`
+	if result, err = root.MarshalJSON5(); err != nil || string(result) != expected {
+		t.Errorf("Wrong result of the modified node\nExpected: %s\nActual:   %s, %v", expected, result, err)
+	}
+
+	var node *Node
+	if _, err = node.MarshalJSON5(); err == nil {
+		t.Errorf("MarshalJSON5() expected error on nil node")
+	}
+	if _, err = ArrayNode("", []*Node{valueNode(nil, "", Numeric, "foo")}).MarshalJSON5(); err == nil {
+		t.Errorf("MarshalJSON5() expected error on wrong value")
+	}
+}
+
+func TestUnmarshalJSON5(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "unquoted keys", input: `{foo: 1, $bar: 2, _baz1: 3, ключ: 4, null: 5}`, expected: `{"foo":1,"$bar":2,"_baz1":3,"ключ":4,"null":5}`},
+		{name: "single-quoted strings", input: `{'a b': 'it\'s "foo"', "c": 'x'}`, expected: `{"a b":"it's \"foo\"","c":"x"}`},
+		{name: "raw tab", input: "['a\tb', \"\tc\"]", expected: `["a\tb","\tc"]`},
+		{name: "control characters", input: "['a\x01b']", expected: `["a\u0001b"]`},
+		{name: "escapes", input: `['\x41\v\0', "\a\/\u0042"]`, expected: `["\u0041\u000b\u0000","a\/\u0042"]`},
+		{name: "line continuation", input: "'foo\\\nbar\\\r\nbaz'", expected: `"foobarbaz"`},
+		{name: "trailing commas", input: `{"a": [1, 2, ], "b": {c: 3,},}`, expected: `{"a":[1,2],"b":{"c":3}}`},
+		{name: "trailing comma with comment", input: "[1, // one\n /* end */ ]", expected: `[1]`},
+		{name: "line comments", input: "// header\n{a: 1, // a\nb: 2 // b\n}", expected: `{"a":1,"b":2}`},
+		{name: "block comments", input: `/* header */ {a: /* 2 */ 1}`, expected: `{"a":1}`},
+		{name: "comments in strings", input: `['// not a comment', "/* not a comment */"]`, expected: `["// not a comment","/* not a comment */"]`},
+		{name: "hex numbers", input: `[0x1F, 0XfF, -0x10, +0x0]`, expected: `[31,255,-16,0]`},
+		{name: "leading decimal point", input: `[.5, -.25, +.1e2]`, expected: `[0.5,-0.25,0.1e2]`},
+		{name: "trailing decimal point", input: `[5., -1.e3]`, expected: `[5.0,-1.0e3]`},
+		{name: "plus sign", input: `[+1, +1.5]`, expected: `[1,1.5]`},
+		{name: "non-finite numbers", input: `[Infinity, -Infinity, +Infinity, NaN]`, expected: `[Infinity,-Infinity,Infinity,NaN]`},
+		{name: "additional whitespaces", input: "\ufeff{\va:\f1,\u00a0b:\u20282}", expected: `{"a":1,"b":2}`},
+		{name: "plain JSON", input: `{"a": [true, false, null, "x", -1.5e-3]}`, expected: `{"a":[true,false,null,"x",-1.5e-3]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalJSON5([]byte(test.input))
+			if err != nil {
+				t.Errorf("UnmarshalJSON5() unexpected error: %s", err)
+				return
+			}
+			if actual := string(root.Bytes()); actual != test.expected {
+				t.Errorf("UnmarshalJSON5() wrong result: %s, expected %s", actual, test.expected)
+			}
+		})
+	}
+
+	root := Must(UnmarshalJSON5([]byte(`{a: 0x10, b: '\x41'}`)))
+	if value := root.MustKey("a").MustNumeric(); value != 16 {
+		t.Errorf("UnmarshalJSON5() wrong value of the hex number: %v", value)
+	}
+	if value := root.MustKey("b").MustString(); value != "A" {
+		t.Errorf("UnmarshalJSON5() wrong value of the string: %v", value)
+	}
+}
+
+func TestUnmarshalJSON5_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		_type ErrorType
+		index int
+	}{
+		{name: "unquoted value", input: `{a: foo}`, _type: WrongSymbol, index: 4},
+		{name: "wrong symbol after key", input: `{a b: 1}`, _type: WrongSymbol, index: 1},
+		{name: "wrong symbol after comment", input: `[1, /* comment */ 2 3]`, _type: WrongSymbol, index: 20},
+		{name: "unterminated block", input: `[1, /* 2]`, _type: UnexpectedEOF, index: 4},
+		{name: "unterminated string", input: `['foo]`, _type: UnexpectedEOF, index: 1},
+		{name: "new line in string", input: "['foo\nbar']", _type: WrongSymbol, index: 5},
+		{name: "wrong hex escape", input: `['\x4g']`, _type: WrongSymbol, index: 5},
+		{name: "octal escape", input: `['\01']`, _type: WrongSymbol, index: 4},
+		{name: "wrong hex number", input: `[0x]`, _type: WrongSymbol, index: 3},
+		{name: "single decimal point", input: `[.]`, _type: WrongSymbol, index: 1},
+		{name: "double comma", input: `[1,,]`, _type: WrongSymbol, index: 3},
+		{name: "empty array with comma", input: `[,]`, _type: WrongSymbol, index: 1},
+		{name: "empty object with comma", input: `{ , }`, _type: WrongSymbol, index: 2},
+		{name: "leading comma", input: `[, 1]`, _type: WrongSymbol, index: 1},
+		{name: "comma after comment", input: `{a: 1, /* b */ , }`, _type: WrongSymbol, index: 15},
+		{name: "comma at the start", input: `,`, _type: WrongSymbol, index: 0},
+		{name: "double sign", input: `[+-1]`, _type: WrongSymbol, index: 2},
+		{name: "double minus", input: `[--1]`, _type: WrongSymbol, index: 2},
+		{name: "hex fraction", input: `[0x1.5]`, _type: WrongSymbol, index: 4},
+		{name: "hex exponent", input: `[0x1e+5]`, _type: WrongSymbol, index: 5},
+		{name: "number followed by number", input: `[1+2]`, _type: WrongSymbol, index: 2},
+		{name: "number followed by letter", input: `[1x]`, _type: WrongSymbol, index: 2},
+		{name: "unknown literal", input: `[Infinityx]`, _type: WrongSymbol, index: 1},
+		{name: "unexpected end", input: `{a: [1`, _type: UnexpectedEOF, index: 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalJSON5([]byte(test.input))
+			if err == nil {
+				t.Errorf("UnmarshalJSON5() expected error")
+				return
+			}
+			if value, ok := err.(Error); !ok || value.Type != test._type || value.Index != test.index {
+				t.Errorf("UnmarshalJSON5() wrong error: %#v", err)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON5_roundTrip(t *testing.T) {
+	data := []byte(`{"name": "it's \"foo\"", "first name": null, "1st": [1.5, -2, "a\nb\\c", {}], "nested": {"": [true, false]}, "ключ": "значение"}`)
+	root := Must(Unmarshal(data))
+	result, err := root.MarshalJSON5()
+	if err != nil {
+		t.Errorf("MarshalJSON5() unexpected error: %s", err)
+		return
+	}
+	parsed, err := UnmarshalJSON5(result)
+	if err != nil {
+		t.Errorf("UnmarshalJSON5() unexpected error: %s\n%s", err, result)
+		return
+	}
+	if ok, err := parsed.Eq(root); err != nil || !ok {
+		t.Errorf("Wrong round trip\nExpected: %s\nActual:   %s", root.Bytes(), parsed.Bytes())
+	}
+
+	root = ArrayNode("", []*Node{NumericNode("", math.Inf(1)), NumericNode("", math.Inf(-1))})
+	if result, err = root.MarshalJSON5(); err != nil {
+		t.Errorf("MarshalJSON5() unexpected error: %s", err)
+		return
+	}
+	if parsed, err = UnmarshalJSON5(result); err != nil || !math.IsInf(parsed.MustIndex(0).MustNumeric(), 1) || !math.IsInf(parsed.MustIndex(1).MustNumeric(), -1) {
+		t.Errorf("Wrong round trip of the non-finite numbers: %s, %v", result, err)
+	}
+}
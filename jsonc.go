@@ -0,0 +1,53 @@
+package ajson
+
+// UnmarshalJSONC parses the JSON with comments: line comments `// ...` and block comments `/* ... */` are allowed
+// everywhere as whitespaces. Comments are replaced by spaces in the copy of data, so the indexes in the errors and
+// borders of the nodes point to the same positions as in the original data. Source of the nodes contains spaces instead of comments.
+func UnmarshalJSONC(data []byte) (root *Node, err error) {
+	safe, err := stripComments(data)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(safe)
+}
+
+// stripComments returns a copy of data with the comments replaced by spaces, line breaks inside the comments are kept
+func stripComments(data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	copy(result, data)
+	str := false
+	for i := 0; i < len(result); i++ {
+		c := result[i]
+		switch {
+		case str:
+			if c == backslash {
+				i++
+			} else if c == quotes {
+				str = false
+			}
+		case c == quotes:
+			str = true
+		case c == division && i+1 < len(result) && result[i+1] == division:
+			for ; i < len(result) && result[i] != skipN && result[i] != skipR; i++ {
+				result[i] = skipS
+			}
+		case c == division && i+1 < len(result) && result[i+1] == asterisk:
+			start := i
+			result[i], result[i+1] = skipS, skipS
+			for i += 2; ; i++ {
+				if i+1 >= len(result) {
+					return nil, Error{Type: UnexpectedEOF, Index: start}
+				}
+				if result[i] == asterisk && result[i+1] == division {
+					result[i], result[i+1] = skipS, skipS
+					i++
+					break
+				}
+				if result[i] != skipN && result[i] != skipR {
+					result[i] = skipS
+				}
+			}
+		}
+	}
+	return result, nil
+}
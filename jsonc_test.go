@@ -0,0 +1,73 @@
+package ajson
+
+import (
+	"testing"
+)
+
+func TestUnmarshalJSONC(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "line", input: "{\"a\": 1 // comment\n}", expected: `{"a":1}`},
+		{name: "line at the end", input: "[1, 2] // comment", expected: `[1,2]`},
+		{name: "block", input: `{/* comment */"a": /* "b": 2, */ [1, /**/ 2]}`, expected: `{"a":[1,2]}`},
+		{name: "multiline block", input: "/*\n * header\n */\n{\"a\": \"b\"}", expected: `{"a":"b"}`},
+		{name: "in strings", input: `{"a": "// not a comment", "b": "/* not a comment */"}`, expected: `{"a":"// not a comment","b":"/* not a comment */"}`},
+		{name: "escaped quote", input: `["\" // not a comment"] // comment`, expected: `["\" // not a comment"]`},
+		{name: "no comments", input: `{"a": [true, null]}`, expected: `{"a":[true,null]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := []byte(test.input)
+			root, err := UnmarshalJSONC(data)
+			if err != nil {
+				t.Errorf("UnmarshalJSONC() unexpected error: %s", err)
+				return
+			}
+			if actual := string(root.Bytes()); actual != test.expected {
+				t.Errorf("UnmarshalJSONC() wrong result: %s, expected %s", actual, test.expected)
+			}
+			if string(data) != test.input {
+				t.Errorf("UnmarshalJSONC() changed the original data")
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSONC_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		_type ErrorType
+		index int
+	}{
+		{name: "after block", input: `{"a": /* comment */ x}`, _type: WrongSymbol, index: 20},
+		{name: "after multiline block", input: "[1,\n/* one\ntwo */\n2 3]", _type: WrongSymbol, index: 20},
+		{name: "after line", input: "[1, // comment\n }", _type: WrongSymbol, index: 16},
+		{name: "single slash", input: `[1, / 2]`, _type: WrongSymbol, index: 4},
+		{name: "unterminated block", input: `[1, /* 2]`, _type: UnexpectedEOF, index: 4},
+		{name: "only comments", input: `/* comment */ // comment`, _type: UnexpectedEOF},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalJSONC([]byte(test.input))
+			if err == nil {
+				t.Errorf("UnmarshalJSONC() expected error")
+				return
+			}
+			current, ok := err.(Error)
+			if !ok {
+				t.Errorf("UnmarshalJSONC() unexpected error type: %T", err)
+				return
+			}
+			if current.Type != test._type {
+				t.Errorf("UnmarshalJSONC() wrong error: %s", err)
+			}
+			if test.index != 0 && current.Index != test.index {
+				t.Errorf("UnmarshalJSONC() wrong index of error: %d, expected %d (%q)", current.Index, test.index, test.input[current.Index:])
+			}
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package ajson
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // JSONPath returns slice of founded elements in current JSON data, by it's JSONPath.
@@ -37,14 +41,20 @@ import (
 //
 //    $          the root object/element
 //    @          the current object/element
+//    @root      the root object/element in the expressions, the same as `$`
+//    @parent    the parent of the current object/element in the expressions, i.e. the filtered container in `?(@.price > @parent.avg)`; null for the root
 //    . or []  child operator
-//    ..      recursive descent. JSONPath borrows this syntax from E4X.
-//    *       wildcard. All objects/elements regardless their names.
+//    ..      recursive descent. JSONPath borrows this syntax from E4X. Each node is returned only once, even for the chained descents like `$..a..b`.
+//    *       wildcard. All objects/elements regardless their names. Children of Object are selected in order of the sorted keys, like in InheritorsSorted.
+//    b*      glob in the key of object: `*` matches any sequence of characters, `?` matches any single character. Quoted keys are matched exactly.
 //    []      subscript operator. XPath uses it to iterate over element collections and for predicates. In Javascript and JSON it is the native array operator.
-//    [,]     Union operator in XPath results in a combination of node sets. JSONPath allows alternate names or array indices as a set.
+//    [,]     Union operator in XPath results in a combination of node sets. JSONPath allows alternate names, array indices or slices as a set, like `[0,2:4]`. Each matched node is returned only once.
 //    [start:end:step]  array slice operator borrowed from ES4.
 //    ?()     applies a filter (script) expression.
 //    ()      script expression, using the underlying script engine.
+//    .length pseudo-property: size of array/object or length of string. Real field of object named `length` has a priority.
+//    ~       keys operator: names of the found members of objects as strings, or indexes of the found elements of arrays as numbers, like `$.store.*~`. Quote the keys ending with `~`.
+//    ^       parent operator: parents of the found elements, like `$.store.book.^.bicycle`. Each parent is returned only once, the root element stays as is. Quote the key `^`.
 //
 //
 // JSONPath Script engine
@@ -75,6 +85,8 @@ import (
 //
 // Package has several predefined operators. You are free to add new one with AddOperator
 //
+// Use RegisterOperator to add a binary operator with one or two symbols, like `~=`, which rejects the symbols already in use.
+//
 // Operator precedence: https://golang.org/ref/spec#Operator_precedence
 //
 //     Precedence    Operator
@@ -108,12 +120,22 @@ import (
 //     <=  less or equals          any
 //     >   larger                  any
 //     >=  larger or equals        any
-//     =~  equals regex string     strings
+//     =~  equals regex string     strings, pattern with the flags i, m, s is wrapped into slashes: `/^a/i`
+//
+//     !   not                     any (unary, i.e. `!(@.foo == 1 || @.bar == 2)`)
+//
+// Comparison is strict by default: `"10" == 10` is false. Use JSONPathLoose or EvalLoose to compare numeric strings with numbers as numbers.
+//
+// Filter expression over the path, which is not found, is false: for the element without `a` both `@.a == 1` and `@.a != 1` are false, while `!(@.a == 1)` is true.
+//
+// Operators over the paths with several results, like `@..price > 100` or `@.* == null`, are existential: the result is true if it is true for any of the found nodes, and false if nothing is found. Functions get such paths as an array, like `avg(@..price)`.
 //
 // Supported functions
 //
 // Package has several predefined functions. You are free to add new one with AddFunction
 //
+// Functions with several comma separated arguments, like `distance(@.from, @.to)`, can be added with RegisterFunction
+//
 //     abs          math.Abs          integers, floats
 //     acos         math.Acos         integers, floats
 //     acosh        math.Acosh        integers, floats
@@ -136,6 +158,7 @@ import (
 //     factorial    N!                unsigned integer
 //     floor        math.Floor        integers, floats
 //     gamma        math.Gamma        integers, floats
+//     index        position          any, like `index(@)`: index of the node in the parent array, or null
 //     j0           math.J0           integers, floats
 //     j1           math.J1           integers, floats
 //     length       len               array
@@ -144,10 +167,12 @@ import (
 //     log1p        math.Log1p        integers, floats
 //     log2         math.Log2         integers, floats
 //     logb         math.Logb         integers, floats
+//     match        regexp.Match      string and pattern, like `match(@.sku, '/^a/i')`
 //     not          not               any
 //     pow10        math.Pow10        integer
 //     round        math.Round        integers, floats
 //     roundtoeven  math.RoundToEven  integers, floats
+//     search       regexp.Submatch   string and pattern: the first capture group, or the match, or null
 //     sin          math.Sin          integers, floats
 //     sinh         math.Sinh         integers, floats
 //     sqrt         math.Sqrt         integers, floats
@@ -158,15 +183,265 @@ import (
 //     y1           math.Y1           integers, floats
 //
 func JSONPath(data []byte, path string) (result []*Node, err error) {
-	commands, err := ParseJSONPath(path)
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	node, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return deReference(context.Background(), node, commands)
+}
+
+// JSONPathLoose do the same thing as JSONPath, but with loose comparison in the filters and the expressions: numeric string
+// is compared with the Numeric value as a number, so `$[?(@.price == 10)]` matches `{"price": "10"}`.
+func JSONPathLoose(data []byte, path string) (result []*Node, err error) {
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	node, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return deReference(context.WithValue(context.Background(), looseKey{}, true), node, commands)
+}
+
+// JSONPathTimeout do the same thing as JSONPath, but aborts the evaluation of the path after the duration d and returns the Timeout error,
+// which matches context.DeadlineExceeded in errors.Is.
+// It protects from the slow requests over the untrusted data, like nested descents and filters. Parsing of the data is not limited.
+func JSONPathTimeout(data []byte, path string, d time.Duration) (result []*Node, err error) {
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	node, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return deReference(ctx, node, commands)
+}
+
+// JSONPathWithRoot returns slice of founded elements in current JSON data, by the path relative to the nodes founded by rootPath.
+// It's the same as the concatenated path: JSONPathWithRoot(data, "$.data.results", "$[*].id") is JSONPath(data, "$.data.results[*].id").
+// The leading `$` or `@` of the path refers to the nodes founded by rootPath, but `$` inside the expressions still refers to the root of the data.
+func JSONPathWithRoot(data []byte, rootPath, path string) (result []*Node, err error) {
+	prefix, err := parseJSONPath(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) != 0 && (commands[0] == "$" || commands[0] == "@") {
+		commands = commands[1:]
+	}
+	node, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	joined := make([]string, 0, len(prefix)+len(commands)) // commands are shared by the cache
+	joined = append(append(joined, prefix...), commands...)
+	return deReference(context.Background(), node, joined)
+}
+
+// JSONPathValue returns slice of founded elements in the native Go value, like map[string]interface{}, by it's JSONPath.
+// Value is converted to the tree of nodes without marshaling, except the types unknown for JSON, which are converted with json.Marshal.
+func JSONPathValue(value interface{}, path string) (result []*Node, err error) {
+	commands, err := parseJSONPath(path)
 	if err != nil {
 		return nil, err
 	}
+	node, err := interfaceNode(value)
+	if err != nil {
+		return nil, err
+	}
+	return deReference(context.Background(), node, commands)
+}
+
+// Count returns the count of nodes found by JSONPath request in the data. Nodes found by the last step of the path are counted
+// without collecting the result, values of the found nodes are not calculated.
+func Count(data []byte, path string) (int, error) {
+	node, err := Unmarshal(data)
+	if err != nil {
+		return 0, err
+	}
+	return node.Count(path)
+}
+
+// ForEachMatch calls fn for each of the founded elements in the data by JSONPath request, the data is parsed only once.
+// Iteration stops on the first error returned by fn, and the error is returned as is.
+func ForEachMatch(data []byte, path string, fn func(*Node) error) error {
+	result, err := JSONPath(data, path)
+	if err != nil {
+		return err
+	}
+	for _, node := range result {
+		if err = fn(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyEach evaluates the relative JSONPath against each of the nodes, e.g. the result of the previous request, and returns the union of the founded elements.
+// The symbol '@' in the path refers to each of the nodes, and each founded element is returned only once, in order of the nodes.
+func ApplyEach(nodes []*Node, relPath string) ([]*Node, error) {
+	commands, err := parseJSONPath(relPath)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Node, 0)
+	unique := make(map[*Node]bool)
+	for _, node := range nodes {
+		found, err := deReference(context.Background(), node, commands)
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range found {
+			if !unique[element] {
+				unique[element] = true
+				result = append(result, element)
+			}
+		}
+	}
+	return result, nil
+}
+
+// SortByPath sorts the nodes, e.g. the result of the previous request, by the value founded by the relative JSONPath in each of them, like `@.price`.
+// Numeric values are sorted before String values, Bool values are the last ones. The nodes without the single scalar value by the path,
+// including Null, are moved to the end in both orders. Sort is stable.
+func SortByPath(nodes []*Node, relPath string, ascending bool) error {
+	commands, err := parseJSONPath(relPath)
+	if err != nil {
+		return err
+	}
+	type sortKey struct {
+		rank   int
+		number float64
+		text   string
+	}
+	keys := make(map[*Node]sortKey, len(nodes))
+	for _, node := range nodes {
+		found, err := deReference(context.Background(), node, commands)
+		if err != nil {
+			return err
+		}
+		key := sortKey{rank: 3}
+		if len(found) == 1 {
+			switch found[0].Type() {
+			case Numeric:
+				key.rank = 0
+				key.number, err = found[0].GetNumeric()
+			case String:
+				key.rank = 1
+				key.text, err = found[0].GetString()
+			case Bool:
+				var value bool
+				key.rank = 2
+				if value, err = found[0].GetBool(); value {
+					key.number = 1
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		keys[node] = key
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		left, right := keys[nodes[i]], keys[nodes[j]]
+		if left.rank == 3 || right.rank == 3 {
+			return right.rank == 3 && left.rank != 3
+		}
+		if !ascending {
+			left, right = right, left
+		}
+		if left.rank != right.rank {
+			return left.rank < right.rank
+		}
+		if left.rank == 1 {
+			return left.text < right.text
+		}
+		return left.number < right.number
+	})
+	return nil
+}
+
+// JSONPathMulti returns slices of founded elements for each of the labeled paths, the data is parsed only once.
+// Results of the correct paths are returned even if another paths are wrong, the error contains labels and errors of all the wrong paths.
+func JSONPathMulti(data []byte, paths map[string]string) (map[string][]*Node, error) {
 	node, err := Unmarshal(data)
 	if err != nil {
 		return nil, err
 	}
-	return deReference(node, commands)
+	labels := make([]string, 0, len(paths))
+	for label := range paths {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	result := make(map[string][]*Node, len(paths))
+	failed := make([]string, 0)
+	for _, label := range labels {
+		nodes, err := node.JSONPath(paths[label])
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("'%s': %s", label, err))
+			continue
+		}
+		result[label] = nodes
+	}
+	if len(failed) != 0 {
+		return result, errorRequest("wrong paths: %s", strings.Join(failed, "; "))
+	}
+	return result, nil
+}
+
+// JSONPathVars returns slice of founded elements in current JSON data, by it's JSONPath with `${name}` placeholders, substituted by vars.
+//
+// Integer values are substituted as is, to be used as the array indexes: `$.users[${idx}].name`.
+// Other values are substituted as the quoted strings with escaped quotes, so they can't inject the path operators:
+// `$.users[${key}]` is `$.users['value']` and `$[?(@.name == ${name})]` compares with the string. Unknown placeholder is an error.
+func JSONPathVars(data []byte, path string, vars map[string]string) (result []*Node, err error) {
+	path, err = substituteVars(path, vars)
+	if err != nil {
+		return nil, err
+	}
+	return JSONPath(data, path)
+}
+
+// substituteVars replaces `${name}` placeholders in the path by the values of vars, see JSONPathVars
+func substituteVars(path string, vars map[string]string) (string, error) {
+	var result strings.Builder
+	for {
+		start := strings.Index(path, "${")
+		if start == -1 {
+			result.WriteString(path)
+			return result.String(), nil
+		}
+		end := strings.IndexByte(path[start:], bracesR)
+		if end == -1 {
+			return "", errorRequest("unclosed placeholder in path: %s", path[start:])
+		}
+		end += start
+		name := path[start+2 : end]
+		value, ok := vars[name]
+		if !ok {
+			return "", errorRequest("unknown variable: %s", name)
+		}
+		result.WriteString(path[:start])
+		if _, err := strconv.Atoi(value); err == nil {
+			result.WriteString(value)
+		} else {
+			result.WriteByte(quote)
+			result.WriteString(keyReplacer.Replace(value))
+			result.WriteByte(quote)
+		}
+		path = path[end+1:]
+	}
 }
 
 // Paths returns calculated paths of underlying nodes
@@ -179,9 +454,9 @@ func Paths(array []*Node) []string {
 }
 
 func recursiveChildren(node *Node) (result []*Node) {
-	if node.isContainer() {
-		for _, element := range node.Inheritors() {
-			if element.isContainer() {
+	if node.IsContainer() {
+		for _, element := range node.InheritorsSorted() {
+			if element.IsContainer() {
 				result = append(result, element)
 			}
 		}
@@ -195,12 +470,22 @@ func recursiveChildren(node *Node) (result []*Node) {
 }
 
 // ParseJSONPath will parse current path and return all commands tobe run.
+// Bare numeric keys are both the array indexes and the object keys: `$.0` and `$[0]` are the element of Array or the key "0" of Object,
+// while the quoted key `$['0']` is the key of Object only.
+// Path without the leading `$` or `@` is relative to the root: `store.book[0]` is the same as `$.store.book[0]`.
 // Example:
 //
 // 	result, _ := ParseJSONPath("$.store.book[?(@.price < 10)].title")
 // 	result == []string{"$", "store", "book", "?(@.price < 10)", "title"}
 //
 func ParseJSONPath(path string) (result []string, err error) {
+	if path != "" && path[0] != dollar && path[0] != at {
+		if path[0] == dot || path[0] == bracketL {
+			path = string(dollar) + path
+		} else {
+			path = string(dollar) + string(dot) + path
+		}
+	}
 	buf := newBuffer([]byte(path))
 	result = make([]string, 0)
 	const (
@@ -221,7 +506,7 @@ func ParseJSONPath(path string) (result []string, err error) {
 		}
 	parseSwitch:
 		switch true {
-		case c == dollar || c == at:
+		case c == dollar || c == at || c == tilde || c == caret:
 			result = append(result, string(c))
 		case c == dot:
 			start = buf.index
@@ -250,7 +535,15 @@ func ParseJSONPath(path string) (result []string, err error) {
 				break
 			}
 			if start+1 < stop {
-				result = append(result, string(buf.data[start+1:stop]))
+				key := string(buf.data[start+1 : stop])
+				names := len(key) > 1 && key[len(key)-1] == tilde // keys operator after the name: `$.*~`
+				if names {
+					key = key[:len(key)-1]
+				}
+				result = append(result, key)
+				if names {
+					result = append(result, string(tilde))
+				}
 			}
 		case c == bracketL:
 			_, err = buf.next()
@@ -307,22 +600,53 @@ func ParseJSONPath(path string) (result []string, err error) {
 	return
 }
 
-func deReference(node *Node, commands []string) (result []*Node, err error) {
+// countReference returns the count of the nodes found by the commands, up to the limit, if it's positive. Nodes of the last command are
+// counted for each element of the previous result, without collecting them, unless the command returns each node only once: `..` and `^`.
+func countReference(ctx context.Context, node *Node, commands []string, limit int) (count int, err error) {
+	last := len(commands) - 1
+	if last < 1 || commands[last] == ".." || commands[last] == "^" {
+		result, err := deReference(ctx, node, commands)
+		if limit > 0 && len(result) > limit {
+			return limit, err
+		}
+		return len(result), err
+	}
+	elements, err := deReference(ctx, node, commands[:last])
+	if err != nil {
+		return 0, err
+	}
+	step := []string{"@", commands[last]}
+	for _, element := range elements {
+		nodes, err := deReference(ctx, element, step)
+		if err != nil {
+			return 0, err
+		}
+		if count += len(nodes); limit > 0 && count >= limit {
+			return limit, nil
+		}
+	}
+	return count, nil
+}
+
+func deReference(ctx context.Context, node *Node, commands []string) (result []*Node, err error) {
 	result = make([]*Node, 0)
 	var (
 		temporary   []*Node
 		keys        []string
-		ikeys       [3]int
 		fkeys       [3]float64
 		num         int
 		key         string
 		ok          bool
 		value, temp *Node
+		nodes       []*Node
 		float       float64
 		tokens      tokens
 		expr        rpn
 	)
 	for i, cmd := range commands {
+		if ctx.Err() != nil {
+			return nil, errorTimeout()
+		}
 		tokens, err = newBuffer([]byte(cmd)).tokenize()
 		if err != nil {
 			return
@@ -336,131 +660,100 @@ func deReference(node *Node, commands []string) (result []*Node, err error) {
 			if i == 0 {
 				result = append(result, node)
 			}
-		case cmd == "..": // recursive descent
+		case cmd == "..": // recursive descent, each node is returned only once, if the descents are overlapped: `$..a..b`
 			temporary = make([]*Node, 0)
+			unique := make(map[*Node]bool)
 			for _, element := range result {
-				temporary = append(temporary, recursiveChildren(element)...)
+				if !unique[element] {
+					unique[element] = true
+					temporary = append(temporary, element)
+				}
+			}
+			for _, element := range result {
+				if ctx.Err() != nil {
+					return nil, errorTimeout()
+				}
+				for _, child := range recursiveChildren(element) {
+					if !unique[child] {
+						unique[child] = true
+						temporary = append(temporary, child)
+					}
+				}
 			}
-			result = append(result, temporary...)
+			result = temporary
 		case cmd == "*": // wildcard
 			temporary = make([]*Node, 0)
 			for _, element := range result {
-				temporary = append(temporary, element.Inheritors()...)
+				temporary = append(temporary, element.InheritorsSorted()...)
+			}
+			result = temporary
+		case cmd == "~": // keys of the elements: names in the parent Object, or indexes in the parent Array
+			temporary = make([]*Node, 0, len(result))
+			for _, element := range result {
+				if element.parent == nil {
+					continue
+				}
+				if element.parent.IsArray() && element.index != nil {
+					temporary = append(temporary, NumericNode("", float64(*element.index)))
+				} else if element.parent.IsObject() && element.key != nil {
+					temporary = append(temporary, StringNode("", *element.key))
+				}
+			}
+			result = temporary
+		case cmd == "^": // parent of the elements, each one is returned only once; the root element stays as is
+			temporary = make([]*Node, 0, len(result))
+			unique := make(map[*Node]bool)
+			for _, element := range result {
+				if element.parent != nil {
+					element = element.parent
+				}
+				if !unique[element] {
+					unique[element] = true
+					temporary = append(temporary, element)
+				}
 			}
 			result = temporary
-		case tokens.exists(":"): // array slice operator
-			if tokens.count(":") > 3 {
+		case tokens.exists(":") && !tokens.exists(","): // array slice operator
+			if tokens.count(":") > 2 {
 				return nil, errorRequest("slice must contains no more than 2 colons, got '%s'", cmd)
 			}
 			keys = tokens.slice(":")
 
 			temporary = make([]*Node, 0)
 			for _, element := range result {
-				if element.IsArray() && element.Size() > 0 {
-					if fkeys[0], err = getNumberIndex(element, keys[0], math.NaN()); err != nil {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-					if fkeys[1], err = getNumberIndex(element, keys[1], math.NaN()); err != nil {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-					if len(keys) < 3 {
-						fkeys[2] = 1
-					} else if fkeys[2], err = getNumberIndex(element, keys[2], 1); err != nil {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-
-					ikeys[2] = int(fkeys[2])
-					if ikeys[2] == 0 {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-
-					if math.IsNaN(fkeys[0]) {
-						if ikeys[2] > 0 {
-							ikeys[0] = 0
-						} else {
-							ikeys[0] = element.Size() - 1
-						}
-					} else {
-						ikeys[0] = getPositiveIndex(int(fkeys[0]), element.Size())
-					}
-					if math.IsNaN(fkeys[1]) {
-						if ikeys[2] > 0 {
-							ikeys[1] = element.Size()
-						} else {
-							ikeys[1] = -1
-						}
-					} else {
-						ikeys[1] = getPositiveIndex(int(fkeys[1]), element.Size())
-					}
-
-					if ikeys[2] > 0 {
-						if ikeys[0] < 0 {
-							ikeys[0] = 0
-						}
-						if ikeys[1] > element.Size() {
-							ikeys[1] = element.Size()
-						}
-
-						for i := ikeys[0]; i < ikeys[1]; i += ikeys[2] {
-							value, ok := element.children[strconv.Itoa(i)]
-							if ok {
-								temporary = append(temporary, value)
-							}
-						}
-					} else if ikeys[2] < 0 {
-						if ikeys[0] > element.Size() {
-							ikeys[0] = element.Size()
-						}
-						if ikeys[1] < -1 {
-							ikeys[1] = -1
-						}
-
-						for i := ikeys[0]; i > ikeys[1]; i += ikeys[2] {
-							value, ok := element.children[strconv.Itoa(i)]
-							if ok {
-								temporary = append(temporary, value)
-							}
-						}
-					}
+				if nodes, err = getSlice(ctx, element, keys, cmd); err != nil {
+					return nil, err
 				}
+				temporary = append(temporary, nodes...)
 			}
 			result = temporary
 		case strings.HasPrefix(cmd, "?(") && strings.HasSuffix(cmd, ")"): // applies a filter (script) expression
-			expr, err = newBuffer([]byte(cmd[2 : len(cmd)-1])).rpn()
+			filter, err := parseFilter(cmd[2 : len(cmd)-1])
 			if err != nil {
 				return nil, errorRequest("wrong request: %s", cmd)
 			}
 			temporary = make([]*Node, 0)
 			for _, element := range result {
-				if element.isContainer() {
-					for _, temp = range element.Inheritors() {
-						value, err = eval(temp, expr, cmd)
-						if err != nil {
-							return nil, errorRequest("wrong request: %s", cmd)
-						}
-						if value != nil {
-							ok, err = boolean(value)
-							if err != nil || !ok {
-								continue
-							}
-							temporary = append(temporary, temp)
-						}
-					}
+				if nodes, err = filterNodes(ctx, element, filter, cmd); err != nil {
+					return nil, err
 				}
+				temporary = append(temporary, nodes...)
 			}
 			result = temporary
 		case strings.HasPrefix(cmd, "(") && strings.HasSuffix(cmd, ")"): // script expression, using the underlying script engine
-			expr, err = newBuffer([]byte(cmd[1 : len(cmd)-1])).rpn()
+			expr, err = compileExpression(cmd[1 : len(cmd)-1])
 			if err != nil {
 				return nil, errorRequest("wrong request: %s", cmd)
 			}
 			temporary = make([]*Node, 0)
 			for _, element := range result {
-				if !element.isContainer() {
+				if !element.IsContainer() {
 					continue
 				}
-				temp, err = eval(element, expr, cmd)
-				if err != nil {
+				temp, err = eval(ctx, element, expr, cmd)
+				if isTimeout(err) {
+					return nil, err
+				} else if err != nil {
 					return nil, errorRequest("wrong request: %s", cmd)
 				}
 				if temp != nil {
@@ -476,7 +769,7 @@ func deReference(node *Node, commands []string) (result []*Node, err error) {
 						num, err = temp.getInteger()
 						if err == nil { // INTEGER
 							if num < 0 {
-								key = strconv.Itoa(element.Size() - num)
+								key = strconv.Itoa(element.Size() + num)
 							} else {
 								key = strconv.Itoa(num)
 							}
@@ -494,7 +787,7 @@ func deReference(node *Node, commands []string) (result []*Node, err error) {
 							return nil, errorRequest("wrong type convert: %s", err.Error())
 						}
 						if ok {
-							temporary = append(temporary, element.Inheritors()...)
+							temporary = append(temporary, element.InheritorsSorted()...)
 						}
 						continue
 						// case Array: // get all keys from element via array values
@@ -516,8 +809,36 @@ func deReference(node *Node, commands []string) (result []*Node, err error) {
 			}
 
 			temporary = make([]*Node, 0)
-			for _, key = range keys { // fixme
+			unique := make(map[*Node]bool)
+			for _, key = range keys {
+				if bounds, _ := tokenize(key); bounds.exists(":") { // slice as a member of the union: `[0,2:4]`
+					if bounds.count(":") > 2 {
+						return nil, errorRequest("slice must contains no more than 2 colons, got '%s'", cmd)
+					}
+					for _, element := range result {
+						if nodes, err = getSlice(ctx, element, bounds.slice(":"), cmd); err != nil {
+							return nil, err
+						}
+						for _, child := range nodes {
+							if !unique[child] {
+								unique[child] = true
+								temporary = append(temporary, child)
+							}
+						}
+					}
+					continue
+				}
 				for _, element := range result {
+					value, ok = nil, false
+					if element.IsObject() && isGlob(key) {
+						for _, child := range element.InheritorsSorted() {
+							if matchGlob(key, *child.key) && !unique[child] {
+								unique[child] = true
+								temporary = append(temporary, child)
+							}
+						}
+						continue
+					}
 					if element.IsArray() {
 						if key == "length" || key == "'length'" || key == "\"length\"" {
 							value, err = functions["length"](element)
@@ -526,40 +847,46 @@ func deReference(node *Node, commands []string) (result []*Node, err error) {
 							}
 							ok = true
 						} else if strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
-							fkeys[0], err = getNumberIndex(element, key, math.NaN())
+							fkeys[0], err = getNumberIndex(ctx, element, key, math.NaN())
 							if err != nil {
 								return nil, err
 							}
 							if math.IsNaN(fkeys[0]) {
 								return nil, errorRequest("wrong request: %s", cmd)
 							}
-							if element.Size() == 0 {
-								ok = false
-							} else {
-								num = getPositiveIndex(int(fkeys[0]), element.Size())
-								key = strconv.Itoa(num)
-								value, ok = element.children[key]
+							if element.Size() != 0 {
+								num = getPositiveIndex(sliceIndex(fkeys[0], element.Size()), element.Size())
+								value, ok = element.children[strconv.Itoa(num)]
 							}
-						} else {
-							key, _ = str(key)
+						} else if !isQuoted(key) { // quoted keys are for objects only
 							num, err = strconv.Atoi(key)
 							if err != nil || element.Size() == 0 {
-								ok = false
 								err = nil
 							} else {
 								num = getPositiveIndex(num, element.Size())
-								key = strconv.Itoa(num)
-								value, ok = element.children[key]
+								value, ok = element.children[strconv.Itoa(num)]
 							}
 						}
-
 					} else if element.IsObject() {
-						key, _ = str(key)
-						value, ok = element.children[key]
+						name, _ := str(key) // bare integer is the key of the object too, like `$.0`
+						value, ok = element.children[name]
+						if !ok && key == "length" { // real field has a priority over the pseudo-property
+							value, err = functions["length"](element)
+							if err != nil {
+								return
+							}
+							ok = true
+						}
+					} else if element.IsString() && key == "length" {
+						value, err = functions["length"](element)
+						if err != nil {
+							return
+						}
+						ok = true
 					}
-					if ok {
+					if ok && !unique[value] { // union of the same keys returns each node only once
+						unique[value] = true
 						temporary = append(temporary, value)
-						ok = false
 					}
 				}
 			}
@@ -569,16 +896,214 @@ func deReference(node *Node, commands []string) (result []*Node, err error) {
 	return
 }
 
+// ValidatePath checks the syntax of the JSONPath without the data: path should be parsed by ParseJSONPath,
+// slices should contain no more than 2 colons with the integer or script bounds and the non-zero step,
+// union should not contain empty keys, filter and script expressions should be valid for the script engine,
+// with the right count of the arguments of the functions and the constant patterns of match and search.
+// It is the same check, as the evaluation does before the execution, so JSONPath fails on the same paths regardless of the data.
+func ValidatePath(path string) error {
+	_, err := parsePath(path)
+	return err
+}
+
+func validateCommand(cmd string) error {
+	if cmd == "$" || cmd == "@" || cmd == ".." || cmd == "*" || cmd == "~" || cmd == "^" {
+		return nil
+	}
+	if cmd == "" {
+		return errorRequest("empty subscript")
+	}
+	tokens, err := newBuffer([]byte(cmd)).tokenize()
+	if err != nil {
+		return err
+	}
+	switch {
+	case tokens.exists(":") && !tokens.exists(","):
+		return validateSlice(tokens, cmd)
+	case strings.HasPrefix(cmd, "?(") && strings.HasSuffix(cmd, ")"):
+		if _, err = parseFilter(cmd[2 : len(cmd)-1]); err != nil {
+			return errorRequest("wrong request: %s", cmd)
+		}
+	case strings.HasPrefix(cmd, "(") && strings.HasSuffix(cmd, ")"):
+		if _, err = compileExpression(cmd[1 : len(cmd)-1]); err != nil {
+			return errorRequest("wrong request: %s", cmd)
+		}
+	case tokens.exists(","):
+		for _, key := range tokens.slice(",") {
+			if key == "" {
+				return errorRequest("wrong request: %s", cmd)
+			}
+			if bounds, _ := tokenize(key); bounds.exists(":") {
+				if err = validateSlice(bounds, cmd); err != nil {
+					return err
+				}
+			} else if strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
+				if _, err = compileExpression(key[1 : len(key)-1]); err != nil {
+					return errorRequest("wrong request: %s", cmd)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateSlice checks the bounds of the slice: integers or scripts, and the non-zero step
+func validateSlice(tokens tokens, cmd string) error {
+	if tokens.count(":") > 2 {
+		return errorRequest("slice must contains no more than 2 colons, got '%s'", cmd)
+	}
+	for i, key := range tokens.slice(":") {
+		if key == "" {
+			continue
+		}
+		if strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
+			if _, err := compileExpression(key[1 : len(key)-1]); err != nil {
+				return errorRequest("wrong request: %s", cmd)
+			}
+		} else if index, err := parseIndex(key); err != nil || (i == 2 && index == 0) {
+			return errorRequest("wrong request: %s", cmd)
+		}
+	}
+	return nil
+}
+
+// compileExpression returns the RPN of the script expression, checked the same way as eval runs it: arity of the operations
+// and the functions, literals, constant arguments of the functions and the inner paths. So the wrong expression is
+// reported before the evaluation, regardless of the data.
+func compileExpression(expression string) (expr rpn, err error) {
+	expr, err = newBuffer([]byte(expression)).rpn()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		stack = make([][]*Node, 0) // arguments of each value of the stack in eval: constant nodes or nil for the unknown value
+		bstr  []byte
+		temp  *Node
+		size  int
+		ok    bool
+	)
+	for _, exp := range expr {
+		size = len(stack)
+		if _, ok = functions[exp]; ok {
+			if size < 1 || len(stack[size-1]) != 1 {
+				return nil, errorRequest("wrong expression: %s", expression)
+			}
+			stack[size-1] = []*Node{nil}
+		} else if _, ok = variadicFunctions[exp]; ok {
+			if size < 1 {
+				return nil, errorRequest("wrong expression: %s", expression)
+			}
+			if check, ok := variadicChecks[exp]; ok {
+				if err = check(stack[size-1]); err != nil {
+					return nil, err
+				}
+			}
+			stack[size-1] = []*Node{nil}
+		} else if exp == string(coma) {
+			if size < 2 || len(stack[size-1]) != 1 {
+				return nil, errorRequest("wrong expression: %s", expression)
+			}
+			stack[size-2] = append(stack[size-2], stack[size-1][0])
+			stack = stack[:size-1]
+		} else if _, ok = operations[exp]; ok {
+			if size < 2 || len(stack[size-2]) != 1 || len(stack[size-1]) != 1 {
+				return nil, errorRequest("wrong expression: %s", expression)
+			}
+			stack[size-2] = []*Node{nil}
+			stack = stack[:size-1]
+		} else if len(exp) > 0 {
+			temp = nil
+			if exp[0] == dollar || exp[0] == at {
+				_, path := filterReference(nil, exp)
+				if _, err = parsePath(path); err != nil {
+					return nil, err
+				}
+			} else if constant, ok := constants[strings.ToLower(exp)]; ok {
+				temp = constant
+			} else {
+				bstr = []byte(exp)
+				if len(bstr) >= 2 && bstr[0] == quote && bstr[len(bstr)-1] == quote {
+					sstr, ok := unquote(bstr, quote)
+					if !ok {
+						return nil, errorRequest("wrong expression: %s", expression)
+					}
+					temp = StringNode("", sstr)
+				} else if temp, err = Unmarshal(bstr); err != nil {
+					return nil, err
+				}
+			}
+			stack = append(stack, []*Node{temp})
+		} else {
+			stack = append(stack, []*Node{nil})
+		}
+	}
+	if len(stack) > 1 || (len(stack) == 1 && len(stack[0]) != 1) {
+		return nil, errorRequest("wrong expression: %s", expression)
+	}
+	return expr, nil
+}
+
+// isMultiple returns true if the JSONPath commands can select several nodes: wildcards, descents, slices, unions, globs and filters
+func isMultiple(commands []string) bool {
+	for _, cmd := range commands {
+		if cmd == ".." || cmd == "*" || strings.HasPrefix(cmd, "?(") || isGlob(cmd) {
+			return true
+		}
+		if tokens, err := newBuffer([]byte(cmd)).tokenize(); err == nil && (tokens.exists(":") || tokens.exists(",")) {
+			return true
+		}
+	}
+	return false
+}
+
+// setNode returns the value of the operation results over the path with several results.
+// Only one of the Bool results should be true to make the result true, other results are collected into Array.
+func setNode(values []*Node) *Node {
+	result := true
+	for _, value := range values {
+		if value.Type() != Bool {
+			result = false
+			break
+		}
+	}
+	if result {
+		result = false
+		for _, value := range values {
+			if result, _ = value.GetBool(); result {
+				break
+			}
+		}
+		return valueNode(nil, "any", Bool, result)
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	nodes := make([]*Node, len(values))
+	for i, value := range values {
+		nodes[i] = value.detached()
+	}
+	return ArrayNode("", nodes)
+}
+
 // Eval evaluate expression `@.price == 19.95 && @.color == 'red'` to the result value i.e. Bool(true), Numeric(3.14), etc.
 func Eval(node *Node, cmd string) (result *Node, err error) {
-	calc, err := newBuffer([]byte(cmd)).rpn()
+	calc, err := compileExpression(cmd)
 	if err != nil {
 		return nil, err
 	}
-	return eval(node, calc, cmd)
+	return eval(context.Background(), node, calc, cmd)
 }
 
-func eval(node *Node, expression rpn, cmd string) (result *Node, err error) {
+// EvalLoose do the same thing as Eval, but with loose comparison of the numeric strings and the numbers, see JSONPathLoose
+func EvalLoose(node *Node, cmd string) (result *Node, err error) {
+	calc, err := compileExpression(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return eval(context.WithValue(context.Background(), looseKey{}, true), node, calc, cmd)
+}
+
+func eval(ctx context.Context, node *Node, expression rpn, cmd string) (result *Node, err error) {
 	var (
 		stack    = make([]*Node, 0)
 		slice    []*Node
@@ -587,45 +1112,113 @@ func eval(node *Node, expression rpn, cmd string) (result *Node, err error) {
 		op       Operation
 		ok       bool
 		size     int
-		commands []string
+		parsed   *parsedPath
 		bstr     []byte
+		args     map[*Node][]*Node // arguments of the functions, registered with RegisterFunction
+		sets     map[*Node][]*Node // values of the paths with several results, see isMultiple
 	)
 	for _, exp := range expression {
 		size = len(stack)
 		if fn, ok = functions[exp]; ok {
-			if size < 1 {
+			if size < 1 || args[stack[size-1]] != nil {
 				return nil, errorRequest("wrong request: %s", cmd)
 			}
 			stack[size-1], err = fn(stack[size-1])
 			if err != nil {
 				return
 			}
+		} else if variadic, ok := variadicFunctions[exp]; ok {
+			if size < 1 {
+				return nil, errorRequest("wrong request: %s", cmd)
+			}
+			list, ok := args[stack[size-1]]
+			if !ok {
+				list = []*Node{stack[size-1]}
+			}
+			stack[size-1], err = variadic(list)
+			if err != nil {
+				return
+			}
+		} else if exp == string(coma) { // collects the arguments of the function in the placeholder node
+			if size < 2 || args[stack[size-1]] != nil {
+				return nil, errorRequest("wrong request: %s", cmd)
+			}
+			if args == nil {
+				args = make(map[*Node][]*Node)
+			}
+			list, ok := args[stack[size-2]]
+			if !ok {
+				list = []*Node{stack[size-2]}
+			}
+			temp = NullNode("")
+			args[temp] = append(list, stack[size-1])
+			stack[size-2] = temp
+			stack = stack[:size-1]
 		} else if op, ok = operations[exp]; ok {
-			if size < 2 {
+			if size < 2 || args[stack[size-2]] != nil || args[stack[size-1]] != nil {
 				return nil, errorRequest("wrong request: %s", cmd)
 			}
-			stack[size-2], err = op(stack[size-2], stack[size-1])
+			loose := comparisons[exp] && isLoose(ctx)
+			lset, lok := sets[stack[size-2]]
+			rset, rok := sets[stack[size-1]]
+			if lok || rok { // existential: operation is applied to each of the values, the failed ones are skipped
+				if !lok {
+					lset = []*Node{stack[size-2]}
+				}
+				if !rok {
+					rset = []*Node{stack[size-1]}
+				}
+				slice = make([]*Node, 0, len(lset)*len(rset))
+				for _, left := range lset {
+					for _, right := range rset {
+						if temp, err = operate(op, left, right, loose); err == nil {
+							slice = append(slice, temp)
+						}
+					}
+				}
+				err = nil
+				temp = setNode(slice)
+				sets[temp] = slice
+				stack[size-2] = temp
+				stack = stack[:size-1]
+				continue
+			}
+			stack[size-2], err = operate(op, stack[size-2], stack[size-1], loose)
 			if err != nil {
 				return
 			}
 			stack = stack[:size-1]
 		} else if len(exp) > 0 {
 			if exp[0] == dollar || exp[0] == at {
-				commands, err = ParseJSONPath(exp)
+				base, path := filterReference(node, exp)
+				parsed, err = parsePath(path)
 				if err != nil {
 					return
 				}
-				slice, err = deReference(node, commands)
-				if err != nil {
-					return
+				slice = nil
+				if base != nil {
+					slice, err = deReference(ctx, base, parsed.commands)
+					if err != nil {
+						return
+					}
 				}
 				if len(slice) > 1 { // array given
+					for i, element := range slice {
+						slice[i] = element.detached()
+					}
 					stack = append(stack, ArrayNode("", slice))
 				} else if len(slice) == 1 {
 					stack = append(stack, slice[0])
-				} else { // no data found
-					// stack = append(stack, NullNode(""))
+				} else if !parsed.multiple { // no data found
 					return NullNode(""), nil
+				} else {
+					stack = append(stack, NullNode(""))
+				}
+				if parsed.multiple {
+					if sets == nil {
+						sets = make(map[*Node][]*Node)
+					}
+					sets[stack[len(stack)-1]] = slice
 				}
 			} else if constant, ok := constants[strings.ToLower(exp)]; ok {
 				stack = append(stack, constant)
@@ -650,7 +1243,7 @@ func eval(node *Node, expression rpn, cmd string) (result *Node, err error) {
 			stack = append(stack, valueNode(nil, "", String, ""))
 		}
 	}
-	if len(stack) == 1 {
+	if len(stack) == 1 && args[stack[0]] == nil {
 		return stack[0], nil
 	}
 	if len(stack) == 0 {
@@ -659,8 +1252,136 @@ func eval(node *Node, expression rpn, cmd string) (result *Node, err error) {
 	return nil, errorRequest("wrong request: %s", cmd)
 }
 
-func getNumberIndex(element *Node, input string, Default float64) (result float64, err error) {
-	var integer int
+// filterReference resolves the references `@root` and `@parent` of the expression path: `@root` is the root of the document and
+// `@parent` is the parent of the current node, nil for the root. Returns the node and the path relative to it.
+func filterReference(node *Node, path string) (*Node, string) {
+	for _, name := range []string{"@root", "@parent"} {
+		if !strings.HasPrefix(path, name) {
+			continue
+		}
+		rest := path[len(name):]
+		if rest != "" && rest[0] != dot && rest[0] != bracketL {
+			continue
+		}
+		if node != nil {
+			if name == "@root" {
+				node = node.root()
+			} else {
+				node = node.parent
+			}
+		}
+		return node, string(at) + rest
+	}
+	return node, path
+}
+
+// filterNodes returns children of the container element, for which the expression is true
+func filterNodes(ctx context.Context, element *Node, filter *filterExpression, cmd string) (result []*Node, err error) {
+	if !element.IsContainer() {
+		return nil, nil
+	}
+	for _, child := range element.InheritorsSorted() {
+		if ctx.Err() != nil {
+			return nil, errorTimeout()
+		}
+		ok, err := filter.match(ctx, child, cmd)
+		if isTimeout(err) {
+			return nil, err
+		} else if err != nil {
+			return nil, errorRequest("wrong request: %s", cmd)
+		}
+		if ok {
+			result = append(result, child)
+		}
+	}
+	return result, nil
+}
+
+// getSlice returns children of the array element by the slice keys: start, end and the optional step
+func getSlice(ctx context.Context, element *Node, keys []string, cmd string) (result []*Node, err error) {
+	if !element.IsArray() || element.Size() == 0 {
+		return nil, nil
+	}
+	var (
+		ikeys [3]int
+		fkeys [3]float64
+	)
+	if fkeys[0], err = getNumberIndex(ctx, element, keys[0], math.NaN()); isTimeout(err) {
+		return nil, err
+	} else if err != nil {
+		return nil, errorRequest("wrong request: %s", cmd)
+	}
+	if fkeys[1], err = getNumberIndex(ctx, element, keys[1], math.NaN()); isTimeout(err) {
+		return nil, err
+	} else if err != nil {
+		return nil, errorRequest("wrong request: %s", cmd)
+	}
+	if len(keys) < 3 {
+		fkeys[2] = 1
+	} else if fkeys[2], err = getNumberIndex(ctx, element, keys[2], 1); isTimeout(err) {
+		return nil, err
+	} else if err != nil {
+		return nil, errorRequest("wrong request: %s", cmd)
+	}
+
+	ikeys[2] = sliceIndex(fkeys[2], element.Size())
+	if ikeys[2] == 0 {
+		return nil, errorRequest("wrong request: %s", cmd)
+	}
+
+	if math.IsNaN(fkeys[0]) {
+		if ikeys[2] > 0 {
+			ikeys[0] = 0
+		} else {
+			ikeys[0] = element.Size() - 1
+		}
+	} else {
+		ikeys[0] = getPositiveIndex(sliceIndex(fkeys[0], element.Size()), element.Size())
+	}
+	if math.IsNaN(fkeys[1]) {
+		if ikeys[2] > 0 {
+			ikeys[1] = element.Size()
+		} else {
+			ikeys[1] = -1
+		}
+	} else {
+		ikeys[1] = getPositiveIndex(sliceIndex(fkeys[1], element.Size()), element.Size())
+	}
+
+	result = make([]*Node, 0)
+	if ikeys[2] > 0 {
+		if ikeys[0] < 0 {
+			ikeys[0] = 0
+		}
+		if ikeys[1] > element.Size() {
+			ikeys[1] = element.Size()
+		}
+
+		for i := ikeys[0]; i < ikeys[1]; i += ikeys[2] {
+			value, ok := element.children[strconv.Itoa(i)]
+			if ok {
+				result = append(result, value)
+			}
+		}
+	} else {
+		if ikeys[0] > element.Size() {
+			ikeys[0] = element.Size()
+		}
+		if ikeys[1] < -1 {
+			ikeys[1] = -1
+		}
+
+		for i := ikeys[0]; i > ikeys[1]; i += ikeys[2] {
+			value, ok := element.children[strconv.Itoa(i)]
+			if ok {
+				result = append(result, value)
+			}
+		}
+	}
+	return result, nil
+}
+
+func getNumberIndex(ctx context.Context, element *Node, input string, Default float64) (result float64, err error) {
 	if input == "" {
 		result = Default
 	} else if input == "(@.length)" {
@@ -668,29 +1389,56 @@ func getNumberIndex(element *Node, input string, Default float64) (result float6
 	} else if strings.HasPrefix(input, "(") && strings.HasSuffix(input, ")") {
 		var expr rpn
 		var temp *Node
-		expr, err = newBuffer([]byte(input[1 : len(input)-1])).rpn()
+		expr, err = compileExpression(input[1 : len(input)-1])
 		if err != nil {
 			return 0, err
 		}
-		temp, err = eval(element, expr, input)
+		temp, err = eval(ctx, element, expr, input)
 		if err != nil {
 			return
 		}
-		integer, err = temp.getInteger()
+		if !temp.IsNumeric() {
+			return 0, errorType()
+		}
+		result, err = temp.GetNumeric()
 		if err != nil {
 			return
 		}
-		result = float64(integer)
-	} else {
-		integer, err = strconv.Atoi(input)
-		if err != nil {
-			return 0, err
+		if math.Mod(result, 1.0) != 0 {
+			return 0, errorRequest("node is not INT")
 		}
-		result = float64(integer)
+	} else {
+		result, err = parseIndex(input)
 	}
 	return
 }
 
+// parseIndex returns the integer index of the slice or the union, the huge indexes are the infinities
+func parseIndex(input string) (float64, error) {
+	integer, err := strconv.Atoi(input)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange { // only the order of the huge index matters
+		if strings.HasPrefix(input, "-") {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return float64(integer), nil
+}
+
+// sliceIndex converts the index to int, indexes beyond the array are clamped to the nearest outside value, to avoid the int overflow
+func sliceIndex(index float64, size int) int {
+	if index > float64(size) {
+		return size + 1
+	}
+	if index < -float64(size) {
+		return -size - 1
+	}
+	return int(index)
+}
+
 func getPositiveIndex(index int, count int) int {
 	if index < 0 {
 		index += count
@@ -1,10 +1,12 @@
 package ajson
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // JSON from example https://goessner.net/articles/JsonPath/index.html#e3
@@ -175,6 +177,16 @@ func TestJsonPath(t *testing.T) {
 		{name: "slices 20", path: "$..[:(1/0):]", wantErr: true},
 		{name: "slices 21", path: "$..[:(1/2):]", wantErr: true},
 		{name: "slices 22", path: "$..[:0.5:]", wantErr: true},
+		{name: "slices 23", path: "$..[1:2:3:4]", wantErr: true},
+		{name: "slices 24", path: "$..[0:9223372036854775807]", expected: "[$['store']['book'][0], $['store']['book'][1], $['store']['book'][2], $['store']['book'][3]]"},
+		{name: "slices 25", path: "$..[-99999999999999999999:2]", expected: "[$['store']['book'][0], $['store']['book'][1]]"},
+		{name: "slices 26", path: "$..[99999999999999999999:]", expected: "[]"},
+		{name: "slices 27", path: "$..[99999999999999999999:-99999999999999999999:-1]", expected: "[$['store']['book'][3], $['store']['book'][2], $['store']['book'][1], $['store']['book'][0]]"},
+		{name: "slices 28", path: "$..[::99999999999999999999]", expected: "[$['store']['book'][0]]"},
+		{name: "slices 29", path: "$..[::-99999999999999999999]", expected: "[$['store']['book'][3]]"},
+		{name: "slices 30", path: "$..[(1e300):]", expected: "[]"},
+		{name: "slices 31", path: "$..[(-1e300):(1e300):(1e300)]", expected: "[$['store']['book'][0]]"},
+		{name: "slices 32", path: "$..[0,99999999999999999999:]", expected: "[$['store']['book'][0]]"},
 
 		{name: "calculated 1", path: "$['store']['book'][(@.length-1)]", expected: "[$['store']['book'][3]]"},
 		{name: "calculated 2", path: "$['store']['book'][(3.5 - 3/2)]", expected: "[$['store']['book'][2]]"},
@@ -210,43 +222,1483 @@ func TestJsonPath(t *testing.T) {
 	}
 }
 
+func TestCount(t *testing.T) {
+	paths := []string{
+		"$", "$..", "$.*", "$..*", "$..price", "$..['price','author']",
+		"$.store.book[*]", "$.store.book[1:3]", "$.store.book[::-1]", "$.store.book[-1:]",
+		"$.store.book[?(@.isbn)]", "$..book[?(@.price < 10)].title", "$.store.book[(@.length-1)]",
+		"$..book.length", "$.store.b*", "$.missing", "$..[?(@ == 0)]",
+		"$..book[*].author.^", "$..*.^", "$.store.book[*]~", "$.store.*.color", "$.store.book[0,0,-4]", "$..book..", "$.store..price",
+	}
+	root := Must(Unmarshal(jsonPathTestData))
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			nodes, err := JSONPath(jsonPathTestData, path)
+			if err != nil {
+				t.Errorf("JSONPath() error: %s", err)
+				return
+			}
+			if count, err := Count(jsonPathTestData, path); err != nil {
+				t.Errorf("Count() error: %s", err)
+			} else if count != len(nodes) {
+				t.Errorf("Count() wrong result: expected %d, got %d", len(nodes), count)
+			}
+			if count, err := root.Count(path); err != nil {
+				t.Errorf("Node.Count() error: %s", err)
+			} else if count != len(nodes) {
+				t.Errorf("Node.Count() wrong result: expected %d, got %d", len(nodes), count)
+			}
+		})
+	}
+
+	if _, err := Count(jsonPathTestData, "$.store.book[?(@.price"); err == nil {
+		t.Errorf("Count() expected error on wrong path")
+	}
+	if _, err := Count([]byte(`{"foo":`), "$"); err == nil {
+		t.Errorf("Count() expected error on wrong data")
+	}
+}
+
+func TestNode_CountMatches(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{
+		"groups": [
+			{"name": "a", "items": [{"id": 1, "tags": ["x", "y"]}, {"id": 2}, {"id": 3, "tags": []}]},
+			{"name": "b", "items": []},
+			{"name": "c", "items": [{"id": 4, "tags": ["z"]}]}
+		]
+	}`)))
+	tests := []struct {
+		name     string
+		node     *Node
+		path     string
+		expected int
+	}{
+		{name: "array elements", node: root.MustKey("groups").MustIndex(0), path: "@.items[*]", expected: 3},
+		{name: "without @", node: root.MustKey("groups").MustIndex(0), path: "items[*]", expected: 3},
+		{name: "brackets without @", node: root.MustKey("groups").MustIndex(0), path: "['items'][0].tags[*]", expected: 2},
+		{name: "empty array", node: root.MustKey("groups").MustIndex(1), path: "items[*]", expected: 0},
+		{name: "nested descent", node: root.MustKey("groups"), path: "..tags[*]", expected: 3},
+		{name: "filter", node: root.MustKey("groups").MustIndex(0), path: "@.items[?(@.tags != null)]", expected: 2},
+		{name: "slice", node: root.MustKey("groups").MustIndex(0).MustKey("items"), path: "@[1:]", expected: 2},
+		{name: "scalar", node: root.MustKey("groups").MustIndex(2).MustKey("name"), path: "@", expected: 1},
+		{name: "missing", node: root.MustKey("groups").MustIndex(2), path: "missing", expected: 0},
+		{name: "root", node: root.MustKey("groups").MustIndex(2), path: "$..id", expected: 4},
+		{name: "parents", node: root.MustKey("groups"), path: "..tags.^", expected: 3},
+		{name: "descent at the end", node: root.MustKey("groups").MustIndex(2), path: "items..", expected: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			count, err := test.node.CountMatches(test.path)
+			if err != nil {
+				t.Errorf("CountMatches() unexpected error: %s", err)
+			} else if count != test.expected {
+				t.Errorf("CountMatches() wrong result: expected %d, got %d", test.expected, count)
+			} else if nodes, _ := test.node.JSONPath(relativePath(test.path)); len(nodes) != count {
+				t.Errorf("CountMatches() doesn't match JSONPath: %d, %d", len(nodes), count)
+			}
+		})
+	}
+
+	if _, err := root.CountMatches("groups[?(@.name"); err == nil {
+		t.Errorf("CountMatches() expected error on wrong path")
+	}
+	nodes, err := root.MustKey("groups").FilterNodes("length(@.items) > 0")
+	if err != nil || len(nodes) != 2 {
+		t.Errorf("FilterNodes() wrong result: %d, %v", len(nodes), err)
+	}
+	for _, node := range nodes {
+		if count, err := node.CountMatches("items[*]"); err != nil || count == 0 {
+			t.Errorf("CountMatches() wrong result of the filtered node: %d, %v", count, err)
+		}
+	}
+}
+
+func TestNode_SumByPath(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{
+		"cart": {"items": [
+			{"name": "a", "price": 10.5, "qty": 2},
+			{"name": "b", "price": 4},
+			{"name": "c"},
+			{"name": "d", "price": 0.5, "qty": 1}
+		]},
+		"tags": {"x": {"price": 1}, "y": {"price": "free"}}
+	}`)))
+	items := root.MustKey("cart").MustKey("items")
+	tests := []struct {
+		name     string
+		fn       func(string) (float64, error)
+		path     string
+		expected float64
+	}{
+		{name: "sum", fn: items.SumByPath, path: "@.price", expected: 15},
+		{name: "sum without @", fn: items.SumByPath, path: "price", expected: 15},
+		{name: "sum of nothing", fn: items.SumByPath, path: "missing", expected: 0},
+		{name: "sum of several matches", fn: items.SumByPath, path: "@[price,qty]", expected: 18},
+		{name: "avg", fn: items.AvgByPath, path: "@.price", expected: 5},
+		{name: "min", fn: items.MinByPath, path: "@.price", expected: 0.5},
+		{name: "max", fn: items.MaxByPath, path: "@.price", expected: 10.5},
+		{name: "object children", fn: root.MustKey("tags").SumByPath, path: "@[?(@ == 1)]", expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := test.fn(test.path)
+			if err != nil {
+				t.Errorf("%s() unexpected error: %s", test.name, err)
+			} else if value != test.expected {
+				t.Errorf("%s() wrong result: expected %v, got %v", test.name, test.expected, value)
+			}
+		})
+	}
+
+	if _, err := items.SumByPath("@.name"); err == nil || err.Error() != "wrong request: non-numeric value by the path: $['cart']['items'][0]['name']" {
+		t.Errorf("SumByPath() wrong error on non-numeric value: %v", err)
+	}
+	if _, err := root.MustKey("tags").MaxByPath("price"); err == nil {
+		t.Errorf("MaxByPath() expected error on non-numeric value")
+	}
+	if _, err := items.AvgByPath("missing"); err == nil {
+		t.Errorf("AvgByPath() expected error on no values")
+	}
+	if _, err := items.MinByPath("@.price["); err == nil {
+		t.Errorf("MinByPath() expected error on wrong path")
+	}
+	if _, err := items.MustIndex(0).MustKey("price").SumByPath("@"); err == nil {
+		t.Errorf("SumByPath() expected error on scalar node")
+	}
+}
+
+func TestNode_Exists(t *testing.T) {
+	root := Must(Unmarshal(jsonPathTestData))
+	tests := []struct {
+		path     string
+		expected bool
+		err      bool
+	}{
+		{path: "$", expected: true},
+		{path: "$.store.book[0].title", expected: true},
+		{path: "$..book[?(@.price < 10)]", expected: true},
+		{path: "$..book[?(@.price > 100)]", expected: false},
+		{path: "$.missing", expected: false},
+		{path: "$.store.book[10]", expected: false},
+		{path: "$..*", expected: true},
+		{path: "$..book[*].author.^", expected: true},
+		{path: "$.store.book[?(@.price", expected: false, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if exists := root.Exists(test.path); exists != test.expected {
+				t.Errorf("Exists() wrong result: expected %v, got %v", test.expected, exists)
+			}
+			exists, err := root.ExistsE(test.path)
+			if (err != nil) != test.err {
+				t.Errorf("ExistsE() unexpected error: %v", err)
+			}
+			if exists != test.expected {
+				t.Errorf("ExistsE() wrong result: expected %v, got %v", test.expected, exists)
+			}
+		})
+	}
+
+	for _, path := range []string{"$..*", "$.store.book[*]", "$..book"} {
+		commands, err := ParseJSONPath(path)
+		if err != nil {
+			t.Errorf("ParseJSONPath(%s) unexpected error: %s", path, err)
+		} else if count, err := countReference(context.Background(), root, commands, 1); err != nil || count != 1 {
+			t.Errorf("countReference(%s) wrong result of the limit: %d, %v", path, count, err)
+		}
+	}
+}
+
+func TestJSONPathMulti(t *testing.T) {
+	paths := map[string]string{
+		"authors": "$.store.book[*].author",
+		"cheap":   "$..book[?(@.price < 10)].title",
+		"color":   "$.store.bicycle.color",
+		"missing": "$.store.missing",
+	}
+	result, err := JSONPathMulti(jsonPathTestData, paths)
+	if err != nil {
+		t.Errorf("JSONPathMulti() unexpected error: %s", err)
+		return
+	}
+	if len(result) != len(paths) {
+		t.Errorf("JSONPathMulti() wrong count of results: %d", len(result))
+	}
+	for label, path := range paths {
+		expected, _ := JSONPath(jsonPathTestData, path)
+		if actual, ok := result[label]; !ok {
+			t.Errorf("JSONPathMulti() result %s is missing", label)
+		} else if fullPath(actual) != fullPath(expected) {
+			t.Errorf("JSONPathMulti() result %s doesn't match\nExpected: %s\nActual:   %s", label, fullPath(expected), fullPath(actual))
+		}
+	}
+
+	paths["wrong"] = "$.store[?(@.price"
+	paths["broken"] = "$["
+	result, err = JSONPathMulti(jsonPathTestData, paths)
+	if err == nil {
+		t.Errorf("JSONPathMulti() expected error")
+	} else if message := err.Error(); !strings.Contains(message, "'broken': ") || !strings.Contains(message, "'wrong': ") || strings.Index(message, "'broken'") > strings.Index(message, "'wrong'") {
+		t.Errorf("JSONPathMulti() wrong error: %s", message)
+	}
+	if len(result) != 4 || len(result["authors"]) != 4 {
+		t.Errorf("JSONPathMulti() results of the correct paths are expected")
+	}
+
+	if _, err = JSONPathMulti([]byte(`{"foo":`), paths); err == nil {
+		t.Errorf("JSONPathMulti() expected error on wrong data")
+	}
+	if result, err := JSONPathMulti(jsonPathTestData, nil); err != nil || len(result) != 0 {
+		t.Errorf("JSONPathMulti() empty result expected, got: %v, %v", result, err)
+	}
+}
+
+func TestJSONPathTimeout(t *testing.T) {
+	result, err := JSONPathTimeout([]byte(jsonPathTestData), "$..book[?(@.price < 10)].title", time.Second)
+	if err != nil {
+		t.Errorf("JSONPathTimeout() unexpected error: %s", err)
+	} else if fullPath(result) != "[$['store']['book'][0]['title'], $['store']['book'][2]['title']]" {
+		t.Errorf("JSONPathTimeout() wrong result: %s", fullPath(result))
+	}
+
+	var data strings.Builder
+	data.WriteString("[")
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			data.WriteString(",")
+		}
+		fmt.Fprintf(&data, `{"a": %d, "b": [{"a": %d}, {"b": {"a": %d}}]}`, i, i+1, i+2)
+	}
+	data.WriteString("]")
+	slow := []string{
+		"$..[?(@..* == $..*)]",
+		"$..[?(@..* == $..*)].a",
+	}
+	for _, path := range slow {
+		start := time.Now()
+		_, err = JSONPathTimeout([]byte(data.String()), path, time.Millisecond)
+		if err == nil {
+			t.Errorf("JSONPathTimeout(%s) expected error", path)
+		} else if !isTimeout(err) || err.Error() != "timeout exceeded" || !err.(Error).Is(context.DeadlineExceeded) {
+			t.Errorf("JSONPathTimeout(%s) wrong error: %s", path, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("JSONPathTimeout(%s) was not aborted in time: %s", path, elapsed)
+		}
+	}
+
+	if _, err = JSONPathTimeout([]byte(`{}`), "$[", time.Second); err == nil || isTimeout(err) {
+		t.Errorf("JSONPathTimeout() expected error of the path: %v", err)
+	}
+	if _, err = JSONPathTimeout([]byte(`{`), "$", time.Second); err == nil || isTimeout(err) {
+		t.Errorf("JSONPathTimeout() expected error of the data: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	root := Must(Unmarshal([]byte(`[1, 2, 3]`)))
+	for _, keys := range [][]string{{"(@.length-1)", ""}, {"", "(@.length-1)"}, {"", "", "(@.length-1)"}} {
+		if _, err = getSlice(ctx, root, keys, "slice"); !isTimeout(err) {
+			t.Errorf("getSlice(%v) wrong error: %v", keys, err)
+		}
+	}
+}
+
+func TestJSONPathWithRoot(t *testing.T) {
+	tests := []struct {
+		root     string
+		path     string
+		expected string
+	}{
+		{root: "$.store.book", path: "$[*].author", expected: "$.store.book[*].author"},
+		{root: "$.store.book", path: "@[0].title", expected: "$.store.book[0].title"},
+		{root: "$.store", path: "book[?(@.price < 10)].title", expected: "$.store.book[?(@.price < 10)].title"},
+		{root: "$.store", path: "[*].price", expected: "$.store[*].price"},
+		{root: "$.store", path: "$..price", expected: "$.store..price"},
+		{root: "$.store.book[*]", path: "$.isbn", expected: "$.store.book[*].isbn"},
+		{root: "$.store.book[*]", path: "$", expected: "$.store.book[*]"},
+		{root: "$", path: "$.store.bicycle.color", expected: "$.store.bicycle.color"},
+		{root: "$.store.book", path: "$[?(@.price > $.expensive)].title", expected: "$.store.book[?(@.price > $.expensive)].title"},
+		{root: "$.store.book", path: "$[(@.length-1)]", expected: "$.store.book[(@.length-1)]"},
+		{root: "$.missing", path: "$[*]", expected: "$.missing[*]"},
+	}
+	for _, test := range tests {
+		t.Run(test.root+" "+test.path, func(t *testing.T) {
+			result, err := JSONPathWithRoot(jsonPathTestData, test.root, test.path)
+			if err != nil {
+				t.Errorf("JSONPathWithRoot() unexpected error: %s", err)
+				return
+			}
+			expected, err := JSONPath(jsonPathTestData, test.expected)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != fullPath(expected) {
+				t.Errorf("Error on JSONPathWithRoot(json, %s, %s): path doesn't match\nExpected: %s\nActual:   %s", test.root, test.path, fullPath(expected), fullPath(result))
+			}
+		})
+	}
+
+	if result, err := JSONPathWithRoot(jsonPathTestData, "$.store", "$.bicycle.price"); err != nil || len(result) != 1 || result[0].MustNumeric() != 19.95 {
+		t.Errorf("JSONPathWithRoot() wrong result: %v, %v", result, err)
+	}
+	if _, err := JSONPathWithRoot(jsonPathTestData, "$.store[", "$"); err == nil {
+		t.Errorf("JSONPathWithRoot() expected error on wrong root path")
+	}
+	if _, err := JSONPathWithRoot(jsonPathTestData, "$.store", "$["); err == nil {
+		t.Errorf("JSONPathWithRoot() expected error on wrong path")
+	}
+	if _, err := JSONPathWithRoot([]byte(`{`), "$", "$"); err == nil {
+		t.Errorf("JSONPathWithRoot() expected error on wrong data")
+	}
+}
+
+func TestJSONPathValue(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	value := map[string]interface{}{
+		"store": map[string]interface{}{
+			"book": []interface{}{
+				map[string]interface{}{"title": "foo", "price": 8.95, "tags": []interface{}{"a", "b"}},
+				map[string]interface{}{"title": "bar", "price": 12, "isbn": nil},
+				map[string]interface{}{"title": "baz", "price": int64(22), "available": true},
+			},
+			"bicycle": item{Name: "qux", Price: 19.95},
+			"counts":  []int{1, 2, 3},
+		},
+	}
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "keys", path: "$.store.book[*].title", expected: "[$['store']['book'][0]['title'], $['store']['book'][1]['title'], $['store']['book'][2]['title']]"},
+		{name: "filter", path: "$.store.book[?(@.price > 10)]", expected: "[$['store']['book'][1], $['store']['book'][2]]"},
+		{name: "null", path: "$..isbn", expected: "[$['store']['book'][1]['isbn']]"},
+		{name: "bool", path: "$..book[?(@.available)]", expected: "[$['store']['book'][2]]"},
+		{name: "struct", path: "$.store.bicycle[?(@ == 'qux')]", expected: "[$['store']['bicycle']['name']]"},
+		{name: "typed slice", path: "$.store.counts[-1:]", expected: "[$['store']['counts'][2]]"},
+		{name: "nested slice", path: "$..tags[1]", expected: "[$['store']['book'][0]['tags'][1]]"},
+		{name: "root", path: "$", expected: "[$]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPathValue(value, test.path)
+			if err != nil {
+				t.Errorf("JSONPathValue() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JSONPathValue(value, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+
+	result, err := JSONPathValue(value, "$.store.book[*].price")
+	if err != nil {
+		t.Errorf("JSONPathValue() unexpected error: %s", err)
+	} else if prices := sumNumeric(result); prices != 8.95+12+22 {
+		t.Errorf("JSONPathValue() wrong values: %v", prices)
+	}
+	if result, err := JSONPathValue("foo", "$"); err != nil || len(result) != 1 || result[0].MustString() != "foo" {
+		t.Errorf("JSONPathValue() wrong result for scalar: %v, %v", result, err)
+	}
+	if _, err = JSONPathValue(value, "$.store["); err == nil {
+		t.Errorf("JSONPathValue() expected error on wrong path")
+	}
+	if _, err = JSONPathValue(map[string]interface{}{"fn": func() {}}, "$"); err == nil {
+		t.Errorf("JSONPathValue() expected error on wrong value")
+	}
+}
+
+func sumNumeric(nodes []*Node) (result float64) {
+	for _, node := range nodes {
+		result += node.MustNumeric()
+	}
+	return
+}
+
+func TestForEachMatch(t *testing.T) {
+	titles := make([]string, 0)
+	err := ForEachMatch(jsonPathTestData, "$..book[?(@.price < 20)].title", func(node *Node) error {
+		titles = append(titles, node.MustString())
+		return nil
+	})
+	if err != nil {
+		t.Errorf("ForEachMatch() unexpected error: %s", err)
+	}
+	if expected := []string{"Sayings of the Century", "Sword of Honour", "Moby Dick"}; !sliceEqual(titles, expected) {
+		t.Errorf("ForEachMatch() wrong result\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(titles))
+	}
+
+	stop := fmt.Errorf("stop")
+	visited := make([]string, 0)
+	err = ForEachMatch(jsonPathTestData, "$.store.book[*].author", func(node *Node) error {
+		visited = append(visited, node.Path())
+		if len(visited) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("ForEachMatch() wrong error: %v", err)
+	}
+	if expected := []string{"$['store']['book'][0]['author']", "$['store']['book'][1]['author']"}; !sliceEqual(visited, expected) {
+		t.Errorf("ForEachMatch() wrong visited nodes\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(visited))
+	}
+
+	called := false
+	fn := func(node *Node) error {
+		called = true
+		return nil
+	}
+	if err = ForEachMatch(jsonPathTestData, "$.store.missing", fn); err != nil || called {
+		t.Errorf("ForEachMatch() wrong result for no matches: %v, %v", err, called)
+	}
+	if err = ForEachMatch(jsonPathTestData, "$.store[", fn); err == nil || called {
+		t.Errorf("ForEachMatch() expected error on wrong path")
+	}
+	if err = ForEachMatch([]byte(`{"store":`), "$.store", fn); err == nil || called {
+		t.Errorf("ForEachMatch() expected error on wrong data")
+	}
+}
+
+func TestApplyEach(t *testing.T) {
+	document := []byte(`{"items": [
+		{"name": "foo", "tags": ["a", "b"]},
+		{"name": "bar", "tags": []},
+		{"name": "baz", "tags": ["c"], "children": [{"tags": ["d"]}]},
+		{"name": "qux"}
+	]}`)
+	items, err := JSONPath(document, "$.items[*]")
+	if err != nil {
+		t.Errorf("JSONPath() unexpected error: %s", err)
+		return
+	}
+	tests := []struct {
+		name     string
+		nodes    []*Node
+		path     string
+		expected string
+	}{
+		{name: "tags", nodes: items, path: "@.tags[*]", expected: "[$['items'][0]['tags'][0], $['items'][0]['tags'][1], $['items'][2]['tags'][0]]"},
+		{name: "descent", nodes: items, path: "@..tags[*]", expected: "[$['items'][0]['tags'][0], $['items'][0]['tags'][1], $['items'][2]['tags'][0], $['items'][2]['children'][0]['tags'][0]]"},
+		{name: "filter", nodes: items, path: "@.tags[?(@ != 'a')]", expected: "[$['items'][0]['tags'][1], $['items'][2]['tags'][0]]"},
+		{name: "current", nodes: items[1:3], path: "@", expected: "[$['items'][1], $['items'][2]]"},
+		{name: "duplicates", nodes: append(items[:1:1], items[0], items[0].MustKey("tags")), path: "@..*", expected: "[$['items'][0]['name'], $['items'][0]['tags'], $['items'][0]['tags'][0], $['items'][0]['tags'][1]]"},
+		{name: "root", nodes: items, path: "$.items[0].name", expected: "[$['items'][0]['name']]"},
+		{name: "empty", nodes: nil, path: "@.tags[*]", expected: "[]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ApplyEach(test.nodes, test.path)
+			if err != nil {
+				t.Errorf("ApplyEach() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on ApplyEach(nodes, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+
+	if _, err = ApplyEach(items, "@.tags["); err == nil {
+		t.Errorf("ApplyEach() expected error on wrong path")
+	}
+	if _, err = ApplyEach(items, "@.tags[?(@ + )]"); err == nil {
+		t.Errorf("ApplyEach() expected error on wrong expression")
+	}
+}
+
+func TestSortByPath(t *testing.T) {
+	document := []byte(`[
+		{"name": "foo", "price": 10, "meta": {"rank": "b"}},
+		{"name": "bar", "price": 2.5, "meta": {"rank": "c"}},
+		{"name": "baz", "meta": {"rank": "a"}},
+		{"name": "qux", "price": 100, "meta": {}},
+		{"name": "quux", "price": null},
+		{"name": "corge", "price": 10}
+	]`)
+	tests := []struct {
+		name      string
+		path      string
+		ascending bool
+		expected  []string
+	}{
+		{name: "numeric ascending", path: "@.price", ascending: true, expected: []string{"bar", "foo", "corge", "qux", "baz", "quux"}},
+		{name: "numeric descending", path: "@.price", ascending: false, expected: []string{"qux", "foo", "corge", "bar", "baz", "quux"}},
+		{name: "string ascending", path: "@.name", ascending: true, expected: []string{"bar", "baz", "corge", "foo", "quux", "qux"}},
+		{name: "string descending", path: "@.name", ascending: false, expected: []string{"qux", "quux", "foo", "corge", "baz", "bar"}},
+		{name: "nested", path: "@.meta.rank", ascending: true, expected: []string{"baz", "foo", "bar", "qux", "quux", "corge"}},
+		{name: "several values", path: "@.*", ascending: true, expected: []string{"foo", "bar", "baz", "qux", "quux", "corge"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes, err := JSONPath(document, "$[*]")
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %s", err)
+				return
+			}
+			if err = SortByPath(nodes, test.path, test.ascending); err != nil {
+				t.Errorf("SortByPath() unexpected error: %s", err)
+				return
+			}
+			names := make([]string, len(nodes))
+			for i, node := range nodes {
+				names[i] = node.MustKey("name").MustString()
+			}
+			if !sliceEqual(names, test.expected) {
+				t.Errorf("SortByPath() wrong order\nExpected: %s\nActual:   %s", sliceString(test.expected), sliceString(names))
+			}
+		})
+	}
+
+	nodes := Must(Unmarshal([]byte(`[true, "b", 3, "a", false, 1, [0], null]`))).MustArray()
+	if err := SortByPath(nodes, "@", true); err != nil {
+		t.Errorf("SortByPath() unexpected error: %s", err)
+	}
+	if actual := fullPath(nodes); actual != "[$[5], $[2], $[3], $[1], $[4], $[0], $[6], $[7]]" {
+		t.Errorf("SortByPath() wrong order of mixed types: %s", actual)
+	}
+	if err := SortByPath(nodes, "@.price[", true); err == nil {
+		t.Errorf("SortByPath() expected error on wrong path")
+	}
+	if err := SortByPath(nil, "@.price", true); err != nil {
+		t.Errorf("SortByPath() unexpected error on empty slice: %s", err)
+	}
+}
+
+func TestJSONPathVars(t *testing.T) {
+	document := []byte(`{"users": [{"name": "foo", "role": "admin"}, {"name": "bar", "role": "it's"}], "a.b": {"x": 1}, "a']['x": 2}`)
+	vars := map[string]string{
+		"idx":       "1",
+		"last":      "-1",
+		"key":       "users",
+		"dotted":    "a.b",
+		"role":      "it's",
+		"inject":    "admin' || @.role != '",
+		"injectKey": "a']['x",
+		"wildcard":  "*",
+	}
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "index", path: "$.users[${idx}].name", expected: "[$['users'][1]['name']]"},
+		{name: "negative index", path: "$.users[${last}]", expected: "[$['users'][1]]"},
+		{name: "key", path: "$[${key}][0]", expected: "[$['users'][0]]"},
+		{name: "key and index", path: "$[${key}][${idx}]", expected: "[$['users'][1]]"},
+		{name: "dotted key", path: "$[${dotted}].x", expected: "[$['a.b']['x']]"},
+		{name: "filter", path: "$.users[?(@.role == ${role})]", expected: "[$['users'][1]]"},
+		{name: "filter injection", path: "$.users[?(@.role == ${inject})]", expected: "[]"},
+		{name: "key injection", path: "$[${injectKey}]", expected: `[$['a\'][\'x']]`},
+		{name: "wildcard", path: "$.users[${wildcard}]", expected: "[]"},
+		{name: "no placeholders", path: "$.users[0]", expected: "[$['users'][0]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPathVars(document, test.path, vars)
+			if err != nil {
+				t.Errorf("JSONPathVars() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JSONPathVars(json, %s): path doesn't match\nExpected: %s\nActual:   %s", test.path, test.expected, fullPath(result))
+			}
+		})
+	}
+
+	for _, path := range []string{"$.users[${unknown}]", "$.users[${idx]", "$.users[${idx}"} {
+		if _, err := JSONPathVars(document, path, vars); err == nil {
+			t.Errorf("JSONPathVars(%s) expected error", path)
+		}
+	}
+}
+
 func TestJsonPath_value(t *testing.T) {
 	tests := []struct {
 		name     string
 		path     string
-		expected interface{}
+		expected interface{}
+	}{
+		{name: "length", path: "$['store']['book'].length", expected: float64(4)},
+		{name: "object length", path: "$['store']['bicycle'].length", expected: float64(2)},
+		{name: "string length", path: "$['store']['bicycle']['color'].length", expected: float64(3)},
+		{name: "price", path: "$['store']['book'][?(@.price + 0.05 == 9)].price", expected: float64(8.95)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(jsonPathTestData, test.path)
+			if err != nil {
+				t.Errorf("Error on JsonPath(json, %s) as %s: %s", test.path, test.name, err.Error())
+			} else if len(result) != 1 {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path to long, expected only value\nActual: %s", test.path, test.name, fullPath(result))
+			} else {
+				val, err := result[0].Value()
+				if err != nil {
+					t.Errorf("Error on JsonPath(json, %s): error %s", test.path, err.Error())
+				} else {
+					switch {
+					case result[0].IsNumeric():
+						if val.(float64) != test.expected.(float64) {
+							t.Errorf("Error on JsonPath(json, %s): value doesn't match\nExpected: %v\nActual:   %v", test.path, test.expected, val)
+						}
+					case result[0].IsString():
+						if val.(string) != test.expected.(string) {
+							t.Errorf("Error on JsonPath(json, %s): value doesn't match\nExpected: %v\nActual:   %v", test.path, test.expected, val)
+						}
+					case result[0].IsBool():
+						if val.(bool) != test.expected.(bool) {
+							t.Errorf("Error on JsonPath(json, %s): value doesn't match\nExpected: %v\nActual:   %v", test.path, test.expected, val)
+						}
+					default:
+						t.Errorf("Error on JsonPath(json, %s): unsupported type found", test.path)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestJSONPath_string_comparison(t *testing.T) {
+	document := []byte(`[{"name":"Alice"},{"name":"Mike"},{"name":"Zed"},{"name":5},{"name":"école"},{"name":"über"},{"name":"M"}]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "greater", path: `$[?(@.name > 'M')]`, expected: "[$[1], $[2], $[4], $[5]]"},
+		{name: "greater or equals", path: `$[?(@.name >= 'M')]`, expected: "[$[1], $[2], $[4], $[5], $[6]]"},
+		{name: "less", path: `$[?(@.name < "Mike")]`, expected: "[$[0], $[6]]"},
+		{name: "less or equals", path: `$[?(@.name <= "Mike")]`, expected: "[$[0], $[1], $[6]]"},
+		{name: "unicode", path: `$[?(@.name >= 'é')]`, expected: "[$[4], $[5]]"},
+		{name: "unicode order", path: `$[?(@.name < 'ü')]`, expected: "[$[0], $[1], $[2], $[4], $[6]]"},
+		{name: "mixed string", path: `$[?(@.name > 1)]`, expected: "[$[3]]"},
+		{name: "mixed numeric", path: `$[?(@.name < 'Z')]`, expected: "[$[0], $[1], $[6]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_string_escaping(t *testing.T) {
+	document := []byte(`[{"name":"O'Brien"},{"name":"back\\slash"},{"name":"new\nline"},{"name":"say \"hi\""},{"name":"OBrien"}]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "apostrophe", path: `$[?(@.name == 'O\'Brien')]`, expected: "[$[0]]"},
+		{name: "apostrophe in double quotes", path: `$[?(@.name == "O'Brien")]`, expected: "[$[0]]"},
+		{name: "backslash", path: `$[?(@.name == 'back\\slash')]`, expected: "[$[1]]"},
+		{name: "new line", path: `$[?(@.name == 'new\nline')]`, expected: "[$[2]]"},
+		{name: "quotes", path: `$[?(@.name == "say \"hi\"")]`, expected: "[$[3]]"},
+		{name: "quotes in single quotes", path: `$[?(@.name == 'say "hi"')]`, expected: "[$[3]]"},
+		{name: "not equals", path: `$[?(@.name != 'O\'Brien')]`, expected: "[$[1], $[2], $[3], $[4]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_filter_nested_path(t *testing.T) {
+	document := []byte(`[
+		{"address": {"city": "NYC", "geo": {"lat": 1}}},
+		{"address": {"city": "LA"}},
+		{"address": "NYC"},
+		{"name": "NYC"},
+		{"address": null},
+		{"address": {"geo": {"lat": 2}}}
+	]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "equals", path: `$[?(@.address.city == 'NYC')]`, expected: "[$[0]]"},
+		{name: "equals brackets", path: `$[?(@['address']['city'] == 'NYC')]`, expected: "[$[0]]"},
+		{name: "exists", path: `$[?(@.address.city)]`, expected: "[$[0], $[1]]"},
+		{name: "not exists", path: `$[?(!@.address.city)]`, expected: "[$[2], $[3], $[4], $[5]]"},
+		{name: "deep compare", path: `$[?(@.address.geo.lat > 1)]`, expected: "[$[5]]"},
+		{name: "deep exists", path: `$[?(@.address.geo.lat)]`, expected: "[$[0], $[5]]"},
+		{name: "deep math", path: `$[?(@.address.geo.lat * 2 == 2)]`, expected: "[$[0]]"},
+		{name: "not equals", path: `$[?(@.address.city != 'NYC')]`, expected: "[$[1]]"},
+		{name: "combined", path: `$[?(@.address.city && @.address.geo.lat)]`, expected: "[$[0]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_boolean(t *testing.T) {
+	document := []byte(`[{"enabled":true},{"enabled":false},{"enabled":"true"},{"enabled":1},{"enabled":0},{"enabled":null},{}]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "equals true", path: `$[?(@.enabled == true)]`, expected: "[$[0]]"},
+		{name: "equals false", path: `$[?(@.enabled == false)]`, expected: "[$[1]]"},
+		{name: "true equals", path: `$[?(true == @.enabled)]`, expected: "[$[0]]"},
+		{name: "upper case", path: `$[?(@.enabled == TRUE)]`, expected: "[$[0]]"},
+		{name: "not equals true", path: `$[?(@.enabled != true)]`, expected: "[$[1], $[2], $[3], $[4], $[5]]"},
+		{name: "equals expression", path: `$[?(@.enabled == (1 > 0))]`, expected: "[$[0]]"},
+		{name: "truthiness", path: `$[?(@.enabled)]`, expected: "[$[0], $[2], $[3]]"},
+		{name: "falsiness", path: `$[?(!@.enabled)]`, expected: "[$[1], $[4], $[5], $[6]]"},
+		{name: "and true", path: `$[?(@.enabled && true)]`, expected: "[$[0], $[2], $[3]]"},
+		{name: "or false", path: `$[?(@.enabled == false || false)]`, expected: "[$[1]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_negation(t *testing.T) {
+	document := []byte(`[{"a":1,"b":2},{"a":1,"b":3},{"a":2,"b":2},{"a":2,"b":3},{"c":true}]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "not exists", path: `$[?(!@.a)]`, expected: "[$[4]]"},
+		{name: "not equals", path: `$[?(!(@.a == 1))]`, expected: "[$[2], $[3], $[4]]"},
+		{name: "not or", path: `$[?(!(@.a == 1 || @.b == 2))]`, expected: "[$[3], $[4]]"},
+		{name: "de morgan or", path: `$[?(!(@.a == 1) && !(@.b == 2))]`, expected: "[$[3], $[4]]"},
+		{name: "not and", path: `$[?(!(@.a == 1 && @.b == 2))]`, expected: "[$[1], $[2], $[3], $[4]]"},
+		{name: "de morgan and", path: `$[?(!(@.a == 1) || !(@.b == 2))]`, expected: "[$[1], $[2], $[3], $[4]]"},
+		{name: "double negation", path: `$[?(!!(@.a == 2))]`, expected: "[$[2], $[3]]"},
+		{name: "nested", path: `$[?(!(@.a == 1 && !(@.b == 3)))]`, expected: "[$[1], $[2], $[3], $[4]]"},
+		{name: "grouping", path: `$[?((@.a == 1 || @.a == 2) && @.b == 3)]`, expected: "[$[1], $[3]]"},
+		{name: "precedence", path: `$[?(@.a == 1 || @.a == 2 && @.b == 3)]`, expected: "[$[0], $[1], $[3]]"},
+		{name: "not in the right", path: `$[?(@.c || !(@.b != 2))]`, expected: "[$[0], $[2], $[4]]"},
+		{name: "calculation over missing", path: `$[?(!(@.a * 2 == 2))]`, expected: "[$[2], $[3], $[4]]"},
+		{name: "not equals missing", path: `$[?(@.a != 1)]`, expected: "[$[2], $[3]]"},
+		{name: "or over missing", path: `$[?(@.c == true || @.a == 2)]`, expected: "[$[2], $[3], $[4]]"},
+		{name: "negation of the operand", path: `$[?(!@.a == false)]`, expected: "[$[0], $[1], $[2], $[3]]"},
+		{name: "grouping inside", path: `$[?(@.b == 3 && (@.a == 1 || !(@.a != 2)))]`, expected: "[$[1], $[3]]"},
+		{name: "operators in strings", path: `$[?(@.s == 'x || y' || @.c)]`, expected: "[$[4]]"},
+		{name: "script group", path: `$[?((@.a + 1) * 2 == 4)]`, expected: "[$[0], $[1]]"},
+		{name: "nested filter", path: `$[?(@[?(@ == 3 || @ == true)])]`, expected: "[$[1], $[3], $[4]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_numeric_keys(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		path     string
+		expected string
+	}{
+		{name: "object quoted", document: `{"0":"foo","1":"bar"}`, path: `$['0']`, expected: "[$['0']]"},
+		{name: "object double quoted", document: `{"0":"foo","1":"bar"}`, path: `$["1"]`, expected: "[$['1']]"},
+		{name: "object dot", document: `{"0":"foo","1":"bar"}`, path: `$.1`, expected: "[$['1']]"},
+		{name: "object index", document: `{"0":"foo","1":"bar"}`, path: `$[0]`, expected: "[$['0']]"},
+		{name: "object negative index", document: `{"-1":"foo"}`, path: `$[-1]`, expected: "[$['-1']]"},
+		{name: "array index", document: `["foo","bar"]`, path: `$[1]`, expected: "[$[1]]"},
+		{name: "array negative index", document: `["foo","bar"]`, path: `$[-1]`, expected: "[$[1]]"},
+		{name: "array quoted", document: `["foo","bar"]`, path: `$['0']`, expected: "[]"},
+		{name: "array double quoted", document: `["foo","bar"]`, path: `$["1"]`, expected: "[]"},
+		{name: "array dot", document: `["foo","bar"]`, path: `$.1`, expected: "[$[1]]"},
+		{name: "array dot nested", document: `{"arr":[10,20]}`, path: `$.arr.0`, expected: "[$['arr'][0]]"},
+		{name: "array dot negative", document: `{"arr":[10,20]}`, path: `$.arr.-1`, expected: "[$['arr'][1]]"},
+		{name: "mixed dot", document: `[{"0":"foo"},["bar"]]`, path: `$.*.0`, expected: "[$[0]['0'], $[1][0]]"},
+		{name: "mixed index", document: `[{"0":"foo"},["bar"]]`, path: `$.*[0]`, expected: "[$[0]['0'], $[1][0]]"},
+		{name: "mixed quoted", document: `[{"0":"foo"},["bar"]]`, path: `$.*['0']`, expected: "[$[0]['0']]"},
+		{name: "mixed union", document: `{"a":{"0":"foo","b":1},"c":["bar"]}`, path: `$.*['0',0,'b']`, expected: "[$['a']['0'], $['c'][0], $['a']['b']]"},
+		{name: "negative index on different sizes", document: `[[1,2,3],[4,5],[]]`, path: `$.*[-1]`, expected: "[$[0][2], $[1][1]]"},
+		{name: "mixed union of keys and index", document: `{"x":[{"a":1,"b":2},["first","second"],{"b":3},"str",null]}`, path: `$.x[*]['a',0,'b']`, expected: "[$['x'][0]['a'], $['x'][1][0], $['x'][0]['b'], $['x'][2]['b']]"},
+		{name: "mixed union of names and negative index", document: `{"x":[{"a":1,"-1":2},["first","second"]]}`, path: `$.x[*][a,-1]`, expected: "[$['x'][0]['a'], $['x'][0]['-1'], $['x'][1][1]]"},
+		{name: "mixed union with script", document: `{"x":[{"a":1,"1":2},["first","second"]]}`, path: `$.x[*]["a",(@.length-1)]`, expected: "[$['x'][0]['a'], $['x'][1][1]]"},
+		{name: "mixed union on the object", document: `{"a":1,"0":2,"b":3}`, path: `$['a',0,'b']`, expected: "[$['a'], $['0'], $['b']]"},
+		{name: "mixed union on the array", document: `["foo","bar"]`, path: `$['a',0,'b']`, expected: "[$[0]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath([]byte(test.document), test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_keys(t *testing.T) {
+	document := []byte(`{"object": {"b": 1, "a": {"x": true}, "c": [1, 2, 3]}, "list": ["x", {"y": 1}, null], "key~": 1}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected []interface{}
+	}{
+		{name: "object", path: "$.object.*~", expected: []interface{}{"a", "b", "c"}},
+		{name: "object brackets", path: "$['object'][*]~", expected: []interface{}{"a", "b", "c"}},
+		{name: "member", path: "$.object.a~", expected: []interface{}{"a"}},
+		{name: "array", path: "$.list[*]~", expected: []interface{}{float64(0), float64(1), float64(2)}},
+		{name: "slice", path: "$.object.c[1:]~", expected: []interface{}{float64(1), float64(2)}},
+		{name: "descent", path: "$..x~", expected: []interface{}{"x"}},
+		{name: "filter", path: "$.object[?(@ == 1)]~", expected: []interface{}{"b"}},
+		{name: "union", path: "$.list[0,2]~", expected: []interface{}{float64(0), float64(2)}},
+		{name: "root", path: "$~", expected: []interface{}{}},
+		{name: "length pseudo-property", path: "$.object.c.length~", expected: []interface{}{}},
+		{name: "missing", path: "$.object.missing~", expected: []interface{}{}},
+		{name: "quoted key", path: "$['key~']", expected: []interface{}{float64(1)}},
+		{name: "after keys", path: "$.object.*~.length", expected: []interface{}{float64(1), float64(1), float64(1)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %s", err)
+				return
+			}
+			actual := make([]interface{}, 0, len(result))
+			for _, node := range result {
+				value, err := node.Unpack()
+				if err != nil {
+					t.Errorf("Unpack() unexpected error: %s", err)
+				}
+				actual = append(actual, value)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Error on JsonPath(json, %s) as %s: wrong keys\nExpected: %v\nActual:   %v", test.path, test.name, test.expected, actual)
+			}
+		})
+	}
+
+	result, err := JSONPath(document, `$.object[?(@.*~ == 'x')]~`)
+	if err != nil || len(result) != 1 || result[0].MustString() != "a" {
+		t.Errorf("JSONPath() wrong result of the keys in the filter: %v, %v", result, err)
+	}
+	if err = ValidatePath("$.object.*~"); err != nil {
+		t.Errorf("ValidatePath() unexpected error: %s", err)
+	}
+}
+
+func TestJSONPath_parent(t *testing.T) {
+	document := []byte(`{"a": {"b": {"c": 1}, "d": 2, "list": [{"id": 1, "name": "x"}, {"id": 2, "name": "y"}]}, "^": 3}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "sibling", path: "$.a.b.^.d", expected: "[$['a']['d']]"},
+		{name: "deep sibling", path: "$.a.b.c.^.^.d", expected: "[$['a']['d']]"},
+		{name: "parent", path: "$.a.b.c.^", expected: "[$['a']['b']]"},
+		{name: "brackets", path: "$['a']['list'][0]^.^.d", expected: "[$['a']['d']]"},
+		{name: "unique parents", path: "$.a.list[*].id.^.name", expected: "[$['a']['list'][0]['name'], $['a']['list'][1]['name']]"},
+		{name: "array parent", path: "$.a.list[*].^", expected: "[$['a']['list']]"},
+		{name: "filter", path: "$..[?(@.id == 2)].^.^.d", expected: "[$['a']['d']]"},
+		{name: "in filter", path: "$.a.list[?(@.^.^.d == 2)].name", expected: "[$['a']['list'][0]['name'], $['a']['list'][1]['name']]"},
+		{name: "root", path: "$.^", expected: "[$]"},
+		{name: "above root", path: "$.a.^.^.a.d", expected: "[$['a']['d']]"},
+		{name: "missing", path: "$.a.missing.^", expected: "[]"},
+		{name: "quoted key", path: "$['^']", expected: "[$['^']]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+
+	result, err := JSONPath(document, `$.a.list[?(@.id ^ 3 == 1)].name`)
+	if err != nil || fullPath(result) != "[$['a']['list'][1]['name']]" {
+		t.Errorf("JSONPath() wrong result of the xor operator: %s, %v", fullPath(result), err)
+	}
+	if err = ValidatePath("$.a.b.^.d"); err != nil {
+		t.Errorf("ValidatePath() unexpected error: %s", err)
+	}
+}
+
+func TestJSONPath_length(t *testing.T) {
+	document := []byte(`[
+		{"tags":[1,2,3]},
+		{"tags":[1]},
+		{"tags":{"a":1,"b":2,"c":3}},
+		{"tags":{"a":1,"length":1}},
+		{"tags":"foo"},
+		{"tags":"foobar"},
+		{"tags":5}
+	]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "greater", path: `$[?(@.tags.length > 3)]`, expected: "[$[5]]"},
+		{name: "equals", path: `$[?(@.tags.length == 1)]`, expected: "[$[1], $[3]]"},
+		{name: "string", path: `$[?(@.tags.length == 3)]`, expected: "[$[0], $[2], $[4]]"},
+		{name: "field", path: `$[3].tags.length`, expected: "[$[3]['tags']['length']]"},
+		{name: "quoted field", path: `$[2].tags['length']`, expected: "[]"},
+		{name: "numeric", path: `$[6].tags.length`, expected: "[]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_recursive_descent_scope(t *testing.T) {
+	document := []byte(`{
+		"price": 1,
+		"store": {"price": 2, "book": [{"price": 3}, {"title": "foo"}], "bicycle": {"price": 4}},
+		"warehouse": {"price": 5, "store": {"price": 6}}
+	}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
 	}{
-		{name: "length", path: "$['store']['book'].length", expected: float64(4)},
-		{name: "price", path: "$['store']['book'][?(@.price + 0.05 == 9)].price", expected: float64(8.95)},
+		{name: "from key", path: `$.store..price`, expected: "[$['store']['price'], $['store']['bicycle']['price'], $['store']['book'][0]['price']]"},
+		{name: "from bracket key", path: `$['store']..['price']`, expected: "[$['store']['price'], $['store']['bicycle']['price'], $['store']['book'][0]['price']]"},
+		{name: "from nested key", path: `$.store.book..price`, expected: "[$['store']['book'][0]['price']]"},
+		{name: "chained", path: `$..store..price`, expected: "[$['store']['price'], $['warehouse']['store']['price'], $['store']['bicycle']['price'], $['store']['book'][0]['price']]"},
+		{name: "from scalar", path: `$.price..price`, expected: "[]"},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_rootless(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "store.book[0]", expected: "$.store.book[0]"},
+		{path: "store.book[*].author", expected: "$.store.book[*].author"},
+		{path: "['store']['bicycle']", expected: "$['store']['bicycle']"},
+		{path: "['store']['book']", expected: "$['store']['book']"},
+		{path: `["store"]['book'][0]['title']`, expected: `$["store"]['book'][0]['title']`},
+		{path: "['store'].book[?(@.price < 10)]", expected: "$['store'].book[?(@.price < 10)]"},
+		{path: "..price", expected: "$..price"},
+		{path: "*", expected: "$.*"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			commands, err := ParseJSONPath(test.path)
+			if err != nil {
+				t.Errorf("ParseJSONPath() unexpected error: %s", err)
+				return
+			}
+			expected, _ := ParseJSONPath(test.expected)
+			if !sliceEqual(commands, expected) {
+				t.Errorf("ParseJSONPath() commands doesn't match\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(commands))
+			}
 			result, err := JSONPath(jsonPathTestData, test.path)
 			if err != nil {
-				t.Errorf("Error on JsonPath(json, %s) as %s: %s", test.path, test.name, err.Error())
-			} else if len(result) != 1 {
-				t.Errorf("Error on JsonPath(json, %s) as %s: path to long, expected only value\nActual: %s", test.path, test.name, fullPath(result))
-			} else {
-				val, err := result[0].Value()
+				t.Errorf("JSONPath() unexpected error: %s", err)
+				return
+			}
+			nodes, _ := JSONPath(jsonPathTestData, test.expected)
+			if actual, expected := Paths(result), Paths(nodes); len(actual) == 0 || !sliceEqual(actual, expected) {
+				t.Errorf("JSONPath() path doesn't match\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(actual))
+			}
+		})
+	}
+}
+
+func TestJSONPath_brackets(t *testing.T) {
+	data := []byte(`[0, 1, 2, 3, 4]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{name: "index", path: "$[2]", expected: []string{"$[2]"}},
+		{name: "negative index", path: "$[-2]", expected: []string{"$[3]"}},
+		{name: "from", path: "$[2:]", expected: []string{"$[2]", "$[3]", "$[4]"}},
+		{name: "to", path: "$[:2]", expected: []string{"$[0]", "$[1]"}},
+		{name: "whole", path: "$[:]", expected: []string{"$[0]", "$[1]", "$[2]", "$[3]", "$[4]"}},
+		{name: "whole with step", path: "$[::]", expected: []string{"$[0]", "$[1]", "$[2]", "$[3]", "$[4]"}},
+		{name: "index and slice", path: "$[0,3:]", expected: []string{"$[0]", "$[3]", "$[4]"}},
+		{name: "slices", path: "$[:1,-1:]", expected: []string{"$[0]", "$[4]"}},
+		{name: "overlapped", path: "$[1:3,2]", expected: []string{"$[1]", "$[2]"}},
+		{name: "reversed", path: "$[4,::-2]", expected: []string{"$[4]", "$[2]", "$[0]"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(data, test.path)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			if actual := Paths(result); !sliceEqual(actual, test.expected) {
+				t.Errorf("Error on JsonPath(json, %s): path doesn't match\nExpected: %s\nActual:   %s", test.path, sliceString(test.expected), sliceString(actual))
+			}
+		})
+	}
+
+	if result, err := JSONPath([]byte(`{"a": 1}`), "$[:]"); err != nil || len(result) != 0 {
+		t.Errorf("Slice of object: expected empty result, got %v, %v", result, err)
+	}
+	if _, err := JSONPath(data, "$[0,1:2:0]"); err == nil {
+		t.Errorf("Expected error on zero step in the union")
+	}
+}
+
+func TestJSONPath_recursive_descent_chained(t *testing.T) {
+	document := []byte(`{"a": {"b": 1, "x": {"b": 2, "a": {"b": 3}}}, "y": [{"a": [{"b": 4}]}], "b": 0}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "chained", path: `$..a..b`, expected: "[$['a']['b'], $['a']['x']['a']['b'], $['a']['x']['b'], $['y'][0]['a'][0]['b']]"},
+		{name: "nested descent", path: `$..a..`, expected: "[$['a'], $['a']['x']['a'], $['y'][0]['a'], $['a']['x'], $['y'][0]['a'][0]]"},
+		{name: "double", path: `$....b`, expected: "[$['b'], $['a']['b'], $['a']['x']['b'], $['a']['x']['a']['b'], $['y'][0]['a'][0]['b']]"},
+		{name: "triple", path: `$..a..a..b`, expected: "[$['a']['x']['a']['b']]"},
+		{name: "wildcard", path: `$..a..*`, expected: "[$['a']['b'], $['a']['x'], $['a']['x']['a']['b'], $['y'][0]['a'][0], $['a']['x']['a'], $['a']['x']['b'], $['y'][0]['a'][0]['b']]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_recursive_union(t *testing.T) {
+	document := []byte(`{
+		"id": 1,
+		"name": "root",
+		"items": [{"id": 2, "name": "foo", "sub": {"id": 3}}, {"name": "bar"}],
+		"owner": {"id": 4, "name": "baz"}
+	}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "keys", path: `$..['id','name']`, expected: "[$['id'], $['owner']['id'], $['items'][0]['id'], $['items'][0]['sub']['id'], $['name'], $['owner']['name'], $['items'][0]['name'], $['items'][1]['name']]"},
+		{name: "keys reversed", path: `$..['name','id']`, expected: "[$['name'], $['owner']['name'], $['items'][0]['name'], $['items'][1]['name'], $['id'], $['owner']['id'], $['items'][0]['id'], $['items'][0]['sub']['id']]"},
+		{name: "duplicated keys", path: `$..['id','id']`, expected: "[$['id'], $['owner']['id'], $['items'][0]['id'], $['items'][0]['sub']['id']]"},
+		{name: "from key", path: `$.items..['id','name']`, expected: "[$['items'][0]['id'], $['items'][0]['sub']['id'], $['items'][0]['name'], $['items'][1]['name']]"},
+		{name: "duplicated indexes", path: `$.items[0,0,1]`, expected: "[$['items'][0], $['items'][1]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				result, err := JSONPath(document, test.path)
 				if err != nil {
-					t.Errorf("Error on JsonPath(json, %s): error %s", test.path, err.Error())
-				} else {
-					switch {
-					case result[0].IsNumeric():
-						if val.(float64) != test.expected.(float64) {
-							t.Errorf("Error on JsonPath(json, %s): value doesn't match\nExpected: %v\nActual:   %v", test.path, test.expected, val)
-						}
-					case result[0].IsString():
-						if val.(string) != test.expected.(string) {
-							t.Errorf("Error on JsonPath(json, %s): value doesn't match\nExpected: %v\nActual:   %v", test.path, test.expected, val)
-						}
-					case result[0].IsBool():
-						if val.(bool) != test.expected.(bool) {
-							t.Errorf("Error on JsonPath(json, %s): value doesn't match\nExpected: %v\nActual:   %v", test.path, test.expected, val)
-						}
-					default:
-						t.Errorf("Error on JsonPath(json, %s): unsupported type found", test.path)
-					}
+					t.Errorf("JSONPath() unexpected error: %v", err)
+					return
+				}
+				if fullPath(result) != test.expected {
+					t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestJSONPath_filter_wildcard(t *testing.T) {
+	document := []byte(`{"items": [
+		{"active": true, "id": 1, "tags": ["a", "b"]},
+		{"active": false, "id": 2},
+		{"id": 3},
+		{"active": true, "id": 4, "tags": []}
+	]}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "dot wildcard", path: `$.items[?(@.active)].*`, expected: "[$['items'][0]['active'], $['items'][0]['id'], $['items'][0]['tags'], $['items'][3]['active'], $['items'][3]['id'], $['items'][3]['tags']]"},
+		{name: "bracket wildcard", path: `$.items[?(@.active)][*]`, expected: "[$['items'][0]['active'], $['items'][0]['id'], $['items'][0]['tags'], $['items'][3]['active'], $['items'][3]['id'], $['items'][3]['tags']]"},
+		{name: "nested wildcard", path: `$.items[?(@.active)].tags.*`, expected: "[$['items'][0]['tags'][0], $['items'][0]['tags'][1]]"},
+		{name: "nothing filtered", path: `$.items[?(@.id > 10)].*`, expected: "[]"},
+		{name: "filter after wildcard", path: `$.items[?(@.active)].*[?(@ == 'b')]`, expected: "[$['items'][0]['tags'][1]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_filter_scalars(t *testing.T) {
+	document := []byte(`{"scores": [70, 85, 90, 99], "names": ["alice", "bob", "carol"], "paths": ["/usr/bin/env", "/api/v1", "api-v1"]}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "numbers greater", path: `$.scores[?(@ > 80)]`, expected: "[$['scores'][1], $['scores'][2], $['scores'][3]]"},
+		{name: "numbers range", path: `$.scores[?(@ > 80 && @ < 95)]`, expected: "[$['scores'][1], $['scores'][2]]"},
+		{name: "numbers equal", path: `$.scores[?(@ == 99)]`, expected: "[$['scores'][3]]"},
+		{name: "numbers math", path: `$.scores[?(@ % 2 == 1)]`, expected: "[$['scores'][1], $['scores'][3]]"},
+		{name: "strings equal", path: `$.names[?(@ == 'bob')]`, expected: "[$['names'][1]]"},
+		{name: "strings not equal", path: `$.names[?(@ != 'bob')]`, expected: "[$['names'][0], $['names'][2]]"},
+		{name: "strings greater", path: `$.names[?(@ > 'b')]`, expected: "[$['names'][1], $['names'][2]]"},
+		{name: "strings regexp", path: `$.names[?(@ =~ '^[ab]')]`, expected: "[$['names'][0], $['names'][1]]"},
+		{name: "strings regexp case sensitive", path: `$.names[?(@ =~ '^[AB]')]`, expected: "[]"},
+		{name: "strings regexp case insensitive", path: `$.names[?(@ =~ '/^[AB]/i')]`, expected: "[$['names'][0], $['names'][1]]"},
+		{name: "strings regexp flags", path: `$.names[?(@ =~ '/^B.B$/is')]`, expected: "[$['names'][1]]"},
+		{name: "strings regexp path", path: `$.paths[?(@ =~ '/usr/bin')]`, expected: "[$['paths'][0]]"},
+		{name: "strings regexp path with slashes", path: `$.paths[?(@ =~ '/api/')]`, expected: "[$['paths'][1]]"},
+		{name: "strings regexp path with flags", path: `$.paths[?(@ =~ '/^/API/i')]`, expected: "[$['paths'][1]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_filter_existential(t *testing.T) {
+	document := []byte(`[
+		{"name": "a", "price": 200},
+		{"name": "b", "items": [{"price": 5}, {"details": {"price": 150}}]},
+		{"name": "c", "items": [{"price": 5}, {"price": 50}]},
+		{"name": "d", "x": null, "y": 1},
+		{},
+		[1, 2, 3],
+		[]
+	]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "nested price", path: `$[?(@..price > 100)]`, expected: "[$[0], $[1]]"},
+		{name: "nested price not", path: `$[?(!(@..price > 100))]`, expected: "[$[2], $[3], $[4], $[5], $[6]]"},
+		{name: "nested price math", path: `$[?(@..price * 2 == 100)]`, expected: "[$[2]]"},
+		{name: "nested price and", path: `$[?(@..price > 100 && @.name != 'a')]`, expected: "[$[1]]"},
+		{name: "nested price reversed", path: `$[?(10 > @..price)]`, expected: "[$[1], $[2]]"},
+		{name: "any child null", path: `$[?(@.* == null)]`, expected: "[$[3]]"},
+		{name: "any child", path: `$[?(@.* == 2)]`, expected: "[$[5]]"},
+		{name: "slice", path: `$[?(@[0:2] == 2)]`, expected: "[$[5]]"},
+		{name: "union", path: `$[?(@['x','y'] == 1)]`, expected: "[$[3]]"},
+		{name: "glob", path: `$[?(@.na* == 'c')]`, expected: "[$[2]]"},
+		{name: "filter", path: `$[?(@.items[?(@.price > 10)] != null)]`, expected: "[$[2]]"},
+		{name: "two sets", path: `$[?(@.items[*].price == @..price)]`, expected: "[$[1], $[2]]"},
+		{name: "function", path: `$[?(avg(@.items[*].price) > 20)]`, expected: "[$[2]]"},
+		{name: "nothing found", path: `$[?(@.missing.* == null)]`, expected: "[]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_filter_references(t *testing.T) {
+	document := []byte(`{
+		"limit": 10,
+		"groups": [
+			{"avg": 15, "items": [{"price": 10}, {"price": 20}, {"price": 30}]},
+			{"avg": 4, "items": [{"price": 2}, {"price": 6}]}
+		],
+		"prices": [5, 12, 30]
+	}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "root", path: `$.prices[?(@ > @root.limit)]`, expected: "[$['prices'][1], $['prices'][2]]"},
+		{name: "root brackets", path: `$.prices[?(@ > @root['limit'])]`, expected: "[$['prices'][1], $['prices'][2]]"},
+		{name: "root is $", path: `$.prices[?(@root.limit == $.limit)]`, expected: "[$['prices'][0], $['prices'][1], $['prices'][2]]"},
+		{name: "parent", path: `$.prices[?(@parent.length == 3)]`, expected: "[$['prices'][0], $['prices'][1], $['prices'][2]]"},
+		{name: "parent of parent", path: `$.groups[*].items[?(@.price > @parent[0].price)]`, expected: "[$['groups'][0]['items'][1], $['groups'][0]['items'][2], $['groups'][1]['items'][1]]"},
+		{name: "parent descent", path: `$..[?(@.price > 10 && @parent.length > 2)]`, expected: "[$['groups'][0]['items'][1], $['groups'][0]['items'][2]]"},
+		{name: "group average", path: `$.groups[?(@.avg > @parent[1].avg)].items[*].price`, expected: "[$['groups'][0]['items'][0]['price'], $['groups'][0]['items'][1]['price'], $['groups'][0]['items'][2]['price']]"},
+		{name: "parent of the children of the root", path: `$[?(@parent.limit == 10)]`, expected: "[$['groups'], $['limit'], $['prices']]"},
+		{name: "not a reference", path: `$[?(@rooted == 1)]`, expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if test.expected == "" {
+				if err == nil {
+					t.Errorf("JSONPath() expected error, got: %s", fullPath(result))
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+
+	root := Must(Unmarshal(document))
+	if result, err := Eval(root, "@parent"); err != nil || !result.IsNull() {
+		t.Errorf("Eval(@parent) of the root should be null, got: %v, %v", result, err)
+	}
+	if result, err := Eval(root.MustKey("prices").MustIndex(0), "@root.limit + @parent[2]"); err != nil || result.MustNumeric() != 40 {
+		t.Errorf("Eval(@root.limit + @parent[2]) wrong result: %v, %v", result, err)
+	}
+}
+
+func TestJSONPath_filter_quotes(t *testing.T) {
+	document := []byte(`[{"name": "bob"}, {"name": "it's"}, {"name": "say \"hi\""}, {"name": "a\"b"}, {"name": "a'b"}]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "double quotes", path: `$[?(@.name == "bob")]`, expected: "[$[0]]"},
+		{name: "single quotes", path: `$[?(@.name == 'bob')]`, expected: "[$[0]]"},
+		{name: "double quotes reversed", path: `$[?("bob" == @.name)]`, expected: "[$[0]]"},
+		{name: "single quotes reversed", path: `$[?('bob' == @.name)]`, expected: "[$[0]]"},
+		{name: "single quote inside double quotes", path: `$[?(@.name == "it's")]`, expected: "[$[1]]"},
+		{name: "double quotes inside single quotes", path: `$[?(@.name == 'say "hi"')]`, expected: "[$[2]]"},
+		{name: "escaped double quote", path: `$[?(@.name == "a\"b")]`, expected: "[$[3]]"},
+		{name: "escaped single quote", path: `$[?(@.name == 'a\'b')]`, expected: "[$[4]]"},
+		{name: "mixed quotes", path: `$[?(@.name == "bob" || @.name == 'it\'s')]`, expected: "[$[0], $[1]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_glob(t *testing.T) {
+	document := []byte(`{
+		"store": {"book": [{"author": "foo"}], "bestseller": {"author": "bar"}, "bicycle": {"brand": "baz"}, "b*": 1, "car": 2},
+		"archive": {"book": [], "album": {"artist": "qux"}}
+	}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "prefix", path: `$.store.b*`, expected: "[$['store']['b*'], $['store']['bestseller'], $['store']['bicycle'], $['store']['book']]"},
+		{name: "single character", path: `$.store.b??k`, expected: "[$['store']['book']]"},
+		{name: "infix", path: `$.store.*e*`, expected: "[$['store']['bestseller'], $['store']['bicycle']]"},
+		{name: "quoted", path: `$.store['b*']`, expected: "[$['store']['b*']]"},
+		{name: "union", path: `$.store[b*,c*]`, expected: "[$['store']['b*'], $['store']['bestseller'], $['store']['bicycle'], $['store']['book'], $['store']['car']]"},
+		{name: "recursive", path: `$..a*`, expected: "[$['archive'], $['archive']['album'], $['archive']['album']['artist'], $['store']['bestseller']['author'], $['store']['book'][0]['author']]"},
+		{name: "chained", path: `$.*.b*k.*`, expected: "[$['store']['book'][0]]"},
+		{name: "arrays", path: `$.store.book.a*`, expected: "[]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestJSONPath_last_element(t *testing.T) {
+	document := []byte(`{
+		"items": [{"name": "a"}, {"name": "b"}, {"name": "c"}],
+		"one": [1],
+		"empty": [],
+		"nested": [[1, 2], [3]]
+	}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "last", path: `$.items[(@.length-1)]`, expected: "[$['items'][2]]"},
+		{name: "last with spaces", path: `$.items[( @.length - 1 )]`, expected: "[$['items'][2]]"},
+		{name: "last child", path: `$.items[(@.length-1)].name`, expected: "[$['items'][2]['name']]"},
+		{name: "last but one", path: `$.items[(@.length-2)]`, expected: "[$['items'][1]]"},
+		{name: "single", path: `$.one[(@.length-1)]`, expected: "[$['one'][0]]"},
+		{name: "empty", path: `$.empty[(@.length-1)]`, expected: "[]"},
+		{name: "each", path: `$.nested[*][(@.length-1)]`, expected: "[$['nested'][0][1], $['nested'][1][0]]"},
+		{name: "negative", path: `$.items[(@.length-4)]`, expected: "[$['items'][2]]"},
+		{name: "out of range", path: `$.items[(@.length)]`, expected: "[]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %v", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestValidatePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: `$`},
+		{path: `@.foo`},
+		{path: `$..*`},
+		{path: `$.store.book[?(@.price < 10)].title`},
+		{path: `$..book[-1:]`},
+		{path: `$[::-1]`},
+		{path: `$[1:(@.length-1):2]`},
+		{path: `$['store']['book'][-2,(@.length-1)]`},
+		{path: `$[(@.length-1)]`},
+		{path: `$[?(!@.isbn)]`},
+		{path: `$[?(avg(@..price) > 1 && @.name =~ 'x')]`},
+		{path: `$.b*`},
+		{path: `$[:]`},
+		{path: `$[0,2:4,-1:]`},
+		{path: `foo`},
+		{path: `store.book[0]`},
+		{path: `foo[`, wantErr: true},
+		{path: `$.foo[`, wantErr: true},
+		{path: `$['foo`, wantErr: true},
+		{path: `$[]`, wantErr: true},
+		{path: `$[1:2:3:4]`, wantErr: true},
+		{path: `$[1:foo]`, wantErr: true},
+		{path: `$[1:2:0]`, wantErr: true},
+		{path: `$[0,1:2:0]`, wantErr: true},
+		{path: `$[0,1:foo]`, wantErr: true},
+		{path: `$[1:(@.length-)]`, wantErr: true},
+		{path: `$[,]`, wantErr: true},
+		{path: `$[1,]`, wantErr: true},
+		{path: `$[1,(2*)]`, wantErr: true},
+		{path: `$[?(@.foo`, wantErr: true},
+		{path: `$[?(@.foo))]`, wantErr: true},
+		{path: `$[?()]`, wantErr: true},
+		{path: `$[?(@.foo == )]`, wantErr: true},
+		{path: `$[?(@.foo == 1 2)]`, wantErr: true},
+		{path: `$[?(@.foo[1:2:3:4])]`, wantErr: true},
+		{path: `$[(1+)]`, wantErr: true},
+		{path: `$.a[?(@.x ==)]`, wantErr: true},
+		{path: `$.a[?(match(@, 1))]`, wantErr: true},
+		{path: `$.a[?(match(@))]`, wantErr: true},
+		{path: `$.a[?(match(@, '['))]`, wantErr: true},
+		{path: `$.a[?(length(@, 1))]`, wantErr: true},
+		{path: `$.a[?(match(@, 'x') && search(@.y, @.z))]`},
+		{path: `$[99999999999999999999:]`},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if err := ValidatePath(test.path); (err != nil) != test.wantErr {
+				t.Errorf("ValidatePath() error = %v, wantErr %v", err, test.wantErr)
+			}
+			for _, data := range []string{`{}`, `{"a":[{"x":1}]}`} { // evaluation agrees with the validation regardless of the data
+				if _, err := JSONPath([]byte(data), test.path); (err != nil) != test.wantErr {
+					t.Errorf("JSONPath(%s) error = %v, wantErr %v", data, err, test.wantErr)
 				}
 			}
 		})
@@ -269,12 +1721,22 @@ func TestParseJSONPath(t *testing.T) {
 		{name: "path bracket:simple", path: "$['root']['element']", expected: []string{"$", "'root'", "'element'"}},
 		{name: "path bracket:combined", path: "$['root'][*]['element']", expected: []string{"$", "'root'", "*", "'element'"}},
 		{name: "path bracket:int", path: "$['store']['book'][0]['title']", expected: []string{"$", "'store'", "'book'", "0", "'title'"}},
+		{name: "path dot:int", path: "$.store.2020.title", expected: []string{"$", "store", "2020", "title"}},
+		{name: "path dot:index", path: "$.arr.0", expected: []string{"$", "arr", "0"}},
+		{name: "path dot:negative", path: "$.arr.-1", expected: []string{"$", "arr", "-1"}},
 		{name: "path combined:simple", path: "$['root'].*['element']", expected: []string{"$", "'root'", "*", "'element'"}},
 		{name: "path combined:dotted", path: "$.['root'].*.['element']", expected: []string{"$", "'root'", "*", "'element'"}},
 		{name: "path combined:dotted small", path: "$['root'].*.['element']", expected: []string{"$", "'root'", "*", "'element'"}},
 		{name: "phoneNumbers", path: "$.phoneNumbers[*].type", expected: []string{"$", "phoneNumbers", "*", "type"}},
 		{name: "filtered", path: "$.store.book[?(@.price < 10)].title", expected: []string{"$", "store", "book", "?(@.price < 10)", "title"}},
 		{name: "formula", path: "$..phoneNumbers..('ty' + 'pe')", expected: []string{"$", "..", "phoneNumbers", "..", "('ty' + 'pe')"}},
+		{name: "keys dot", path: "$.store.*~", expected: []string{"$", "store", "*", "~"}},
+		{name: "keys bracket", path: "$.store['book'][*]~", expected: []string{"$", "store", "'book'", "*", "~"}},
+		{name: "keys name", path: "$.store.book~.length", expected: []string{"$", "store", "book", "~", "length"}},
+		{name: "keys quoted", path: "$['a~']", expected: []string{"$", "'a~'"}},
+		{name: "parent dot", path: "$.a.b.^.c", expected: []string{"$", "a", "b", "^", "c"}},
+		{name: "parent bracket", path: "$['a'][0]^", expected: []string{"$", "'a'", "0", "^"}},
+		{name: "parent quoted", path: "$['^']", expected: []string{"$", "'^'"}},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -712,6 +2174,19 @@ func TestEval(t *testing.T) {
 	}
 }
 
+func TestEval_keeps_parents(t *testing.T) {
+	root := Must(Unmarshal(jsonPathTestData))
+	prices, _ := root.JSONPath("$..price")
+	expected := fullPath(prices)
+	if _, err := Eval(root, "avg($..price)"); err != nil {
+		t.Errorf("Eval() error: %s", err)
+		return
+	}
+	if actual := fullPath(prices); actual != expected {
+		t.Errorf("Eval() changed parents of the nodes:\nExpected: %s\nActual:   %s", expected, actual)
+	}
+}
+
 func BenchmarkJSONPath_all_prices(b *testing.B) {
 	var err error
 	for i := 0; i < b.N; i++ {
@@ -1405,3 +2880,26 @@ func TestJSONPath_special_requests(t *testing.T) {
 		})
 	}
 }
+
+func TestIsMultiple(t *testing.T) {
+	tests := map[string]bool{
+		"$.a.b":           false,
+		"$['a'][0]":       false,
+		"$..a":            true,
+		"$.*":             true,
+		"$.a*":            true,
+		"$[1:3]":          true,
+		"$['a','b']":      true,
+		"$[?(@.a == 1)]":  true,
+		"$[?(@.a)].b":     true,
+		"$[(@.length-1)]": false,
+	}
+	for path, expected := range tests {
+		parsed, err := parsePath(path)
+		if err != nil {
+			t.Errorf("parsePath(%s) unexpected error: %s", path, err)
+		} else if parsed.multiple != expected || isMultiple(parsed.commands) != expected {
+			t.Errorf("isMultiple(%s) wrong result: %t", path, parsed.multiple)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package ajson
 
 import (
+	"context"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -46,7 +48,7 @@ var (
 	//	<=  less or equals          any
 	//	>   larger                  any
 	//	>=  larger or equals        any
-	//	=~  equals regex string     strings
+	//	=~  equals regex string     strings, pattern with the flags i, m, s is wrapped into slashes: `/^a/i`
 	//
 	priority = map[string]uint8{
 		"**": 6, // additional: power
@@ -220,11 +222,11 @@ var (
 			if err != nil {
 				return nil, err
 			}
-			res, err := regexp.MatchString(pattern, val)
+			re, err := compileRegexp(pattern, true)
 			if err != nil {
 				return nil, err
 			}
-			return valueNode(nil, "eq", Bool, res), nil
+			return valueNode(nil, "eq", Bool, re.MatchString(val)), nil
 		},
 		"<": func(left *Node, right *Node) (result *Node, err error) {
 			res, err := left.Le(right)
@@ -348,6 +350,12 @@ var (
 			}
 			return valueNode(nil, "length", Numeric, float64(1)), nil
 		},
+		"index": func(node *Node) (result *Node, err error) {
+			if node.parent != nil && node.parent.IsArray() && node.index != nil {
+				return valueNode(nil, "index", Numeric, float64(*node.index)), nil
+			}
+			return NullNode(""), nil
+		},
 		"factorial": func(node *Node) (result *Node, err error) {
 			num, err := node.getUInteger()
 			if err != nil {
@@ -356,7 +364,7 @@ var (
 			return valueNode(nil, "factorial", Numeric, float64(mathFactorial(num))), nil
 		},
 		"avg": func(node *Node) (result *Node, err error) {
-			if node.isContainer() {
+			if node.IsContainer() {
 				sum := float64(0)
 				if node.Size() == 0 {
 					return valueNode(nil, "avg", Numeric, sum), nil
@@ -374,7 +382,7 @@ var (
 			return valueNode(nil, "avg", Null, nil), nil
 		},
 		"sum": func(node *Node) (result *Node, err error) {
-			if node.isContainer() {
+			if node.IsContainer() {
 				sum := float64(0)
 				if node.Size() == 0 {
 					return valueNode(nil, "sum", Numeric, sum), nil
@@ -426,6 +434,141 @@ func AddFunction(alias string, function Function) {
 	functions[strings.ToLower(alias)] = function
 }
 
+// variadicFunctions are the functions with any number of arguments, registered with RegisterFunction
+var variadicFunctions = map[string]func(args []*Node) (*Node, error){
+	"match": func(args []*Node) (*Node, error) {
+		value, re, err := regexpArgs("match", args)
+		if err != nil || re == nil {
+			return valueNode(nil, "match", Bool, false), err
+		}
+		return valueNode(nil, "match", Bool, re.MatchString(value)), nil
+	},
+	"search": func(args []*Node) (*Node, error) {
+		value, re, err := regexpArgs("search", args)
+		if err != nil || re == nil {
+			return NullNode("search"), err
+		}
+		found := re.FindStringSubmatch(value)
+		switch len(found) {
+		case 0:
+			return NullNode("search"), nil
+		case 1:
+			return valueNode(nil, "search", String, found[0]), nil
+		}
+		return valueNode(nil, "search", String, found[1]), nil
+	},
+}
+
+// variadicChecks check the arguments of the variadic functions before the evaluation, see compileExpression.
+// Argument is the constant node, or nil if the value is known only on the evaluation.
+var variadicChecks = map[string]func(args []*Node) error{
+	"match": func(args []*Node) error {
+		return checkRegexpArgs("match", args)
+	},
+	"search": func(args []*Node) error {
+		return checkRegexpArgs("search", args)
+	},
+}
+
+// checkRegexpArgs checks the count of the arguments of the functions match and search, and the constant pattern
+func checkRegexpArgs(name string, args []*Node) error {
+	if len(args) != 2 {
+		return errorRequest("function %s expects 2 arguments, got %d", name, len(args))
+	}
+	if args[1] == nil {
+		return nil
+	}
+	pattern, err := args[1].GetString()
+	if err != nil {
+		return err
+	}
+	_, err = compileRegexp(pattern, false)
+	return err
+}
+
+// regexpArgs returns the string value and the compiled pattern of the arguments of the functions match and search.
+// Pattern is nil if the value is not a String.
+func regexpArgs(name string, args []*Node) (value string, re *regexp.Regexp, err error) {
+	if len(args) != 2 {
+		return "", nil, errorRequest("function %s expects 2 arguments, got %d", name, len(args))
+	}
+	pattern, err := args[1].GetString()
+	if err != nil {
+		return "", nil, err
+	}
+	if !args[0].IsString() {
+		return "", nil, nil
+	}
+	if value, err = args[0].GetString(); err != nil {
+		return "", nil, err
+	}
+	re, err = compileRegexp(pattern, false)
+	return value, re, err
+}
+
+// compileRegexp returns compiled pattern from the cache, see regexpExpr
+func compileRegexp(pattern string, flagsRequired bool) (*regexp.Regexp, error) {
+	expr := regexpExpr(pattern, flagsRequired)
+	if re, ok := regexpCache.get(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errorRequest("wrong regexp: %s", err)
+	}
+	regexpCache.set(expr, re)
+	return re, nil
+}
+
+// regexpExpr returns the expression of the pattern: the literal wrapped into slashes with the flags i, m, s, like `/^a/i`,
+// is converted into `(?i)^a`, any other pattern is returned as is, like `/usr/bin`. With flagsRequired the literal
+// without flags is returned as is too, so the pattern `/api/` matches only the string with the slashes.
+func regexpExpr(pattern string, flagsRequired bool) string {
+	end := strings.LastIndexByte(pattern, division)
+	if len(pattern) < 2 || pattern[0] != division || end == 0 {
+		return pattern
+	}
+	flags := pattern[end+1:]
+	if strings.Trim(flags, "ims") != "" || (flagsRequired && flags == "") {
+		return pattern
+	}
+	if flags == "" {
+		return pattern[1:end]
+	}
+	return "(?" + flags + ")" + pattern[1:end]
+}
+
+// RegisterFunction registers a function with any number of comma separated arguments for internal JSONPath script,
+// like `distance(@.from, @.to)`. Function name should contain only letters, digits and underscores, and should not be used by another function.
+func RegisterFunction(name string, fn func(args []*Node) (*Node, error)) error {
+	alias := strings.ToLower(name)
+	if alias == "" || alias[0] < 'a' || alias[0] > 'z' {
+		return errorRequest("wrong function name: '%s'", name)
+	}
+	for i := 0; i < len(alias); i++ {
+		if !(alias[i] >= 'a' && alias[i] <= 'z') && !(alias[i] >= '0' && alias[i] <= '9') && alias[i] != '_' {
+			return errorRequest("wrong function name: '%s'", name)
+		}
+	}
+	if fn == nil {
+		return errorRequest("function '%s' is nil", name)
+	}
+	if isFunction(alias) {
+		return errorRequest("function '%s' is already registered", name)
+	}
+	variadicFunctions[alias] = fn
+	return nil
+}
+
+// isFunction returns true if the alias is a name of the function, added with AddFunction or RegisterFunction
+func isFunction(alias string) bool {
+	if _, ok := functions[alias]; ok {
+		return true
+	}
+	_, ok := variadicFunctions[alias]
+	return ok
+}
+
 // AddOperation add an operation for internal JSONPath script
 func AddOperation(alias string, prior uint8, right bool, operation Operation) {
 	alias = strings.ToLower(alias)
@@ -437,11 +580,80 @@ func AddOperation(alias string, prior uint8, right bool, operation Operation) {
 	}
 }
 
+// RegisterOperator registers a left-associative binary operation for internal JSONPath script, like `@.name ~= 'foo'`.
+// Symbol should contain one or two characters, which are not letters, digits, quotes, brackets, spaces or any of `$@.,_`,
+// and should not be used by another operation. Precedence should be in range from 1 (like `||`) to 255, see the precedence of the predefined operations.
+func RegisterOperator(symbol string, precedence int, fn func(left, right *Node) (*Node, error)) error {
+	if len(symbol) == 0 || len(symbol) > 2 {
+		return errorRequest("wrong operator symbol: '%s'", symbol)
+	}
+	for i := 0; i < len(symbol); i++ {
+		if !isOperatorChar(symbol[i]) {
+			return errorRequest("wrong operator symbol: '%s'", symbol)
+		}
+	}
+	if precedence < 1 || precedence > math.MaxUint8 {
+		return errorRequest("wrong operator precedence: %d", precedence)
+	}
+	if fn == nil {
+		return errorRequest("operator '%s' is nil", symbol)
+	}
+	if _, ok := operations[symbol]; ok {
+		return errorRequest("operator '%s' is already registered", symbol)
+	}
+	AddOperation(symbol, uint8(precedence), false, fn)
+	return nil
+}
+
+// isOperatorChar returns true if c can be used in the symbol of the operation
+func isOperatorChar(c byte) bool {
+	switch c {
+	case quotes, quote, coma, backslash, skipS, skipN, skipR, skipT, bracketL, bracketR, bracesL, bracesR, parenthesesL, parenthesesR, dollar, at, dot, '_':
+		return false
+	}
+	return c > ' ' && c < 0x7f && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9')
+}
+
 // AddConstant add a constant for internal JSONPath script
 func AddConstant(alias string, value *Node) {
 	constants[strings.ToLower(alias)] = value
 }
 
+// comparisons are the operations, which compare numeric strings with numbers as numbers in loose mode, see JSONPathLoose
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// looseKey is the key of the context value, which enables loose comparison, see JSONPathLoose
+type looseKey struct{}
+
+// isLoose returns true if loose comparison is enabled in the context
+func isLoose(ctx context.Context) bool {
+	loose, _ := ctx.Value(looseKey{}).(bool)
+	return loose
+}
+
+// operate returns the result of the operation, numeric string operand is converted to Numeric if loose is true and another operand is Numeric
+func operate(op Operation, left, right *Node, loose bool) (*Node, error) {
+	if loose {
+		left, right = coerceNumeric(left, right), coerceNumeric(right, left)
+	}
+	return op(left, right)
+}
+
+func coerceNumeric(node, other *Node) *Node {
+	if !node.IsString() || !other.IsNumeric() {
+		return node
+	}
+	value, err := node.GetString()
+	if err != nil {
+		return node
+	}
+	float, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsInf(float, 0) || math.IsNaN(float) {
+		return node
+	}
+	return NumericNode("", float)
+}
+
 func numericFunction(name string, fn func(float float64) float64) Function {
 	return func(node *Node) (result *Node, err error) {
 		if node.IsNumeric() {
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -49,6 +51,37 @@ func ExampleAddFunction_usage() {
 	// Avg price: 5.5
 }
 
+func ExampleRegisterFunction() {
+	err := RegisterFunction("distance", func(args []*Node) (*Node, error) {
+		if len(args) != 2 {
+			return nil, errors.New("wrong number of arguments")
+		}
+		from, err := args[0].GetNumeric()
+		if err != nil {
+			return nil, err
+		}
+		to, err := args[1].GetNumeric()
+		if err != nil {
+			return nil, err
+		}
+		return NumericNode("distance", math.Abs(to-from)), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	json := []byte(`[{"from": 1, "to": 3}, {"from": 5, "to": 15}, {"from": 10, "to": 8}]`)
+	result, err := JSONPath(json, `$[?(distance(@.from, @.to) < 5)]`)
+	if err != nil {
+		panic(err)
+	}
+	for _, node := range result {
+		fmt.Println(node.Path())
+	}
+	// Output:
+	// $[0]
+	// $[2]
+}
+
 func ExampleAddConstant() {
 	AddConstant("SqrtPi", NumericNode("SqrtPi", math.SqrtPi))
 }
@@ -89,6 +122,34 @@ func ExampleAddOperation() {
 	})
 }
 
+func ExampleRegisterOperator() {
+	err := RegisterOperator("~=", 3, func(left, right *Node) (*Node, error) {
+		lstr, err := left.GetString()
+		if err != nil {
+			return nil, err
+		}
+		rstr, err := right.GetString()
+		if err != nil {
+			return nil, err
+		}
+		return BoolNode("fuzzy", strings.EqualFold(strings.TrimSpace(lstr), strings.TrimSpace(rstr))), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	json := []byte(`[{"name": " Foo "}, {"name": "bar"}, {"name": "FOO"}]`)
+	result, err := JSONPath(json, `$[?(@.name ~= 'foo')]`)
+	if err != nil {
+		panic(err)
+	}
+	for _, node := range result {
+		fmt.Println(node.Path())
+	}
+	// Output:
+	// $[0]
+	// $[2]
+}
+
 func ExampleAddOperation_regex() {
 	json := []byte(`[{"name":"Foo","mail":"foo@example.com"},{"name":"bar","mail":"bar@example.org"}]`)
 	result, err := JSONPath(json, `$.[?(@.mail =~ '.+@example\\.com')]`)
@@ -212,6 +273,13 @@ func TestOperations(t *testing.T) {
 		&operationTest{name: "regexp true", operation: "=~", left: StringNode("", `123`), right: StringNode("", `\d+`), result: _true},
 		&operationTest{name: "regexp false", operation: "=~", left: StringNode("", `1 2 3`), right: StringNode("", `^\d+$`), result: _false},
 		&operationTest{name: "regexp pattern error", operation: "=~", left: StringNode("", `2`), right: StringNode("", `\2`), fail: true},
+		&operationTest{name: "regexp flags", operation: "=~", left: StringNode("", "Foo\nbar"), right: StringNode("", `/^BAR$/mi`), result: _true},
+		&operationTest{name: "regexp not flags", operation: "=~", left: StringNode("", `foo`), right: StringNode("", `/foo/x`), result: _false},
+		&operationTest{name: "regexp path with not flags", operation: "=~", left: StringNode("", `/foo/x`), right: StringNode("", `/foo/x`), result: _true},
+		&operationTest{name: "regexp path", operation: "=~", left: StringNode("", `/usr/bin/env`), right: StringNode("", `/usr/bin`), result: _true},
+		&operationTest{name: "regexp path without flags", operation: "=~", left: StringNode("", `api-v1`), right: StringNode("", `/api/`), result: _false},
+		&operationTest{name: "regexp path without flags match", operation: "=~", left: StringNode("", `/api/v1`), right: StringNode("", `/api/`), result: _true},
+		&operationTest{name: "regexp flags with slashes", operation: "=~", left: StringNode("", `A/B`), right: StringNode("", `/^a/b$/i`), result: _true},
 		&operationTest{name: "regexp error 1", operation: "=~", left: _f, right: StringNode("", `123`), fail: true},
 		&operationTest{name: "regexp error 2", operation: "=~", left: StringNode("", `\d+`), right: _f, fail: true},
 	)
@@ -272,6 +340,322 @@ func TestAddFunction(t *testing.T) {
 	}
 }
 
+func TestRegisterFunction(t *testing.T) {
+	name := "new_variadic_function_name"
+	if isFunction(name) {
+		t.Error("test function already exists")
+	}
+	defer delete(variadicFunctions, name)
+	err := RegisterFunction(strings.ToUpper(name), func(args []*Node) (*Node, error) {
+		var sum float64
+		for _, arg := range args {
+			num, err := arg.GetNumeric()
+			if err != nil {
+				return nil, err
+			}
+			sum += num
+		}
+		return NumericNode("sum", sum), nil
+	})
+	if err != nil {
+		t.Errorf("RegisterFunction() unexpected error: %s", err)
+	}
+	if !isFunction(name) {
+		t.Error("test function was not added")
+	}
+
+	errs := []struct {
+		name string
+		fn   func(args []*Node) (*Node, error)
+	}{
+		{name: name},
+		{name: "abs"},
+		{name: ""},
+		{name: "1abc"},
+		{name: "a-b"},
+		{name: "nil"},
+	}
+	for _, test := range errs {
+		fn := test.fn
+		if fn == nil && test.name != "nil" {
+			fn = func(args []*Node) (*Node, error) { return NullNode(""), nil }
+		}
+		if err := RegisterFunction(test.name, fn); err == nil {
+			t.Errorf("RegisterFunction(%q) expected error", test.name)
+		}
+	}
+
+	root := Must(Unmarshal([]byte(`{"a": 1, "b": 2, "c": [3, 4]}`)))
+	tests := []struct {
+		expression string
+		expected   float64
+		null       bool
+		err        bool
+	}{
+		{expression: name + "(1)", expected: 1},
+		{expression: name + "(@.a, @.b)", expected: 3},
+		{expression: name + "(@.a, @.b * 2, @.c[0]) + 1", expected: 9},
+		{expression: name + "(abs(-1), (@.a + @.b) * 2)", expected: 7},
+		{expression: name + "(1, " + name + "(2, 3))", expected: 6},
+		{expression: name + "(1, @.missing)", null: true},
+		{expression: "abs(1, 2)", err: true},
+		{expression: "(1, 2)", err: true},
+		{expression: "1, 2", err: true},
+		{expression: "(1, 2) + 1", err: true},
+		{expression: name + "(1, 'a')", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			result, err := Eval(root, test.expression)
+			if test.err {
+				if err == nil {
+					t.Errorf("Eval() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Eval() unexpected error: %s", err)
+			} else if test.null {
+				if !result.IsNull() {
+					t.Errorf("Eval() wrong result: %s, expected null", result)
+				}
+			} else if result.MustNumeric() != test.expected {
+				t.Errorf("Eval() wrong result: %v, expected %v", result.MustNumeric(), test.expected)
+			}
+		})
+	}
+
+	result, err := JSONPath([]byte(`[[1, 2], [3, 4], [0, 1]]`), `$[?(`+name+`(@[0], @[1]) > 3)]`)
+	if err != nil {
+		t.Errorf("JSONPath() unexpected error: %s", err)
+	} else if fullPath(result) != "[$[1]]" {
+		t.Errorf("JSONPath() wrong result: %s", fullPath(result))
+	}
+	if err := ValidatePath(`$[?(` + name + `(@.a, @.b) > 2)]`); err != nil {
+		t.Errorf("ValidatePath() unexpected error: %s", err)
+	}
+	if err := ValidatePath(`$[?(abs(@.a, ) > 2)]`); err == nil {
+		t.Errorf("ValidatePath() expected error")
+	}
+}
+
+func TestFunctions_index(t *testing.T) {
+	document := []byte(`{"list": ["a", "b", "c", "d", "e"], "items": [{"id": 1}, {"id": 2}, {"id": 3}]}`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "even", path: `$.list[?(index(@) % 2 == 0)]`, expected: "[$['list'][0], $['list'][2], $['list'][4]]"},
+		{name: "odd objects", path: `$.items[?(index(@) % 2 == 1)].id`, expected: "[$['items'][1]['id']]"},
+		{name: "first", path: `$..[?(index(@) == 0)]`, expected: "[$['items'][0], $['list'][0]]"},
+		{name: "compare with field", path: `$.items[?(index(@) + 1 == @.id)]`, expected: "[$['items'][0], $['items'][1], $['items'][2]]"},
+		{name: "last", path: `$.list[?(index(@) == @parent.length - 1)]`, expected: "[$['list'][4]]"},
+		{name: "values of object", path: `$[?(index(@) == null)]`, expected: "[$['items'], $['list']]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+}
+
+func TestFunctions_regexp(t *testing.T) {
+	document := []byte(`[
+		{"sku": "A-100", "name": "Foo"},
+		{"sku": "a-200", "name": "foo bar"},
+		{"sku": "B-300", "name": "Bar"},
+		{"sku": 400, "name": null},
+		{"name": "baz"}
+	]`)
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "match prefix", path: `$[?(match(@.sku, '/^A/'))]`, expected: "[$[0]]"},
+		{name: "match full", path: `$[?(match(@.sku, '/^[A-Z]-\\d+$/'))]`, expected: "[$[0], $[2]]"},
+		{name: "match flags", path: `$[?(match(@.sku, '/^a/i'))]`, expected: "[$[0], $[1]]"},
+		{name: "match without slashes", path: `$[?(match(@.name, 'bar'))]`, expected: "[$[1]]"},
+		{name: "match not", path: `$[?(!match(@.name, '/^f/i'))]`, expected: "[$[2], $[3], $[4]]"},
+		{name: "search capture", path: `$[?(search(@.sku, '/-(\\d)/') == '2')]`, expected: "[$[1]]"},
+		{name: "search match", path: `$[?(search(@.name, '/o+/') == 'oo')]`, expected: "[$[0], $[1]]"},
+		{name: "search not found", path: `$[?(search(@.name, '/z$/') == null)]`, expected: "[$[0], $[1], $[2], $[3]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := JSONPath(document, test.path)
+			if err != nil {
+				t.Errorf("JSONPath() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("Error on JsonPath(json, %s) as %s: path doesn't match\nExpected: %s\nActual:   %s", test.path, test.name, test.expected, fullPath(result))
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`{"id": "order-2024-17"}`)))
+	result, err := Eval(root, `search(@.id, '/-(\\d{4})-/')`)
+	if err != nil || result.MustString() != "2024" {
+		t.Errorf("search() wrong result: %v, %v", result, err)
+	}
+	result, err = Eval(root, `search(@.id, '/\\d+$/')`)
+	if err != nil || result.MustString() != "17" {
+		t.Errorf("search() wrong result: %v, %v", result, err)
+	}
+
+	for _, expr := range []string{`match(@.id)`, `match(@.id, '/a/', 1)`, `match(@.id, 1)`, `match(@.id, '/(/')`, `search(@.id, '/(/i')`} {
+		if _, err = Eval(root, expr); err == nil {
+			t.Errorf("Eval(%s) expected error", expr)
+		}
+	}
+
+	first, err := compileRegexp("/^a/i", false)
+	if err != nil {
+		t.Errorf("compileRegexp() unexpected error: %s", err)
+	}
+	if second, _ := compileRegexp("/^a/i", true); first != second {
+		t.Errorf("compileRegexp() pattern is not cached")
+	}
+	for i := 0; i < defaultRegexpCacheSize*2; i++ {
+		if _, err = compileRegexp("^"+strconv.Itoa(i)+"$", false); err != nil {
+			t.Errorf("compileRegexp() unexpected error: %s", err)
+		}
+	}
+	if regexpCache.len() != defaultRegexpCacheSize {
+		t.Errorf("compileRegexp() cache is not bounded: %d", regexpCache.len())
+	}
+}
+
+func TestRegisterOperator(t *testing.T) {
+	symbol := "<?"
+	if _, ok := operations[symbol]; ok {
+		t.Error("test operator already exists")
+	}
+	defer func() {
+		delete(operations, symbol)
+		delete(priority, symbol)
+	}()
+	err := RegisterOperator(symbol, 4, func(left, right *Node) (*Node, error) { // minimum of the operands
+		lnum, rnum, err := _floats(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return NumericNode("min", math.Min(lnum, rnum)), nil
+	})
+	if err != nil {
+		t.Errorf("RegisterOperator() unexpected error: %s", err)
+	}
+
+	errs := []struct {
+		symbol     string
+		precedence int
+		nil        bool
+	}{
+		{symbol: symbol, precedence: 1},
+		{symbol: "==", precedence: 1},
+		{symbol: "", precedence: 1},
+		{symbol: "<<<", precedence: 1},
+		{symbol: "a", precedence: 1},
+		{symbol: "~1", precedence: 1},
+		{symbol: "$", precedence: 1},
+		{symbol: "~(", precedence: 1},
+		{symbol: "~", precedence: 0},
+		{symbol: "~", precedence: 256},
+		{symbol: "~", precedence: 1, nil: true},
+	}
+	for _, test := range errs {
+		fn := func(left, right *Node) (*Node, error) { return left, nil }
+		if test.nil {
+			fn = nil
+		}
+		if err := RegisterOperator(test.symbol, test.precedence, fn); err == nil {
+			t.Errorf("RegisterOperator(%q, %d) expected error", test.symbol, test.precedence)
+		}
+	}
+
+	root := Must(Unmarshal([]byte(`{"a": 1, "b": 2}`)))
+	tests := []struct {
+		expression string
+		expected   float64
+	}{
+		{expression: "@.a <? @.b", expected: 1},
+		{expression: "@.b<?@.a", expected: 1},
+		{expression: "3 <? 10 <? 5", expected: 3},
+		{expression: "2 * 3 <? 5", expected: 5},
+		{expression: "2 + 3 <? 1", expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			result, err := Eval(root, test.expression)
+			if err != nil {
+				t.Errorf("Eval() unexpected error: %s", err)
+			} else if result.MustNumeric() != test.expected {
+				t.Errorf("Eval() wrong result: %v, expected %v", result.MustNumeric(), test.expected)
+			}
+		})
+	}
+
+	result, err := JSONPath([]byte(`[{"a": 1, "b": 5}, {"a": 4, "b": 3}, {"a": 2, "b": 2}]`), `$[?(@.a <? @.b >= 2)]`)
+	if err != nil {
+		t.Errorf("JSONPath() unexpected error: %s", err)
+	} else if fullPath(result) != "[$[1], $[2]]" {
+		t.Errorf("JSONPath() wrong result: %s", fullPath(result))
+	}
+}
+
+func TestJSONPathLoose(t *testing.T) {
+	document := []byte(`[{"price":10},{"price":"10"},{"price":" 1e1 "},{"price":"ten"},{"price":"Inf"},{"price":"9"},{"price":true}]`)
+	tests := []struct {
+		name   string
+		path   string
+		strict string
+		loose  string
+	}{
+		{name: "equals", path: `$[?(@.price == 10)]`, strict: "[$[0]]", loose: "[$[0], $[1], $[2]]"},
+		{name: "equals reversed", path: `$[?(10 == @.price)]`, strict: "[$[0]]", loose: "[$[0], $[1], $[2]]"},
+		{name: "not equals", path: `$[?(@.price != 10)]`, strict: "[$[1], $[2], $[3], $[4], $[5], $[6]]", loose: "[$[3], $[4], $[5], $[6]]"},
+		{name: "less", path: `$[?(@.price < 10)]`, strict: "[]", loose: "[$[5]]"},
+		{name: "larger or equals", path: `$[?(@.price >= 10)]`, strict: "[$[0]]", loose: "[$[0], $[1], $[2]]"},
+		{name: "string constant", path: `$[?(@.price == '10')]`, strict: "[$[1]]", loose: "[$[0], $[1]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, loose := range []bool{false, true} {
+				expected, fn := test.strict, JSONPath
+				if loose {
+					expected, fn = test.loose, JSONPathLoose
+				}
+				result, err := fn(document, test.path)
+				if err != nil {
+					t.Errorf("JSONPath() unexpected error: %v", err)
+				} else if fullPath(result) != expected {
+					t.Errorf("Error on JsonPath(json, %s), loose %v: path doesn't match\nExpected: %s\nActual:   %s", test.path, loose, expected, fullPath(result))
+				}
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`{"l": "2", "r": 2}`)))
+	if result, err := Eval(root, "@.l == @.r"); err != nil || result.MustBool() {
+		t.Errorf("Eval() wrong result of the strict comparison: %v, %v", result, err)
+	}
+	if result, err := EvalLoose(root, "@.l == @.r"); err != nil || !result.MustBool() {
+		t.Errorf("EvalLoose() wrong result: %v, %v", result, err)
+	}
+	if _, err := EvalLoose(root, "@.l =="); err == nil {
+		t.Errorf("EvalLoose() expected error")
+	}
+}
+
 func TestFunctions(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -395,6 +779,9 @@ func TestFunctions2(t *testing.T) {
 		{name: "length numeric", fname: "length", value: NumericNode("", 123), result: NumericNode("", 1)},
 		{name: "length bool", fname: "length", value: BoolNode("", false), result: NumericNode("", 1)},
 		{name: "length null", fname: "length", value: NullNode(""), result: NumericNode("", 1)},
+		{name: "index element", fname: "index", value: Must(Unmarshal([]byte(`[1, 2, 3]`))).MustIndex(2), result: NumericNode("", 2)},
+		{name: "index value of object", fname: "index", value: Must(Unmarshal([]byte(`{"a": 1}`))).MustKey("a"), result: NullNode("")},
+		{name: "index root", fname: "index", value: NumericNode("", 1), result: NullNode("")},
 
 		{name: "avg error 1", fname: "avg", value: ArrayNode("test", []*Node{
 			valueNode(nil, "", Numeric, "foo"),
@@ -0,0 +1,92 @@
+package ajson
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Conflict is a description of the node, changed differently in both versions by Merge3.
+// Deleted node presented as nil.
+type Conflict struct {
+	Path   string
+	Base   *Node
+	Ours   *Node
+	Theirs *Node
+}
+
+// Merge3 is a three-way merge of the ours and theirs versions of the base node. Given nodes stay untouched, result is a new tree.
+//
+// Changes present only in one version are applied cleanly. Objects changed in both versions are merged recursively by keys,
+// Arrays - by indexes, if all versions have the same size. Any other changes of the same node in both versions are reported as conflicts,
+// in case of conflict result contains ours version of the node.
+//
+// Result is nil, if root node was deleted.
+func Merge3(base, ours, theirs *Node) (result *Node, conflicts []Conflict) {
+	conflicts = make([]Conflict, 0)
+	result = merge3("$", base, ours, theirs, &conflicts)
+	return result, conflicts
+}
+
+func merge3(path string, base, ours, theirs *Node, conflicts *[]Conflict) *Node {
+	switch {
+	case equalNodes(ours, theirs), equalNodes(base, theirs):
+		return cloneNode(ours)
+	case equalNodes(base, ours):
+		return cloneNode(theirs)
+	case ours != nil && theirs != nil && ours.IsObject() && theirs.IsObject() && (base == nil || base.IsObject()):
+		keys := make(map[string]bool)
+		for _, node := range []*Node{base, ours, theirs} {
+			if node != nil {
+				for key := range node.children {
+					keys[key] = true
+				}
+			}
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		children := make(map[string]*Node, len(sorted))
+		for _, key := range sorted {
+			var child *Node
+			if base != nil {
+				child = base.children[key]
+			}
+			child = merge3(path+"['"+keyReplacer.Replace(key)+"']", child, ours.children[key], theirs.children[key], conflicts)
+			if child != nil {
+				children[key] = child
+			}
+		}
+		return ObjectNode("", children)
+	case ours != nil && theirs != nil && base != nil && ours.IsArray() && theirs.IsArray() && base.IsArray() &&
+		ours.Size() == base.Size() && theirs.Size() == base.Size():
+		children := make([]*Node, 0, base.Size())
+		for i := 0; i < base.Size(); i++ {
+			key := strconv.Itoa(i)
+			child := merge3(path+"["+key+"]", base.children[key], ours.children[key], theirs.children[key], conflicts)
+			if child != nil {
+				children = append(children, child)
+			}
+		}
+		return ArrayNode("", children)
+	}
+	*conflicts = append(*conflicts, Conflict{Path: path, Base: base, Ours: ours, Theirs: theirs})
+	return cloneNode(ours)
+}
+
+// equalNodes returns true if both nodes are nil, or has the same value
+func equalNodes(left, right *Node) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+	result, err := left.Eq(right)
+	return err == nil && result
+}
+
+func cloneNode(node *Node) *Node {
+	if node == nil {
+		return nil
+	}
+	return node.Clone()
+}
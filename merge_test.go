@@ -0,0 +1,93 @@
+package ajson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		expected  string
+		conflicts []string
+	}{
+		{name: "unchanged", base: `{"a":1}`, ours: `{"a":1}`, theirs: `{"a":1}`, expected: `{"a":1}`},
+		{name: "ours scalar", base: `{"a":1,"b":2}`, ours: `{"a":3,"b":2}`, theirs: `{"a":1,"b":2}`, expected: `{"a":3,"b":2}`},
+		{name: "theirs scalar", base: `{"a":1,"b":2}`, ours: `{"a":1,"b":2}`, theirs: `{"a":1,"b":4}`, expected: `{"a":1,"b":4}`},
+		{name: "both different keys", base: `{"a":1,"b":2}`, ours: `{"a":3,"b":2}`, theirs: `{"a":1,"b":4}`, expected: `{"a":3,"b":4}`},
+		{name: "both same change", base: `{"a":1}`, ours: `{"a":2}`, theirs: `{"a":2}`, expected: `{"a":2}`},
+		{name: "added keys", base: `{}`, ours: `{"a":1}`, theirs: `{"b":2}`, expected: `{"a":1,"b":2}`},
+		{name: "deleted keys", base: `{"a":1,"b":2,"c":3}`, ours: `{"b":2,"c":3}`, theirs: `{"a":1,"c":3}`, expected: `{"c":3}`},
+		{name: "nested", base: `{"a":{"b":1,"c":[1,2]}}`, ours: `{"a":{"b":2,"c":[1,2]}}`, theirs: `{"a":{"b":1,"c":[1,2,3]},"d":true}`, expected: `{"a":{"b":2,"c":[1,2,3]},"d":true}`},
+		{name: "arrays by index", base: `[1,2,3]`, ours: `[0,2,3]`, theirs: `[1,2,4]`, expected: `[0,2,4]`},
+		{name: "root scalar", base: `1`, ours: `1`, theirs: `2`, expected: `2`},
+		{name: "conflict scalar", base: `{"a":1,"b":1}`, ours: `{"a":2,"b":1}`, theirs: `{"a":3,"b":2}`, expected: `{"a":2,"b":2}`, conflicts: []string{"$['a']"}},
+		{name: "conflict added", base: `{}`, ours: `{"a":1}`, theirs: `{"a":2}`, expected: `{"a":1}`, conflicts: []string{"$['a']"}},
+		{name: "conflict deleted", base: `{"a":1}`, ours: `{}`, theirs: `{"a":2}`, expected: `{}`, conflicts: []string{"$['a']"}},
+		{name: "conflict type", base: `{"a":{"b":1}}`, ours: `{"a":{"b":2}}`, theirs: `{"a":[1]}`, expected: `{"a":{"b":2}}`, conflicts: []string{"$['a']"}},
+		{name: "conflict quoted key", base: `{"it's":1}`, ours: `{"it's":2}`, theirs: `{"it's":3}`, expected: `{"it's":2}`, conflicts: []string{`$['it\'s']`}},
+		{name: "conflict arrays", base: `[1]`, ours: `[1,2]`, theirs: `[1,3]`, expected: `[1,2]`, conflicts: []string{"$"}},
+		{name: "conflict nested", base: `{"a":[{"b":1}]}`, ours: `{"a":[{"b":2}]}`, theirs: `{"a":[{"b":3}]}`, expected: `{"a":[{"b":2}]}`, conflicts: []string{"$['a'][0]['b']"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base := Must(Unmarshal([]byte(test.base)))
+			ours := Must(Unmarshal([]byte(test.ours)))
+			theirs := Must(Unmarshal([]byte(test.theirs)))
+			result, conflicts := Merge3(base, ours, theirs)
+			if result == nil {
+				t.Errorf("Merge3() result is nil")
+				return
+			}
+			actual, err := result.Unpack()
+			if err != nil {
+				t.Errorf("Unpack() error: %s", err)
+				return
+			}
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Merge3() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+			paths := make([]string, 0, len(conflicts))
+			for _, conflict := range conflicts {
+				paths = append(paths, conflict.Path)
+			}
+			if test.conflicts == nil {
+				test.conflicts = []string{}
+			}
+			if !sliceEqual(paths, test.conflicts) {
+				t.Errorf("Merge3() wrong conflicts:\nExpected: %s\nActual:   %s", sliceString(test.conflicts), sliceString(paths))
+			}
+			for _, node := range []*Node{base, ours, theirs} {
+				if node.IsDirty() {
+					t.Errorf("Merge3() changed the given node")
+				}
+			}
+		})
+	}
+}
+
+func TestMerge3_conflict(t *testing.T) {
+	base := Must(Unmarshal([]byte(`{"a":1}`)))
+	ours := Must(Unmarshal([]byte(`{}`)))
+	theirs := Must(Unmarshal([]byte(`{"a":"foo"}`)))
+	_, conflicts := Merge3(base, ours, theirs)
+	if len(conflicts) != 1 {
+		t.Errorf("Merge3() wrong count of conflicts: %d", len(conflicts))
+		return
+	}
+	conflict := conflicts[0]
+	if conflict.Base != base.MustKey("a") || conflict.Ours != nil || conflict.Theirs != theirs.MustKey("a") {
+		t.Errorf("Merge3() wrong conflict: %#+v", conflict)
+	}
+}
+
+func TestMerge3_deleted(t *testing.T) {
+	result, conflicts := Merge3(Must(Unmarshal([]byte(`1`))), nil, Must(Unmarshal([]byte(`1`))))
+	if result != nil || len(conflicts) != 0 {
+		t.Errorf("Merge3() root was not deleted: %v, %v", result, conflicts)
+	}
+}
@@ -0,0 +1,59 @@
+package ajson
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NDJSONOption is an option of reading the newline delimited JSON in NDJSONPath
+type NDJSONOption int
+
+const (
+	// NDJSONSkipMalformed means that malformed lines are skipped instead of the error, all of them are reported in the error after the last line
+	NDJSONSkipMalformed NDJSONOption = iota
+)
+
+// NDJSONPath applies the JSONPath to the document of each line of the newline delimited JSON (JSON Lines) data independently,
+// and returns the founded elements per line: result[i] is the result for the line i+1. Blank lines have nil result.
+//
+// Malformed line stops the reading with an error, which contains the number of the line. With the NDJSONSkipMalformed option
+// such lines have nil result, and the results of all the lines are returned with an error, which contains numbers and errors of the malformed lines.
+func NDJSONPath(data []byte, path string, options ...NDJSONOption) (result [][]*Node, err error) {
+	var skip bool
+	for _, option := range options {
+		if option == NDJSONSkipMalformed {
+			skip = true
+		}
+	}
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.Split(data, []byte{skipN})
+	if len(lines[len(lines)-1]) == 0 { // the last line ends with the new line, or there is no data
+		lines = lines[:len(lines)-1]
+	}
+	result = make([][]*Node, len(lines))
+	failed := make([]string, 0)
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		node, err := Unmarshal(line)
+		if err == nil {
+			result[i], err = deReference(context.Background(), node, commands)
+		}
+		if err != nil {
+			if !skip {
+				return nil, errorRequest("line %d: %s", i+1, err)
+			}
+			failed = append(failed, fmt.Sprintf("line %d: %s", i+1, err))
+		}
+	}
+	if len(failed) != 0 {
+		return result, errorRequest("malformed lines: %s", strings.Join(failed, "; "))
+	}
+	return result, nil
+}
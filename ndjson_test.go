@@ -0,0 +1,77 @@
+package ajson
+
+import "testing"
+
+func TestNDJSONPath(t *testing.T) {
+	data := []byte(`{"level": "info", "msg": "started", "ctx": {"user": "foo"}}
+{"level": "error", "msg": "failed", "ctx": {"user": "bar", "code": 500}}
+
+{"level": "debug"}
+{"level": "info", "msg": "done"}
+`)
+	result, err := NDJSONPath(data, "$.msg")
+	if err != nil {
+		t.Errorf("NDJSONPath() unexpected error: %s", err)
+		return
+	}
+	expected := []string{"started", "failed", "", "", "done"}
+	if len(result) != len(expected) {
+		t.Errorf("NDJSONPath() wrong count of lines: %d", len(result))
+		return
+	}
+	for i, nodes := range result {
+		if expected[i] == "" {
+			if len(nodes) != 0 {
+				t.Errorf("NDJSONPath() unexpected result of the line %d: %s", i+1, fullPath(nodes))
+			}
+			continue
+		}
+		if len(nodes) != 1 || nodes[0].MustString() != expected[i] || nodes[0].Path() != "$['msg']" {
+			t.Errorf("NDJSONPath() wrong result of the line %d: %s", i+1, fullPath(nodes))
+		}
+	}
+	if result[2] != nil || result[3] == nil {
+		t.Errorf("NDJSONPath() blank line should have nil result, and the line without matches an empty one")
+	}
+
+	result, err = NDJSONPath([]byte("[1, 2]\r\n[3]\r\n[]"), "$[*]")
+	if err != nil || len(result) != 3 || len(result[0]) != 2 || len(result[1]) != 1 || len(result[2]) != 0 {
+		t.Errorf("NDJSONPath() wrong result with CRLF: %v, %v", result, err)
+	}
+}
+
+func TestNDJSONPath_malformed(t *testing.T) {
+	data := []byte(`{"id": 1}
+{"id": 2
+{"id": 3}
+not json
+{"id": 5}`)
+	if _, err := NDJSONPath(data, "$.id"); err == nil || err.Error() != "wrong request: line 2: unexpected end of file" {
+		t.Errorf("NDJSONPath() wrong error: %v", err)
+	}
+
+	result, err := NDJSONPath(data, "$.id", NDJSONSkipMalformed)
+	if err == nil || err.Error() != "wrong request: malformed lines: line 2: unexpected end of file; line 4: wrong symbol 'o' at 1" {
+		t.Errorf("NDJSONPath() wrong error: %v", err)
+	}
+	if len(result) != 5 {
+		t.Errorf("NDJSONPath() wrong count of lines: %d", len(result))
+		return
+	}
+	for i, id := range []float64{1, 0, 3, 0, 5} {
+		if id == 0 {
+			if result[i] != nil {
+				t.Errorf("NDJSONPath() malformed line %d should have nil result", i+1)
+			}
+		} else if len(result[i]) != 1 || result[i][0].MustNumeric() != id {
+			t.Errorf("NDJSONPath() wrong result of the line %d: %s", i+1, fullPath(result[i]))
+		}
+	}
+
+	if _, err = NDJSONPath(data, "$.id[", NDJSONSkipMalformed); err == nil {
+		t.Errorf("NDJSONPath() expected error on wrong path")
+	}
+	if result, err = NDJSONPath(nil, "$"); err != nil || len(result) != 0 {
+		t.Errorf("NDJSONPath() wrong result of the empty data: %v, %v", result, err)
+	}
+}
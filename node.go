@@ -1,10 +1,15 @@
 package ajson
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
 	"sort"
 	"strconv"
+	"strings"
 	"sync/atomic"
 )
 
@@ -22,9 +27,13 @@ import (
 //
 // Every type has its own methods to be called.
 // Every Node contains link to a byte data, parent and children, also calculated type of value, atomic value and internal information.
+//
+// Read-only methods (getters, JSONPath, Eval, Marshal, etc.) are safe for concurrent use, until the tree is modified.
+// Any mutation of the tree, while it is being read from another goroutine, is a data race: use Node.Freeze to prevent it.
 type Node struct {
 	parent   *Node
 	children map[string]*Node
+	order    []string // keys of Object in order of insertion, see Node.orderedKeys
 	key      *string
 	index    *int
 	_type    NodeType
@@ -32,6 +41,7 @@ type Node struct {
 	borders  [2]int
 	value    atomic.Value
 	dirty    bool
+	frozen   bool
 }
 
 // NodeType is a kind of reflection of JSON type to a type of golang
@@ -116,6 +126,9 @@ func ArrayNode(key string, value []*Node) (current *Node) {
 		current.value.Store(value)
 		for i, val := range value {
 			var index = i
+			if val.IsMissing() {
+				val = newMissingNode()
+			}
 			current.children[strconv.Itoa(i)] = val
 			val.parent = current
 			val.index = &index
@@ -135,9 +148,15 @@ func ObjectNode(key string, value map[string]*Node) (current *Node) {
 	if value != nil {
 		current.value.Store(value)
 		for key, val := range value {
+			var name = key
+			if val.IsMissing() {
+				val = newMissingNode()
+				value[key] = val
+			}
 			val.parent = current
-			val.key = &key
+			val.key = &name
 		}
+		current.order = sortedKeys(value)
 	} else {
 		current.children = make(map[string]*Node)
 	}
@@ -165,16 +184,67 @@ func newNode(parent *Node, buf *buffer, _type NodeType, key **string) (current *
 			if *key == nil {
 				err = errorSymbol(buf)
 			} else {
+				if _, ok := parent.children[**key]; !ok {
+					parent.order = append(parent.order, **key)
+				}
 				parent.children[**key] = current
 				*key = nil
 			}
 		} else {
 			err = errorSymbol(buf)
 		}
+		if err == nil && buf.limit > 0 && len(parent.children) > buf.limit {
+			err = errorSymbol(buf) // element over the limit is not allowed
+		}
 	}
 	return
 }
 
+// interfaceNode returns a new root node with the value of the native Go type: nil, bool, string, numbers, []interface{} and map[string]interface{}.
+// Values of other types are converted with json.Marshal.
+func interfaceNode(value interface{}) (current *Node, err error) {
+	switch value := value.(type) {
+	case nil:
+		current = NullNode("")
+	case bool:
+		current = BoolNode("", value)
+	case string:
+		current = StringNode("", value)
+	case float64:
+		current = NumericNode("", value)
+	case float32:
+		current = NumericNode("", float64(value))
+	case int:
+		current = NumericNode("", float64(value))
+	case int64:
+		current = NumericNode("", float64(value))
+	case []interface{}:
+		nodes := make([]*Node, len(value))
+		for i, element := range value {
+			if nodes[i], err = interfaceNode(element); err != nil {
+				return nil, err
+			}
+		}
+		current = ArrayNode("", nodes)
+	case map[string]interface{}:
+		nodes := make(map[string]*Node, len(value))
+		for key, element := range value {
+			if nodes[key], err = interfaceNode(element); err != nil {
+				return nil, err
+			}
+		}
+		current = ObjectNode("", nodes)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return Unmarshal(data)
+	}
+	current.key = nil // root node
+	return current, nil
+}
+
 func valueNode(parent *Node, key string, _type NodeType, value interface{}) (current *Node) {
 	current = &Node{
 		parent:  parent,
@@ -195,7 +265,8 @@ func (n *Node) Parent() *Node {
 	return n.parent
 }
 
-// Source returns slice of bytes, which was identified to be current node
+// Source returns slice of bytes, which was identified to be current node.
+// It is the exact part of the original data, with all the whitespaces and numeric precision, or nil for the created and changed nodes.
 func (n *Node) Source() []byte {
 	if n.ready() && !n.dirty {
 		return (*n.data)[n.borders[0]:n.borders[1]]
@@ -215,6 +286,26 @@ func (n *Node) String() string {
 	return "null"
 }
 
+// Bytes returns current value marshaled as a compact JSON, or nil if the value can't be marshaled (use Marshal to get the error)
+func (n *Node) Bytes() []byte {
+	result, err := Marshal(n)
+	if err != nil {
+		return nil
+	}
+	return compact(result)
+}
+
+// Hash returns SHA-256 of the canonical form of current value, see Canonicalize,
+// so the equal values have the same hash regardless of the keys order and formatting.
+// Error is returned if the value can't be canonicalized, like non-finite numbers or the missing node.
+func (n *Node) Hash() (hash [32]byte, err error) {
+	result, err := n.Canonicalize()
+	if err != nil {
+		return hash, err
+	}
+	return sha256.Sum256(result), nil
+}
+
 // Type will return type of current node
 func (n *Node) Type() NodeType {
 	return n._type
@@ -235,13 +326,73 @@ func (n *Node) Size() int {
 	return len(n.children)
 }
 
-// Keys will return count all keys of children of current node, please check, that parent of this node has an Object type
-func (n *Node) Keys() (result []string) {
-	result = make([]string, 0, len(n.children))
+// Depth will return the maximum nesting depth of current node: 0 for scalars, 1 for Array or Object of scalars, etc.
+func (n *Node) Depth() (result int) {
+	if !n.IsContainer() {
+		return 0
+	}
+	for _, child := range n.children {
+		if depth := child.Depth(); depth > result {
+			result = depth
+		}
+	}
+	return result + 1
+}
+
+// Keys returns keys of children of current node in order of insertion: for Object node parsed keys are in order of the source,
+// new keys are appended to the end; for Array node these are indexes of the elements in order. See KeysSorted for the sorted keys.
+func (n *Node) Keys() []string {
+	if n.IsArray() {
+		result := make([]string, len(n.children))
+		for i := range result {
+			result[i] = strconv.Itoa(i)
+		}
+		return result
+	}
+	return n.orderedKeys()
+}
+
+// KeysSorted returns keys of children of current Object node sorted lexicographically, like in the canonical output.
+// For Array node it is the same as Keys.
+func (n *Node) KeysSorted() []string {
+	if n.IsArray() {
+		return n.Keys()
+	}
+	return sortedKeys(n.children)
+}
+
+// orderedKeys returns keys of current Object node in order of insertion: parsed keys are in order of the source,
+// new keys are appended to the end. Keys without known position follow them, sorted.
+func (n *Node) orderedKeys() []string {
+	result := make([]string, 0, len(n.children))
+	seen := make(map[string]bool, len(n.children))
+	for _, key := range n.order {
+		if _, ok := n.children[key]; ok && !seen[key] {
+			seen[key] = true
+			result = append(result, key)
+		}
+	}
+	if len(result) == len(n.children) {
+		return result
+	}
+	rest := make([]string, 0, len(n.children)-len(result))
 	for key := range n.children {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(result, rest...)
+}
+
+// sortedKeys returns sorted keys of the map
+func sortedKeys(nodes map[string]*Node) []string {
+	result := make([]string, 0, len(nodes))
+	for key := range nodes {
 		result = append(result, key)
 	}
-	return
+	sort.Strings(result)
+	return result
 }
 
 // IsArray returns true if current node is Array
@@ -264,6 +415,16 @@ func (n *Node) IsNumeric() bool {
 	return n._type == Numeric
 }
 
+// IsInt returns true if current node is Numeric without the fractional part, as the JSON Schema "integer" type:
+// `5`, `5.0` and `5e2` are integers, while `5.5` is not. Integers out of the int64 range are integers too, use GetInt to check the range.
+func (n *Node) IsInt() bool {
+	if n._type != Numeric {
+		return false
+	}
+	value, err := n.GetNumeric()
+	return err == nil && !math.IsInf(value, 0) && value == math.Trunc(value)
+}
+
 // IsString returns true if current node is String
 func (n *Node) IsString() bool {
 	return n._type == String
@@ -274,6 +435,16 @@ func (n *Node) IsBool() bool {
 	return n._type == Bool
 }
 
+// IsContainer returns true if current node is Array or Object
+func (n *Node) IsContainer() bool {
+	return n._type == Array || n._type == Object
+}
+
+// IsLeaf returns true if current node is not a container: Null, Numeric, String or Bool
+func (n *Node) IsLeaf() bool {
+	return !n.IsContainer()
+}
+
 // Value is calculating and returns a value of current node.
 //
 // It returns nil, if current node type is Null.
@@ -306,7 +477,7 @@ func (n *Node) Value() (value interface{}, err error) {
 	case Object:
 		return n.GetObject()
 	}
-	return nil, errorType()
+	return nil, n.typeError()
 }
 
 func (n *Node) getValue() (value interface{}, err error) {
@@ -357,7 +528,7 @@ func (n *Node) getValue() (value interface{}, err error) {
 // GetNull returns nil, if current type is Null, else: WrongType error
 func (n *Node) GetNull() (interface{}, error) {
 	if n._type != Null {
-		return nil, errorType()
+		return nil, n.typeError()
 	}
 	return nil, nil
 }
@@ -365,7 +536,7 @@ func (n *Node) GetNull() (interface{}, error) {
 // GetNumeric returns float64, if current type is Numeric, else: WrongType error
 func (n *Node) GetNumeric() (value float64, err error) {
 	if n._type != Numeric {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	iValue, err := n.getValue()
 	if err != nil {
@@ -373,7 +544,7 @@ func (n *Node) GetNumeric() (value float64, err error) {
 	}
 	value, ok := iValue.(float64)
 	if !ok {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	return value, nil
 }
@@ -381,7 +552,7 @@ func (n *Node) GetNumeric() (value float64, err error) {
 // GetString returns string, if current type is String, else: WrongType error
 func (n *Node) GetString() (value string, err error) {
 	if n._type != String {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	iValue, err := n.getValue()
 	if err != nil {
@@ -389,7 +560,7 @@ func (n *Node) GetString() (value string, err error) {
 	}
 	value, ok := iValue.(string)
 	if !ok {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	return value, nil
 }
@@ -397,7 +568,7 @@ func (n *Node) GetString() (value string, err error) {
 // GetBool returns bool, if current type is Bool, else: WrongType error
 func (n *Node) GetBool() (value bool, err error) {
 	if n._type != Bool {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	iValue, err := n.getValue()
 	if err != nil {
@@ -405,15 +576,70 @@ func (n *Node) GetBool() (value bool, err error) {
 	}
 	value, ok := iValue.(bool)
 	if !ok {
-		return value, errorType()
+		return value, n.typeError()
+	}
+	return value, nil
+}
+
+// BigInt returns big.Int, if current type is Numeric and the value is an integer, like `1e3`, else: WrongType or WrongRequest error.
+// The value is parsed from the source of the node, so it has no precision loss of float64, e.g. for `123456789012345678901234567890`.
+// Modified nodes have no source, their float64 value is used.
+func (n *Node) BigInt() (*big.Int, error) {
+	literal, err := n.numericLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := new(big.Int).SetString(literal, 10); ok {
+		return value, nil
+	}
+	rat, ok := new(big.Rat).SetString(literal)
+	if !ok || !rat.IsInt() {
+		return nil, errorRequest("node is not INT")
+	}
+	return rat.Num(), nil
+}
+
+// BigFloat returns big.Float, if current type is Numeric, else: WrongType error. The value is parsed from the source of the node
+// with the precision enough for all of its digits, so it has no precision loss of float64, e.g. for `0.1000000000000000000000000001`.
+// Modified nodes have no source, their float64 value is used.
+func (n *Node) BigFloat() (*big.Float, error) {
+	literal, err := n.numericLiteral()
+	if err != nil {
+		return nil, err
+	}
+	prec := uint(len(literal)) * 4 // more than log2(10) bits per digit
+	if prec < 64 {
+		prec = 64
+	}
+	value, _, err := big.ParseFloat(literal, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, errorRequest("wrong number: %s", literal)
 	}
 	return value, nil
 }
 
+// numericLiteral returns the source of current Numeric node, or its formatted float64 value for the modified one
+func (n *Node) numericLiteral() (string, error) {
+	if n._type != Numeric {
+		return "", n.typeError()
+	}
+	if n.ready() && !n.dirty {
+		return string(n.Source()), nil
+	}
+	value, err := n.GetNumeric()
+	if err != nil {
+		return "", err
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return "", errorRequest("non-finite number: %v", value)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64), nil
+}
+
 // GetArray returns []*Node, if current type is Array, else: WrongType error
 func (n *Node) GetArray() (value []*Node, err error) {
 	if n._type != Array {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	iValue, err := n.getValue()
 	if err != nil {
@@ -421,7 +647,7 @@ func (n *Node) GetArray() (value []*Node, err error) {
 	}
 	value, ok := iValue.([]*Node)
 	if !ok {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	return value, nil
 }
@@ -429,7 +655,7 @@ func (n *Node) GetArray() (value []*Node, err error) {
 // GetObject returns map[string]*Node, if current type is Object, else: WrongType error
 func (n *Node) GetObject() (value map[string]*Node, err error) {
 	if n._type != Object {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	iValue, err := n.getValue()
 	if err != nil {
@@ -437,11 +663,64 @@ func (n *Node) GetObject() (value map[string]*Node, err error) {
 	}
 	value, ok := iValue.(map[string]*Node)
 	if !ok {
-		return value, errorType()
+		return value, n.typeError()
 	}
 	return value, nil
 }
 
+// GetInt returns int64, if current type is Numeric and it's value is an integer in the int64 range, else: error.
+// Original source is used for the parsed nodes, so big integers don't lose their precision.
+func (n *Node) GetInt() (int64, error) {
+	if n._type != Numeric {
+		return 0, n.typeError()
+	}
+	if source := n.Source(); source != nil {
+		if value, err := strconv.ParseInt(string(source), 10, 64); err == nil {
+			return value, nil
+		}
+	}
+	float, err := n.GetNumeric()
+	if err != nil {
+		return 0, err
+	}
+	if math.Trunc(float) != float && !math.IsInf(float, 0) {
+		return 0, errorRequest("node is not INT")
+	}
+	if float < -(1<<63) || float >= 1<<63 {
+		return 0, errorRequest("node is out of INT64 range")
+	}
+	return int64(float), nil
+}
+
+// GetUint returns uint64, if current type is Numeric and it's value is a non-negative integer in the uint64 range, else: error.
+// Original source is used for the parsed nodes, so big integers don't lose their precision.
+func (n *Node) GetUint() (uint64, error) {
+	if n._type != Numeric {
+		return 0, n.typeError()
+	}
+	if source := n.Source(); source != nil {
+		if value, err := strconv.ParseUint(string(source), 10, 64); err == nil {
+			return value, nil
+		}
+	}
+	float, err := n.GetNumeric()
+	if err != nil {
+		return 0, err
+	}
+	if math.Trunc(float) != float && !math.IsInf(float, 0) {
+		return 0, errorRequest("node is not UINT")
+	}
+	if float < 0 || float >= 1<<64 {
+		return 0, errorRequest("node is out of UINT64 range")
+	}
+	return uint64(float), nil
+}
+
+// GetFloat returns float64, if current type is Numeric, else: WrongType error. The same as GetNumeric.
+func (n *Node) GetFloat() (float64, error) {
+	return n.GetNumeric()
+}
+
 // MustNull returns nil, if current type is Null, else: panic if error happened
 func (n *Node) MustNull() (value interface{}) {
 	value, err := n.GetNull()
@@ -496,6 +775,41 @@ func (n *Node) MustObject() (value map[string]*Node) {
 	return
 }
 
+// StringOr returns string, if current type is String, else: the default value, e.g. for nil, Null or the missing node.
+//
+//	name := root.Opt("config").Opt("name").StringOr("default")
+func (n *Node) StringOr(def string) string {
+	if n == nil {
+		return def
+	}
+	if value, err := n.GetString(); err == nil {
+		return value
+	}
+	return def
+}
+
+// NumericOr returns float64, if current type is Numeric, else: the default value, see StringOr
+func (n *Node) NumericOr(def float64) float64 {
+	if n == nil {
+		return def
+	}
+	if value, err := n.GetNumeric(); err == nil {
+		return value
+	}
+	return def
+}
+
+// BoolOr returns bool, if current type is Bool, else: the default value, see StringOr
+func (n *Node) BoolOr(def bool) bool {
+	if n == nil {
+		return def
+	}
+	if value, err := n.GetBool(); err == nil {
+		return value
+	}
+	return def
+}
+
 // Unpack will produce current node to it's interface, recursively with all underlying nodes (in contrast to Node.Value).
 func (n *Node) Unpack() (value interface{}, err error) {
 	switch n._type {
@@ -535,14 +849,49 @@ func (n *Node) Unpack() (value interface{}, err error) {
 			}
 		}
 		value = result
+	default:
+		return nil, n.typeError()
 	}
 	return
 }
 
+// AsMap will produce current Object node to the map[string]interface{}, recursively with all underlying nodes. If current node is not Object, will return error
+func (n *Node) AsMap() (value map[string]interface{}, err error) {
+	if n._type != Object {
+		return nil, n.typeError()
+	}
+	iValue, err := n.Unpack()
+	if err != nil {
+		return nil, err
+	}
+	return iValue.(map[string]interface{}), nil
+}
+
+// AsSlice will produce current Array node to the []interface{}, recursively with all underlying nodes. If current node is not Array, will return error
+func (n *Node) AsSlice() (value []interface{}, err error) {
+	if n._type != Array {
+		return nil, n.typeError()
+	}
+	iValue, err := n.Unpack()
+	if err != nil {
+		return nil, err
+	}
+	return iValue.([]interface{}), nil
+}
+
+// Unmarshal decodes current node into the value pointed to by v, with the same rules as json.Unmarshal does
+func (n *Node) Unmarshal(v interface{}) error {
+	data, err := Marshal(n)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
 // GetIndex will return child node of current array node. If current node is not Array, or index is unavailable, will return error
 func (n *Node) GetIndex(index int) (*Node, error) {
 	if n._type != Array {
-		return nil, errorType()
+		return nil, n.typeError()
 	}
 	if index < 0 {
 		index += len(n.children)
@@ -566,7 +915,7 @@ func (n *Node) MustIndex(index int) (value *Node) {
 // GetKey will return child node of current object node. If current node is not Object, or key is unavailable, will return error
 func (n *Node) GetKey(key string) (*Node, error) {
 	if n._type != Object {
-		return nil, errorType()
+		return nil, n.typeError()
 	}
 	value, ok := n.children[key]
 	if !ok {
@@ -584,18 +933,126 @@ func (n *Node) MustKey(key string) (value *Node) {
 	return
 }
 
+// Get will return the node by the simplified path from current node: keys separated by dots and array indices in brackets, like `a.b[2].c`.
+// Wildcards, filters and quoted keys are not supported, use JSONPath for them. If the node is unavailable, will return error
+func (n *Node) Get(path string) (*Node, error) {
+	current := n
+	for pos := 0; pos < len(path); {
+		var err error
+		if path[pos] == bracketL {
+			end := strings.IndexByte(path[pos:], bracketR)
+			if end < 0 {
+				return nil, errorRequest("wrong path: %s", path)
+			}
+			index, cerr := strconv.Atoi(path[pos+1 : pos+end])
+			if cerr != nil {
+				return nil, errorRequest("wrong path: %s", path)
+			}
+			current, err = current.GetIndex(index)
+			pos += end + 1
+		} else {
+			if pos > 0 {
+				if path[pos] != dot {
+					return nil, errorRequest("wrong path: %s", path)
+				}
+				pos++
+			}
+			end := pos
+			for end < len(path) && path[end] != dot && path[end] != bracketL {
+				end++
+			}
+			if end == pos {
+				return nil, errorRequest("wrong path: %s", path)
+			}
+			current, err = current.GetKey(path[pos:end])
+			pos = end
+		}
+		if err != nil {
+			return nil, errorRequest("path not found: %s", path)
+		}
+	}
+	return current, nil
+}
+
+// Coalesce will return the first found node, which is not Null, by the simplified paths from current node, the same as in Get, e.g. `displayName`, `profile.name`.
+// Missing nodes and wrong paths are skipped, if nothing is found returns nil
+func (n *Node) Coalesce(paths ...string) *Node {
+	for _, path := range paths {
+		if node, err := n.Get(path); err == nil && !node.IsNull() {
+			return node
+		}
+	}
+	return nil
+}
+
 // HasKey will return boolean value, if current object node has custom key
 func (n *Node) HasKey(key string) bool {
 	_, ok := n.children[key]
 	return ok
 }
 
+// missingType is the type of the missing node, see Node.Opt
+const missingType NodeType = -1
+
+// missingNode is the result of Node.Opt and Node.OptIndex for the absent elements
+var missingNode = newMissingNode()
+
+// newMissingNode returns the new missing node. Shared missingNode is never attached to the containers, the copy of it is used instead.
+func newMissingNode() *Node {
+	return &Node{_type: missingType, frozen: true}
+}
+
+// Opt will return child node of current object node by key, or the missing node, if current node is not Object, or key is unavailable.
+//
+// Missing node has no type and no children: every Is* method returns false, Opt and OptIndex return the missing node,
+// every getter (GetString, Value, Unpack, GetKey, etc.) returns an error of the Missing type, and Must* methods will panic.
+// Missing node is frozen, so mutation methods return an error too. Use IsMissing to check the result, e.g.:
+//
+//	value, err := root.Opt("foo").Opt("bar").OptIndex(0).GetString()
+//
+func (n *Node) Opt(key string) *Node {
+	if n._type == Object {
+		if child, ok := n.children[key]; ok {
+			return child
+		}
+	}
+	return missingNode
+}
+
+// OptIndex will return child node of current array node by index, or the missing node, if current node is not Array, or index is unavailable.
+// Negative index is counted from the end of Array. See Opt for the details.
+func (n *Node) OptIndex(index int) *Node {
+	if n._type == Array {
+		if index < 0 {
+			index += len(n.children)
+		}
+		if child, ok := n.children[strconv.Itoa(index)]; ok {
+			return child
+		}
+	}
+	return missingNode
+}
+
+// IsMissing returns true if current node is the missing node, returned by Opt or OptIndex, or its copy
+func (n *Node) IsMissing() bool {
+	return n != nil && n._type == missingType
+}
+
+// typeError returns Missing error for the missing node, or WrongType error otherwise
+func (n *Node) typeError() error {
+	if n.IsMissing() {
+		return errorMissing()
+	}
+	return errorType()
+}
+
 // Empty method check if current container node has no children
 func (n *Node) Empty() bool {
 	return len(n.children) == 0
 }
 
-// Path returns full JsonPath of current Node
+// Path returns full JsonPath of current Node in the bracket-notation, like `$['store']['book'][0]['title']`.
+// Keys are always quoted, so the result can be used in JSONPath for the keys with any characters.
 func (n *Node) Path() string {
 	if n.parent == nil {
 		if n.key == nil {
@@ -603,10 +1060,66 @@ func (n *Node) Path() string {
 		}
 		return n.Key()
 	}
+	return n.parent.Path() + n.pathSegment()
+}
+
+// pathSegment returns the last part of the current Node JsonPath: quoted key with escaped quotes and backslashes, or index
+func (n *Node) pathSegment() string {
 	if n.key != nil {
-		return n.parent.Path() + "['" + n.Key() + "']"
+		return "['" + keyReplacer.Replace(n.Key()) + "']"
 	}
-	return n.parent.Path() + "[" + strconv.Itoa(n.Index()) + "]"
+	return "[" + strconv.Itoa(n.Index()) + "]"
+}
+
+var keyReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// WalkPath calls fn for current node and all its descendants in depth-first order (children in order of Inheritors),
+// with the JsonPath of each node, the same as the Path returns. Walking stops on the first error returned by fn.
+func (n *Node) WalkPath(fn func(path string, node *Node) error) error {
+	return n.walkPath(n.Path(), fn)
+}
+
+func (n *Node) walkPath(path string, fn func(path string, node *Node) error) error {
+	if err := fn(path, n); err != nil {
+		return err
+	}
+	for _, child := range n.Inheritors() {
+		if err := child.walkPath(path+child.pathSegment(), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllKeys returns sorted distinct keys of all the objects in the tree of the root node
+func AllKeys(root *Node) []string {
+	unique := make(map[string]bool)
+	_ = root.WalkPath(func(_ string, node *Node) error {
+		if node.IsObject() {
+			for key := range node.children {
+				unique[key] = true
+			}
+		}
+		return nil
+	})
+	result := make([]string, 0, len(unique))
+	for key := range unique {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// AllPaths returns paths of all the leaf nodes in the tree of the root node, in the order of Node.WalkPath
+func AllPaths(root *Node) []string {
+	result := make([]string, 0)
+	_ = root.WalkPath(func(path string, node *Node) error {
+		if node.IsLeaf() {
+			result = append(result, path)
+		}
+		return nil
+	})
+	return result
 }
 
 // Eq check if nodes value are the same
@@ -683,6 +1196,51 @@ func (n *Node) Neq(node *Node) (result bool, err error) {
 	return !result, err
 }
 
+// Contains returns true if subset is structurally contained in current node: each key of the subset Object exists in current Object node
+// and its value is contained in the value of current node, recursively. Each element of the subset Array is contained in any element
+// of current Array node, regardless of the order, so `[1,2,3]` contains `[3,1]` and `[{"id":1,"tags":["a","b"]}]` contains `[{"tags":["b"]}]`.
+// Scalar values are compared with Eq. Returns false for the nodes with the wrong values.
+func (n *Node) Contains(subset *Node) bool {
+	if n == nil || subset == nil || n.Type() != subset.Type() {
+		return false
+	}
+	switch n.Type() {
+	case Array:
+		elements, subsets, err := _arrays(n, subset)
+		if err != nil {
+			return false
+		}
+		for _, expected := range subsets {
+			found := false
+			for _, element := range elements {
+				if element.Contains(expected) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case Object:
+		elements, subsets, err := _objects(n, subset)
+		if err != nil {
+			return false
+		}
+		for key, expected := range subsets {
+			element, ok := elements[key]
+			if !ok || !element.Contains(expected) {
+				return false
+			}
+		}
+		return true
+	default:
+		result, err := n.Eq(subset)
+		return err == nil && result
+	}
+}
+
 // Le check if nodes value is lesser than given
 func (n *Node) Le(node *Node) (result bool, err error) {
 	if n.Type() == node.Type() {
@@ -775,12 +1333,21 @@ func (n *Node) Geq(node *Node) (result bool, err error) {
 	return
 }
 
-func (n *Node) ready() bool {
-	return n.borders[1] != 0
+// Less check if nodes value is lesser than given, with the same rules as the operator `<` of the JSONPath script in the strict comparison.
+// Only the Numeric and the String values can be compared, values of the different types are not lesser than each other.
+func (n *Node) Less(other *Node) (bool, error) {
+	return n.Le(other)
 }
 
-func (n *Node) isContainer() bool {
-	return n._type == Array || n._type == Object
+// Equals check if nodes value are the same, with the same rules as the operator `==` of the JSONPath script in the strict comparison.
+// Values of the different types are not equal, the errors of the value calculation are reported as false.
+func (n *Node) Equals(other *Node) bool {
+	result, err := n.Eq(other)
+	return err == nil && result
+}
+
+func (n *Node) ready() bool {
+	return n.borders[1] != 0
 }
 
 func (n *Node) getInteger() (int, error) {
@@ -808,16 +1375,25 @@ func (n *Node) getUInteger() (uint, error) {
 	return uint(result), nil
 }
 
-// Inheritors return sorted by keys/index slice of children
-func (n *Node) Inheritors() (result []*Node) {
+// Inheritors return slice of children in order of Keys: for Object node in order of insertion, for Array node in order of indexes.
+// Find, FindAll, WalkPath, TransformScalars and ToYAML iterate the children in the same order. See InheritorsSorted for the sorted keys.
+func (n *Node) Inheritors() []*Node {
+	return n.inheritors(n.orderedKeys)
+}
+
+// InheritorsSorted return sorted by keys/index slice of children, like KeysSorted. JSONPath selects the children in this order.
+func (n *Node) InheritorsSorted() []*Node {
+	return n.inheritors(func() []string {
+		return sortedKeys(n.children)
+	})
+}
+
+// inheritors returns children of Object node in order of the keys, or children of Array node in order of indexes
+func (n *Node) inheritors(keys func() []string) (result []*Node) {
 	size := len(n.children)
 	if n.IsObject() {
 		result = make([]*Node, size)
-		keys := n.Keys()
-		sort.Slice(keys, func(i, j int) bool {
-			return keys[i] < keys[j]
-		})
-		for i, key := range keys {
+		for i, key := range keys() {
 			result[i] = n.children[key]
 		}
 	} else if n.IsArray() {
@@ -829,13 +1405,223 @@ func (n *Node) Inheritors() (result []*Node) {
 	return
 }
 
+// Find returns the first descendant of current node, which satisfies the fn, in depth-first order (children in order of Inheritors), or nil if nothing found
+func (n *Node) Find(fn func(node *Node) bool) (result *Node) {
+	n.walk(func(node *Node) bool {
+		if fn(node) {
+			result = node
+			return false
+		}
+		return true
+	})
+	return
+}
+
+// FindAll returns all descendants of current node, which satisfies the fn, in depth-first order (children in order of Inheritors)
+func (n *Node) FindAll(fn func(node *Node) bool) (result []*Node) {
+	result = make([]*Node, 0)
+	n.walk(func(node *Node) bool {
+		if fn(node) {
+			result = append(result, node)
+		}
+		return true
+	})
+	return
+}
+
+// walk calls fn for every descendant of current node in depth-first order, until fn returns false
+func (n *Node) walk(fn func(node *Node) bool) bool {
+	for _, child := range n.Inheritors() {
+		if !fn(child) || !child.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
 // JSONPath evaluate path for current node
 func (n *Node) JSONPath(path string) (result []*Node, err error) {
-	commands, err := ParseJSONPath(path)
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return deReference(context.Background(), n, commands)
+}
+
+// FilterNodes returns children of current container node, for which the filter expression is true, the same as `[?(expr)]` in JSONPath, e.g. `@.price < 10`.
+func (n *Node) FilterNodes(expr string) ([]*Node, error) {
+	if !n.IsContainer() {
+		return nil, n.typeError()
+	}
+	filter, err := parseFilter(expr)
 	if err != nil {
 		return nil, err
 	}
-	return deReference(n, commands)
+	result, err := filterNodes(context.Background(), n, filter, expr)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = make([]*Node, 0)
+	}
+	return result, nil
+}
+
+// Count returns the count of nodes found by JSONPath request from current node. Nodes found by the last step of the path are counted
+// without collecting the result, values of the found nodes are not calculated.
+func (n *Node) Count(path string) (int, error) {
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return 0, err
+	}
+	return countReference(context.Background(), n, commands, 0)
+}
+
+// CountMatches returns the count of nodes found by the relative JSONPath request under current node, like `@.items[*]`.
+// Unlike Count, the path without the leading `$` or `@` is relative to current node: `items[*]` is the same as `@.items[*]`.
+// The same as Count, nodes found by the last step of the path are counted without collecting the result.
+func (n *Node) CountMatches(relPath string) (int, error) {
+	return n.Count(relativePath(relPath))
+}
+
+// relativePath returns the path with the leading `@`, if it doesn't start with `$` or `@`
+func relativePath(relPath string) string {
+	if relPath != "" && relPath[0] != dollar && relPath[0] != at {
+		if relPath[0] == dot || relPath[0] == bracketL {
+			return string(at) + relPath
+		}
+		return string(at) + string(dot) + relPath
+	}
+	return relPath
+}
+
+// SumByPath returns the sum of the numbers found by the relative JSONPath request in each child of current array or object,
+// like the total price of the items by `@.price`. Children without matches are skipped, non-numeric matches cause an error.
+// Sum of no numbers is 0.
+func (n *Node) SumByPath(relPath string) (float64, error) {
+	values, err := n.numbersByPath(relPath)
+	if err != nil {
+		return 0, err
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum, nil
+}
+
+// AvgByPath returns the average of the numbers found by the relative JSONPath request in each child of current array or object,
+// same as SumByPath. No numbers cause an error.
+func (n *Node) AvgByPath(relPath string) (float64, error) {
+	values, err := n.numbersByPath(relPath)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, errorRequest("no numeric values by the path: %s", relPath)
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values)), nil
+}
+
+// MinByPath returns the minimum of the numbers found by the relative JSONPath request in each child of current array or object,
+// same as SumByPath. No numbers cause an error.
+func (n *Node) MinByPath(relPath string) (float64, error) {
+	values, err := n.numbersByPath(relPath)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, errorRequest("no numeric values by the path: %s", relPath)
+	}
+	result := values[0]
+	for _, value := range values[1:] {
+		result = math.Min(result, value)
+	}
+	return result, nil
+}
+
+// MaxByPath returns the maximum of the numbers found by the relative JSONPath request in each child of current array or object,
+// same as SumByPath. No numbers cause an error.
+func (n *Node) MaxByPath(relPath string) (float64, error) {
+	values, err := n.numbersByPath(relPath)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, errorRequest("no numeric values by the path: %s", relPath)
+	}
+	result := values[0]
+	for _, value := range values[1:] {
+		result = math.Max(result, value)
+	}
+	return result, nil
+}
+
+// numbersByPath returns the numbers found by the relative JSONPath request in each child of current container
+func (n *Node) numbersByPath(relPath string) ([]float64, error) {
+	if n == nil || !n.IsContainer() {
+		return nil, errorType()
+	}
+	relPath = relativePath(relPath)
+	values := make([]float64, 0, len(n.children))
+	for _, child := range n.Inheritors() {
+		found, err := child.JSONPath(relPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range found {
+			if node.Type() != Numeric {
+				return nil, errorRequest("non-numeric value by the path: %s", node.Path())
+			}
+			value, err := node.GetNumeric()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// Exists returns true if JSONPath request from current node founds at least one node. Wrong path is reported as false, use ExistsE to get the error.
+func (n *Node) Exists(path string) bool {
+	exists, _ := n.ExistsE(path)
+	return exists
+}
+
+// ExistsE returns true if JSONPath request from current node founds at least one node, or an error of the path parsing.
+// Like Count, the result is not collected, and the evaluation of the last step stops on the first found node.
+func (n *Node) ExistsE(path string) (bool, error) {
+	commands, err := parseJSONPath(path)
+	if err != nil {
+		return false, err
+	}
+	count, err := countReference(context.Background(), n, commands, 1)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// detached returns a shallow copy of current node, without link to the parent, which shares data and children with the original one
+func (n *Node) detached() (node *Node) {
+	node = &Node{
+		children: n.children,
+		order:    n.order,
+		_type:    n._type,
+		data:     n.data,
+		borders:  n.borders,
+		dirty:    n.dirty,
+		frozen:   n.frozen,
+	}
+	if value := n.value.Load(); value != nil {
+		node.value.Store(value)
+	}
+	return
 }
 
 // root returns the root node
@@ -10,6 +10,21 @@ func (n *Node) IsDirty() bool {
 	return n.dirty
 }
 
+// Freeze marks current node, with all underlying nodes, as immutable: all mutation methods will return an error.
+//
+// Frozen tree is safe for concurrent use. Use Node.Clone to get a mutable copy of the frozen node.
+func (n *Node) Freeze() {
+	n.frozen = true
+	for _, child := range n.children {
+		child.Freeze()
+	}
+}
+
+// IsFrozen returns true if current node was frozen by Node.Freeze
+func (n *Node) IsFrozen() bool {
+	return n.frozen
+}
+
 // SetNull update current node value with Null value
 func (n *Node) SetNull() error {
 	return n.update(Null, nil)
@@ -40,11 +55,24 @@ func (n *Node) SetObject(value map[string]*Node) error {
 	return n.update(Object, value)
 }
 
+// SetRaw parses raw JSON data and update current node value with parsed value, the node keeps its key or index in the parent.
+// Wrong data leaves current node unchanged.
+func (n *Node) SetRaw(raw []byte) error {
+	value, err := Unmarshal(raw)
+	if err != nil {
+		return err
+	}
+	return n.replaceValue(value)
+}
+
 // AppendArray append current Array node values with Node values
 func (n *Node) AppendArray(value ...*Node) error {
 	if !n.IsArray() {
 		return errorType()
 	}
+	if n.frozen {
+		return errorFrozen()
+	}
 	for _, val := range value {
 		if err := n.appendNode(nil, val); err != nil {
 			return err
@@ -54,6 +82,39 @@ func (n *Node) AppendArray(value ...*Node) error {
 	return nil
 }
 
+// InsertIndex inserts value into current Array node at the index, the following elements are shifted up.
+// Negative index counts from the end of Array, index equal to the size of Array appends value.
+func (n *Node) InsertIndex(index int, value *Node) error {
+	if !n.IsArray() {
+		return errorType()
+	}
+	if n.frozen {
+		return errorFrozen()
+	}
+	if index < 0 {
+		index += len(n.children)
+	}
+	if index < 0 || index > len(n.children) {
+		return errorRequest("out of index %d", index)
+	}
+	if value.parent == n && *value.index < index { // indexes will be shifted
+		index--
+	}
+	if err := n.appendNode(nil, value); err != nil {
+		return err
+	}
+	for i := len(n.children) - 1; i > index; i-- {
+		current := i
+		previous := n.children[strconv.Itoa(i-1)]
+		previous.index = &current
+		n.children[strconv.Itoa(i)] = previous
+	}
+	value.index = &index
+	n.children[strconv.Itoa(index)] = value
+	n.mark()
+	return nil
+}
+
 // AppendObject append current Object node value with key:value
 func (n *Node) AppendObject(key string, value *Node) error {
 	if !n.IsObject() {
@@ -67,6 +128,34 @@ func (n *Node) AppendObject(key string, value *Node) error {
 	return nil
 }
 
+// AppendRaw parses raw JSON data and appends current Object node value with key:parsed value
+func (n *Node) AppendRaw(key string, raw []byte) error {
+	if !n.IsObject() {
+		return errorType()
+	}
+	value, err := Unmarshal(raw)
+	if err != nil {
+		return err
+	}
+	return n.AppendObject(key, value)
+}
+
+// AppendArrayRaw parses raw JSON data and appends current Array node values with parsed values
+func (n *Node) AppendArrayRaw(raw ...[]byte) error {
+	if !n.IsArray() {
+		return errorType()
+	}
+	values := make([]*Node, len(raw))
+	for i, data := range raw {
+		value, err := Unmarshal(data)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	return n.AppendArray(values...)
+}
+
 // DeleteNode removes element child
 func (n *Node) DeleteNode(value *Node) error {
 	return n.remove(value)
@@ -90,6 +179,94 @@ func (n *Node) PopKey(key string) (node *Node, err error) {
 	return node, n.remove(node)
 }
 
+// Rename changes the key of the Object element, the value stays the same. Renaming to the existing key returns an error.
+func (n *Node) Rename(oldKey, newKey string) error {
+	node, err := n.GetKey(oldKey)
+	if err != nil {
+		return err
+	}
+	if n.frozen {
+		return errorFrozen()
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	if n.HasKey(newKey) {
+		return errorRequest("key '%s' already exists", newKey)
+	}
+	n.mark()
+	n.resetCache()
+	order := n.orderedKeys()
+	for i, key := range order {
+		if key == oldKey {
+			order[i] = newKey
+		}
+	}
+	delete(n.children, oldKey)
+	node.key = &newKey
+	n.children[newKey] = node
+	n.order = order
+	return nil
+}
+
+// MoveKey moves the key of current Object node to the position toIndex in order of keys, which is used by Marshal.
+// Keys of Object are kept in order of insertion, negative index counts from the end, e.g. -1 moves the key to the end.
+func (n *Node) MoveKey(key string, toIndex int) error {
+	if !n.IsObject() {
+		return errorType()
+	}
+	if n.frozen {
+		return errorFrozen()
+	}
+	if !n.HasKey(key) {
+		return errorRequest("wrong key '%s'", key)
+	}
+	order := n.orderedKeys()
+	if toIndex < 0 {
+		toIndex += len(order)
+	}
+	if toIndex < 0 || toIndex >= len(order) {
+		return errorRequest("out of index %d", toIndex)
+	}
+	result := make([]string, 0, len(order))
+	for _, current := range order {
+		if current != key {
+			result = append(result, current)
+		}
+	}
+	result = append(result[:toIndex], append([]string{key}, result[toIndex:]...)...)
+	n.mark()
+	n.order = result
+	return nil
+}
+
+// Swap exchanges the elements of current Array node at the indexes i and j. Negative index counts from the end of Array.
+func (n *Node) Swap(i, j int) error {
+	if !n.IsArray() {
+		return errorType()
+	}
+	if n.frozen {
+		return errorFrozen()
+	}
+	left, err := n.GetIndex(i)
+	if err != nil {
+		return err
+	}
+	right, err := n.GetIndex(j)
+	if err != nil {
+		return err
+	}
+	if left == right {
+		return nil
+	}
+	n.mark()
+	n.resetCache()
+	left.index, right.index = right.index, left.index
+	n.children[strconv.Itoa(*left.index)] = left
+	n.children[strconv.Itoa(*right.index)] = right
+	return nil
+}
+
 // DeleteIndex removes element from Array, by it's index
 func (n *Node) DeleteIndex(index int) error {
 	node, err := n.GetIndex(index)
@@ -116,8 +293,170 @@ func (n *Node) Delete() error {
 	return n.parent.remove(n)
 }
 
+// MergeOption is a strategy of merging values in Node.Merge
+type MergeOption int
+
+const (
+	// MergeArrayReplace means that Array values of the current node will be replaced by the values of the other one
+	MergeArrayReplace MergeOption = iota
+	// MergeArrayConcat means that Array values of the other node will be appended to the current Array node
+	MergeArrayConcat
+)
+
+// Merge deep-merges other Node into the current one. Other Node stays untouched, all merged values are cloned.
+//
+// Objects are merged recursively: overlapping keys will be merged, missing keys will be added.
+// Arrays are replaced by default, use MergeArrayConcat option to append values instead.
+// Any other value of the other Node overwrites current value.
+//
+// Returns WrongType error if current and other nodes have incompatible types, e.g. Object and Array.
+func (n *Node) Merge(other *Node, options ...MergeOption) error {
+	if n.isParentNode(other) || other.isParentNode(n) {
+		return errorRequest("try to create infinite loop")
+	}
+	if n._type != other._type && (n.IsContainer() || other.IsContainer()) {
+		return errorType()
+	}
+	return n.merge(other, options)
+}
+
+func (n *Node) merge(other *Node, options []MergeOption) (err error) {
+	switch other._type {
+	case Object:
+		for _, child := range other.Inheritors() {
+			current, ok := n.children[*child.key]
+			if ok && current._type == Object && child._type == Object {
+				err = current.merge(child, options)
+			} else if ok && current._type == Array && child._type == Array {
+				err = current.merge(child, options)
+			} else {
+				err = n.AppendObject(*child.key, child.Clone())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case Array:
+		children := other.Inheritors()
+		for i, child := range children {
+			children[i] = child.Clone()
+		}
+		for _, option := range options {
+			if option == MergeArrayConcat {
+				return n.AppendArray(children...)
+			}
+		}
+		return n.SetArray(children)
+	}
+	value, err := other.Value()
+	if err != nil {
+		return err
+	}
+	return n.update(other._type, value)
+}
+
+// CompactFlag is a set of values to be removed in Node.Compact
+type CompactFlag int
+
+const (
+	// CompactNull removes Null values
+	CompactNull CompactFlag = 1 << iota
+	// CompactEmptyArray removes empty Array values
+	CompactEmptyArray
+	// CompactEmptyObject removes empty Object values
+	CompactEmptyObject
+	// CompactAll removes Null values, empty Array and empty Object values
+	CompactAll = CompactNull | CompactEmptyArray | CompactEmptyObject
+)
+
+// Compact recursively removes Null values, empty Arrays and empty Objects from current node, arrays will be reindexed.
+// Containers, that become empty after the cleaning, are also removed. Current node itself is never removed.
+//
+// Use flags to choose values to be removed, CompactAll is used by default.
+func (n *Node) Compact(flags ...CompactFlag) error {
+	flag := CompactAll
+	if len(flags) > 0 {
+		flag = 0
+		for _, value := range flags {
+			flag |= value
+		}
+	}
+	return n.compact(flag)
+}
+
+func (n *Node) compact(flag CompactFlag) (err error) {
+	children := n.Inheritors()
+	for i := len(children) - 1; i >= 0; i-- {
+		child := children[i]
+		if err = child.compact(flag); err != nil {
+			return err
+		}
+		if child.IsNull() && flag&CompactNull != 0 ||
+			child.IsArray() && child.Empty() && flag&CompactEmptyArray != 0 ||
+			child.IsObject() && child.Empty() && flag&CompactEmptyObject != 0 {
+			if err = n.remove(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Truncate recursively trims arrays to maxArrayLen elements and strings to maxStringLen runes, with an ellipsis "…" appended to the truncated strings.
+// Negative value of the limit disables the corresponding truncation.
+func (n *Node) Truncate(maxArrayLen, maxStringLen int) error {
+	switch n._type {
+	case String:
+		if maxStringLen < 0 {
+			return nil
+		}
+		value, err := n.GetString()
+		if err != nil {
+			return err
+		}
+		if runes := []rune(value); len(runes) > maxStringLen {
+			return n.SetString(string(runes[:maxStringLen]) + "…")
+		}
+	case Array:
+		if maxArrayLen >= 0 {
+			for size := n.Size(); size > maxArrayLen; size-- {
+				if err := n.DeleteIndex(size - 1); err != nil {
+					return err
+				}
+			}
+		}
+		fallthrough
+	case Object:
+		for _, child := range n.children {
+			if err := child.Truncate(maxArrayLen, maxStringLen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TransformScalars calls fn for each scalar node (Null, Numeric, String or Bool) of current node tree, including current node itself,
+// in depth-first order, children in order of Inheritors. Containers are skipped, fn can change the nodes in place, like SetString does,
+// and the new children of the changed nodes are not visited. Walking stops on the first error returned by fn.
+func (n *Node) TransformScalars(fn func(*Node) error) error {
+	if n.IsLeaf() {
+		return fn(n)
+	}
+	for _, child := range n.Inheritors() {
+		if err := child.TransformScalars(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Clone creates full copy of current Node. With all child, but without link to the parent.
 func (n *Node) Clone() *Node {
+	if n.IsMissing() {
+		return missingNode
+	}
 	node := n.clone()
 	node.parent = nil
 	node.key = nil
@@ -125,7 +464,168 @@ func (n *Node) Clone() *Node {
 	return node
 }
 
+// ReplaceAll replaces each node found by JSONPath request from the root node with the result of fn, and returns the count of replaced nodes.
+// Nodes detached by the previous replacements, like the children of the replaced node, are skipped. The root node itself keeps its identity,
+// its value is replaced.
+func ReplaceAll(root *Node, path string, fn func(*Node) (*Node, error)) (int, error) {
+	nodes, err := root.JSONPath(path)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, node := range nodes {
+		if node.root() != root {
+			continue
+		}
+		value, err := fn(node)
+		if err != nil {
+			return count, err
+		}
+		if value == nil {
+			return count, errorRequest("nil value for %s", node.Path())
+		}
+		if err = node.replaceWith(value); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// RemoveMatches removes each node found by JSONPath request from the root node from its parent, and returns the count of removed nodes.
+// Nodes detached by the previous removals, like the children of the removed node, are skipped, elements of arrays are shifted.
+// Returns an error, if the root node itself is found.
+func RemoveMatches(root *Node, path string) (int, error) {
+	nodes, err := root.JSONPath(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, node := range nodes {
+		if node == root {
+			return 0, errorRequest("root node can't be removed")
+		}
+	}
+	count := 0
+	for _, node := range nodes {
+		if node.parent == nil || node.root() != root {
+			continue
+		}
+		if err = node.parent.remove(node); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// replaceWith puts value to the place of current node in the parent, or replaces current node value if it has no parent
+func (n *Node) replaceWith(value *Node) error {
+	if n == value {
+		return nil
+	}
+	switch {
+	case n.parent == nil:
+		return n.replaceValue(value)
+	case n.parent.IsArray():
+		return n.parent.setIndex(*n.index, value)
+	default:
+		return n.parent.AppendObject(*n.key, value)
+	}
+}
+
+// Prune returns a new tree with the copies of the nodes found by any of the JSONPath requests from the root node, with all their descendants,
+// and the containers on the way from the root to them. Other elements are dropped, kept elements of arrays are renumbered, the order of keys is kept.
+// Container root without found nodes becomes empty, scalar root without found nodes becomes Null.
+func Prune(root *Node, keepPaths []string) (*Node, error) {
+	keep := make(map[*Node]bool)
+	ancestors := make(map[*Node]bool)
+	for _, path := range keepPaths {
+		nodes, err := root.JSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if node.root() != root { // computed values, like `.length`
+				continue
+			}
+			keep[node] = true
+			for parent := node.parent; parent != nil && !ancestors[parent]; parent = parent.parent {
+				ancestors[parent] = true
+			}
+		}
+	}
+	if !keep[root] && !root.IsContainer() {
+		return NullNode(""), nil
+	}
+	return root.prune(keep, ancestors), nil
+}
+
+// prune returns a copy of current node without the key, the kept nodes are copied with all their descendants, the ancestors only with the kept children
+func (n *Node) prune(keep, ancestors map[*Node]bool) *Node {
+	if keep[n] {
+		return n.Clone()
+	}
+	if n.IsArray() {
+		children := make([]*Node, 0)
+		for _, child := range n.Inheritors() {
+			if keep[child] || ancestors[child] {
+				children = append(children, child.prune(keep, ancestors))
+			}
+		}
+		result := ArrayNode("", children)
+		result.key = nil
+		return result
+	}
+	result := ObjectNode("", nil)
+	result.key = nil
+	for _, key := range n.orderedKeys() {
+		if child := n.children[key]; keep[child] || ancestors[child] {
+			_ = result.AppendObject(key, child.prune(keep, ancestors))
+		}
+	}
+	return result
+}
+
+// Pick returns a new Object node with the copies of the current node elements by the given keys, missing keys are ignored
+func (n *Node) Pick(keys ...string) (*Node, error) {
+	if !n.IsObject() {
+		return nil, errorType()
+	}
+	children := make(map[string]*Node, len(keys))
+	for _, key := range keys {
+		if child, ok := n.children[key]; ok {
+			children[key] = child.Clone()
+		}
+	}
+	node := ObjectNode("", children)
+	node.key = nil // the same as Clone: without the key
+	return node, nil
+}
+
+// Omit returns a new Object node with the copies of the current node elements, except the given keys
+func (n *Node) Omit(keys ...string) (*Node, error) {
+	if !n.IsObject() {
+		return nil, errorType()
+	}
+	omit := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		omit[key] = true
+	}
+	children := make(map[string]*Node, len(n.children))
+	for key, child := range n.children {
+		if !omit[key] {
+			children[key] = child.Clone()
+		}
+	}
+	node := ObjectNode("", children)
+	node.key = nil // the same as Clone: without the key
+	return node, nil
+}
+
 func (n *Node) clone() *Node {
+	if n.IsMissing() {
+		return newMissingNode()
+	}
 	node := &Node{
 		parent:   n.parent,
 		children: make(map[string]*Node, len(n.children)),
@@ -134,17 +634,27 @@ func (n *Node) clone() *Node {
 		_type:    n._type,
 		data:     n.data,
 		borders:  n.borders,
-		value:    n.value,
 		dirty:    n.dirty,
 	}
+	if n.order != nil {
+		node.order = append([]string{}, n.order...)
+	}
+	if !n.IsContainer() { // cached value of container contains links to the original children
+		node.value = n.value
+	}
 	for key, value := range n.children {
-		node.children[key] = value.clone()
+		child := value.clone()
+		child.parent = node
+		node.children[key] = child
 	}
 	return node
 }
 
 // update stored value, with validations
 func (n *Node) update(_type NodeType, value interface{}) error {
+	if n.frozen {
+		return errorFrozen()
+	}
 	// validate
 	err := n.validate(_type, value)
 	if err != nil {
@@ -155,7 +665,7 @@ func (n *Node) update(_type NodeType, value interface{}) error {
 	n.clear()
 
 	atomic.StoreInt32((*int32)(&n._type), int32(_type))
-	n.value = atomic.Value{}
+	n.resetCache()
 	if value != nil {
 		switch _type {
 		case Array:
@@ -169,8 +679,9 @@ func (n *Node) update(_type NodeType, value interface{}) error {
 		case Object:
 			nodes := value.(map[string]*Node)
 			n.children = make(map[string]*Node, len(nodes))
-			for key, node := range nodes {
-				if err = n.appendNode(&key, node); err != nil {
+			for _, key := range sortedKeys(nodes) {
+				var name = key
+				if err = n.appendNode(&name, nodes[key]); err != nil {
 					return err
 				}
 			}
@@ -217,18 +728,28 @@ func (n *Node) validate(_type NodeType, value interface{}) error {
 
 // update stored value, without validations
 func (n *Node) remove(value *Node) error {
-	if !n.isContainer() {
+	if !n.IsContainer() {
 		return errorType()
 	}
+	if n.frozen {
+		return errorFrozen()
+	}
 	if value.parent != n {
 		return errorRequest("wrong parent")
 	}
 	n.mark()
+	n.resetCache()
 	if n.IsArray() {
 		delete(n.children, strconv.Itoa(*value.index))
 		n.dropindex(*value.index)
 	} else {
 		delete(n.children, *value.key)
+		for i, key := range n.order {
+			if key == *value.key {
+				n.order = append(n.order[:i:i], n.order[i+1:]...)
+				break
+			}
+		}
 	}
 	value.parent = nil
 	return nil
@@ -248,6 +769,9 @@ func (n *Node) dropindex(index int) {
 
 // appendNode append current Node node value with new Node value, by key or index
 func (n *Node) appendNode(key *string, value *Node) error {
+	if n.frozen || value.frozen {
+		return errorFrozen()
+	}
 	if n.isParentNode(value) {
 		return errorRequest("try to create infinite loop")
 	}
@@ -256,15 +780,16 @@ func (n *Node) appendNode(key *string, value *Node) error {
 			return err
 		}
 	}
+	n.resetCache()
 	value.parent = n
 	value.key = key
 	if key != nil {
 		if old, ok := n.children[*key]; ok {
-			if old != value {
-				if err := n.remove(old); err != nil {
-					return err
-				}
+			if old != value { // replaced in place, the key keeps its position
+				old.parent = nil
 			}
+		} else {
+			n.order = append(n.order, *key)
 		}
 		n.children[*key] = value
 	} else {
@@ -275,6 +800,40 @@ func (n *Node) appendNode(key *string, value *Node) error {
 	return nil
 }
 
+// setIndex replaces element of current Array node by it's index with the new Node value
+func (n *Node) setIndex(index int, value *Node) error {
+	if n.frozen || value.frozen {
+		return errorFrozen()
+	}
+	if n.isParentNode(value) {
+		return errorRequest("try to create infinite loop")
+	}
+	old, ok := n.children[strconv.Itoa(index)]
+	if !ok {
+		return errorRequest("wrong index: %d", index)
+	}
+	if old == value {
+		return nil
+	}
+	if parent := value.parent; parent != nil {
+		if parent == n && *value.index < index { // indexes will be shifted
+			index--
+		}
+		if err := parent.remove(value); err != nil {
+			return err
+		}
+		old = n.children[strconv.Itoa(index)]
+	}
+	n.mark()
+	n.resetCache()
+	old.parent = nil
+	value.parent = n
+	value.key = nil
+	value.index = &index
+	n.children[strconv.Itoa(index)] = value
+	return nil
+}
+
 // mark node as dirty, with all parents (up the tree)
 func (n *Node) mark() {
 	node := n
@@ -284,6 +843,11 @@ func (n *Node) mark() {
 	}
 }
 
+// resetCache drops the cached value of node, cached value of the container contains links to the children
+func (n *Node) resetCache() {
+	n.value = atomic.Value{}
+}
+
 // clear current value of node
 func (n *Node) clear() {
 	n.data = nil
@@ -292,6 +856,7 @@ func (n *Node) clear() {
 		n.children[key].parent = nil
 	}
 	n.children = nil
+	n.order = nil
 }
 
 // isParentNode check if current node is one of the parents
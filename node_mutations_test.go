@@ -2,7 +2,9 @@ package ajson
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -694,6 +696,79 @@ func TestNode_AppendArray_self(t *testing.T) {
 	}
 }
 
+func TestNode_InsertIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		index    int
+		expected string
+		paths    string
+		err      bool
+	}{
+		{name: "start", index: 0, expected: `["x",0,1,2]`, paths: "[$[0], $[1], $[2], $[3]]"},
+		{name: "middle", index: 2, expected: `[0,1,"x",2]`, paths: "[$[0], $[1], $[2], $[3]]"},
+		{name: "end", index: 3, expected: `[0,1,2,"x"]`, paths: "[$[0], $[1], $[2], $[3]]"},
+		{name: "negative", index: -1, expected: `[0,1,"x",2]`, paths: "[$[0], $[1], $[2], $[3]]"},
+		{name: "negative start", index: -3, expected: `["x",0,1,2]`, paths: "[$[0], $[1], $[2], $[3]]"},
+		{name: "out of range", index: 4, expected: `[0,1,2]`, err: true},
+		{name: "negative out of range", index: -4, expected: `[0,1,2]`, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(`[0,1,2]`)))
+			value := StringNode("", "x")
+			if err := root.InsertIndex(test.index, value); (err != nil) != test.err {
+				t.Errorf("InsertIndex() unexpected error: %v", err)
+			}
+			if result, err := Marshal(root); err != nil {
+				t.Errorf("Marshal returns error: %v", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Marshal returns wrong value: %s, expected: %s", string(result), test.expected)
+			}
+			if test.err {
+				return
+			}
+			if actual := fullPath(root.Inheritors()); actual != test.paths {
+				t.Errorf("Wrong paths: %s, expected: %s", actual, test.paths)
+			}
+			if value.Index() < 0 || root.MustIndex(value.Index()) != value {
+				t.Errorf("Wrong index of the inserted value: %d", value.Index())
+			}
+		})
+	}
+}
+
+func TestNode_InsertIndex_move(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a":[0,1,2,3],"b":[4]}`)))
+	array := root.MustKey("a")
+	if err := array.InsertIndex(3, array.MustIndex(0)); err != nil {
+		t.Errorf("InsertIndex() unexpected error: %v", err)
+	}
+	if err := array.InsertIndex(0, array.MustIndex(-1)); err != nil {
+		t.Errorf("InsertIndex() unexpected error: %v", err)
+	}
+	if err := array.InsertIndex(1, root.MustKey("b").MustIndex(0)); err != nil {
+		t.Errorf("InsertIndex() unexpected error: %v", err)
+	}
+	if result, err := Marshal(array); err != nil {
+		t.Errorf("Marshal returns error: %v", err)
+	} else if string(result) != `[3,4,1,2,0]` {
+		t.Errorf("Marshal returns wrong value: %s", string(result))
+	}
+	if size := root.MustKey("b").Size(); size != 0 {
+		t.Errorf("Wrong size of the source array: %d", size)
+	}
+	if err := array.InsertIndex(0, array); err == nil {
+		t.Errorf("InsertIndex() expected error on infinite loop")
+	}
+	if err := root.InsertIndex(0, NullNode("")); err == nil {
+		t.Errorf("InsertIndex() expected error on Object")
+	}
+	array.Freeze()
+	if err := array.InsertIndex(0, NullNode("")); err == nil {
+		t.Errorf("InsertIndex() expected error on frozen node")
+	}
+}
+
 func TestNode_AppendObject(t *testing.T) {
 	if err := Must(Unmarshal([]byte(`{"foo":"bar","baz":null}`))).AppendObject("biz", NullNode("")); err != nil {
 		t.Errorf("AppendArray should return error")
@@ -720,6 +795,104 @@ func TestNode_AppendObject(t *testing.T) {
 	}
 }
 
+func TestNode_AppendRaw(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":"bar"}`)))
+
+	if err := root.AppendRaw("obj", []byte(`{"baz": [1, {"qux": null}]}`)); err != nil {
+		t.Errorf("AppendRaw returns error: %v", err)
+	}
+	if err := root.AppendRaw("arr", []byte(` [true, "a"] `)); err != nil {
+		t.Errorf("AppendRaw returns error: %v", err)
+	}
+	if err := root.AppendRaw("foo", []byte(`1`)); err != nil {
+		t.Errorf("AppendRaw returns error: %v", err)
+	}
+	actual, _ := root.Unpack()
+	expected, _ := Must(Unmarshal([]byte(`{"foo":1,"obj":{"baz":[1,{"qux":null}]},"arr":[true,"a"]}`))).Unpack()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("AppendRaw wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+	}
+	if path := root.MustKey("obj").MustKey("baz").MustIndex(1).Path(); path != "$['obj']['baz'][1]" {
+		t.Errorf("AppendRaw wrong path: %s", path)
+	}
+
+	if err := root.AppendRaw("bad", []byte(`{"foo":`)); err == nil {
+		t.Errorf("AppendRaw must returns error: wrong data")
+	}
+	if root.HasKey("bad") {
+		t.Errorf("AppendRaw must not append wrong data")
+	}
+	if err := root.MustKey("arr").AppendRaw("foo", []byte(`1`)); err == nil {
+		t.Errorf("AppendRaw must returns error: not object")
+	}
+}
+
+func TestNode_AppendArrayRaw(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[1]`)))
+
+	if err := root.AppendArrayRaw([]byte(`{"foo": [2]}`), []byte(`[3, [4]]`)); err != nil {
+		t.Errorf("AppendArrayRaw returns error: %v", err)
+	}
+	if value := string(root.Bytes()); value != `[1,{"foo":[2]},[3,[4]]]` {
+		t.Errorf("AppendArrayRaw wrong result: %s", value)
+	}
+	if path := root.MustIndex(2).MustIndex(1).Path(); path != "$[2][1]" {
+		t.Errorf("AppendArrayRaw wrong path: %s", path)
+	}
+
+	if err := root.AppendArrayRaw([]byte(`5`), []byte(`[`)); err == nil {
+		t.Errorf("AppendArrayRaw must returns error: wrong data")
+	}
+	if root.Size() != 3 {
+		t.Errorf("AppendArrayRaw must not append values on error")
+	}
+	if err := Must(Unmarshal([]byte(`{}`))).AppendArrayRaw([]byte(`1`)); err == nil {
+		t.Errorf("AppendArrayRaw must returns error: not array")
+	}
+}
+
+func TestNode_SetRaw(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":"bar","list":[1,{"a":[2]},3]}`)))
+
+	if err := root.MustKey("foo").SetRaw([]byte(` {"baz": [1, {"qux": null}]} `)); err != nil {
+		t.Errorf("SetRaw returns error: %v", err)
+	}
+	if err := root.MustKey("list").MustIndex(1).SetRaw([]byte(`"scalar"`)); err != nil {
+		t.Errorf("SetRaw returns error: %v", err)
+	}
+	if ok, err := root.Eq(Must(Unmarshal([]byte(`{"foo":{"baz":[1,{"qux":null}]},"list":[1,"scalar",3]}`)))); err != nil || !ok {
+		t.Errorf("SetRaw wrong result: %s", root)
+	}
+	if path := root.MustKey("foo").MustKey("baz").MustIndex(1).Path(); path != "$['foo']['baz'][1]" {
+		t.Errorf("SetRaw wrong path: %s", path)
+	}
+	if path := root.MustKey("list").MustIndex(1).Path(); path != "$['list'][1]" {
+		t.Errorf("SetRaw wrong path: %s", path)
+	}
+	if value := string(root.MustKey("list").Bytes()); value != `[1,"scalar",3]` {
+		t.Errorf("SetRaw wrong result: %s", value)
+	}
+
+	if err := root.MustKey("list").MustIndex(0).SetRaw([]byte(`[true, `)); err == nil {
+		t.Errorf("SetRaw must returns error: wrong data")
+	}
+	if value := root.MustKey("list").MustIndex(0); !value.IsNumeric() || value.MustNumeric() != 1 {
+		t.Errorf("SetRaw must not change the node on error: %s", value)
+	}
+
+	root = Must(Unmarshal([]byte(`[{"a":1}]`)))
+	if err := root.SetRaw([]byte(`{"b":2}`)); err != nil {
+		t.Errorf("SetRaw returns error: %v", err)
+	}
+	if value := string(root.Bytes()); value != `{"b":2}` {
+		t.Errorf("SetRaw wrong result: %s", value)
+	}
+	root.Freeze()
+	if err := root.SetRaw([]byte(`null`)); err == nil {
+		t.Errorf("SetRaw must returns error: frozen")
+	}
+}
+
 func TestNode_AppendObject_self(t *testing.T) {
 	root := Must(Unmarshal([]byte(`{"foo":{"bar":"baz"},"fiz":null}`)))
 
@@ -951,6 +1124,229 @@ func TestNode_DeleteKey(t *testing.T) {
 	}
 }
 
+func TestNode_Rename(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected string
+		oldKey   string
+		newKey   string
+		fail     bool
+	}{
+		{`null`, ``, "foo", "bar", true},
+		{`[1,2,3]`, ``, "0", "1", true},
+		{`{}`, ``, "foo", "bar", true},
+		{`{"foo":"bar","baz":1}`, ``, "foo", "baz", true},
+		{`{"foo":"bar"}`, `{"foo":"bar"}`, "foo", "foo", false},
+		{`{"foo":"bar"}`, `{"qux":"bar"}`, "foo", "qux", false},
+		{`{"foo":{"bar":[1]}}`, `{"":{"bar":[1]}}`, "foo", "", false},
+		{`{"foo":{"bar":[1]},"baz":1}`, `{"qux":{"bar":[1]},"baz":1}`, "foo", "qux", false},
+	}
+	for _, test := range tests {
+		t.Run(test.json, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			err := root.Rename(test.oldKey, test.newKey)
+			if test.fail {
+				if err == nil {
+					t.Errorf("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			actual, _ := root.Unpack()
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Unexpected result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestNode_Rename_path(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a":1,"foo":{"bar":[1]},"z":2}`)))
+	node := root.MustKey("foo")
+	if err := root.Rename("foo", "y"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+	if root.MustKey("y") != node || root.HasKey("foo") {
+		t.Errorf("Wrong node after Rename()")
+	}
+	if path := node.MustKey("bar").MustIndex(0).Path(); path != "$['y']['bar'][0]" {
+		t.Errorf("Wrong path after Rename(): %s", path)
+	}
+	if keys := fullPath(root.Inheritors()); keys != "[$['a'], $['y'], $['z']]" {
+		t.Errorf("Wrong order after Rename(): %s", keys)
+	}
+	if object := root.MustObject(); len(object) != 3 || object["y"] != node {
+		t.Errorf("Wrong value after Rename()")
+	}
+	if result := string(node.Bytes()); result != `{"bar":[1]}` {
+		t.Errorf("Wrong value after Rename(): %s", result)
+	}
+
+	root.Freeze()
+	if err := root.Rename("y", "foo"); err == nil {
+		t.Errorf("Expected error on frozen node")
+	}
+}
+
+func TestNode_MoveKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		index    int
+		expected string
+	}{
+		{name: "to the front", key: "c", index: 0, expected: `{"c":3,"b":2,"a":1,"d":4}`},
+		{name: "to the end", key: "b", index: 3, expected: `{"a":1,"c":3,"d":4,"b":2}`},
+		{name: "to the end negative", key: "b", index: -1, expected: `{"a":1,"c":3,"d":4,"b":2}`},
+		{name: "to the middle", key: "d", index: 1, expected: `{"b":2,"d":4,"a":1,"c":3}`},
+		{name: "same place", key: "a", index: 1, expected: `{"b":2,"a":1,"c":3,"d":4}`},
+		{name: "from the front negative", key: "b", index: -2, expected: `{"a":1,"c":3,"b":2,"d":4}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(`{"b": 2, "a": 1, "c": 3, "d": 4}`)))
+			if err := root.MoveKey(test.key, test.index); err != nil {
+				t.Errorf("MoveKey() unexpected error: %s", err)
+				return
+			}
+			if result, err := Marshal(root); err != nil || string(result) != test.expected {
+				t.Errorf("MoveKey() wrong result: %s, %v\nExpected: %s", result, err, test.expected)
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`{"b": 2, "a": 1}`)))
+	_ = root.AppendObject("c", NumericNode("", 3))
+	_ = root.AppendObject("b", StringNode("", "x"))
+	if err := root.MoveKey("c", 0); err != nil {
+		t.Errorf("MoveKey() unexpected error: %s", err)
+	}
+	_ = root.Rename("a", "z")
+	if result := string(root.Bytes()); result != `{"c":3,"b":"x","z":1}` {
+		t.Errorf("MoveKey() wrong result after mutations: %s", result)
+	}
+	_ = root.DeleteKey("c")
+	_ = root.AppendObject("c", NullNode(""))
+	if result := string(root.Bytes()); result != `{"b":"x","z":1,"c":null}` {
+		t.Errorf("MoveKey() wrong result after delete: %s", result)
+	}
+	if result := string(root.Clone().Bytes()); result != `{"b":"x","z":1,"c":null}` {
+		t.Errorf("MoveKey() wrong result of the clone: %s", result)
+	}
+
+	if err := root.MoveKey("missing", 0); err == nil {
+		t.Errorf("MoveKey() expected error on missing key")
+	}
+	if err := root.MoveKey("b", 3); err == nil {
+		t.Errorf("MoveKey() expected error on wrong index")
+	}
+	if err := root.MoveKey("b", -4); err == nil {
+		t.Errorf("MoveKey() expected error on wrong negative index")
+	}
+	if err := Must(Unmarshal([]byte(`[1, 2]`))).MoveKey("0", 1); err == nil {
+		t.Errorf("MoveKey() expected error on array")
+	}
+	root.Freeze()
+	if err := root.MoveKey("b", 1); err == nil {
+		t.Errorf("MoveKey() expected error on frozen node")
+	}
+}
+
+func TestNode_MoveKey_inheritors(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": 1, "m": 2, "z": 3}`)))
+	if err := root.MoveKey("m", 0); err != nil {
+		t.Errorf("MoveKey() unexpected error: %s", err)
+		return
+	}
+	keys := func(nodes []*Node) []string {
+		result := make([]string, len(nodes))
+		for i, node := range nodes {
+			result[i] = node.Key()
+		}
+		return result
+	}
+	if expected := []string{"m", "a", "z"}; !sliceEqual(root.Keys(), expected) || !sliceEqual(keys(root.Inheritors()), expected) {
+		t.Errorf("Inheritors() wrong order: %s, keys: %s", sliceString(keys(root.Inheritors())), sliceString(root.Keys()))
+	}
+	if expected := []string{"a", "m", "z"}; !sliceEqual(keys(root.InheritorsSorted()), expected) {
+		t.Errorf("InheritorsSorted() wrong order: %s", sliceString(keys(root.InheritorsSorted())))
+	}
+	if result, err := ToYAML(root); err != nil || string(result) != "m: 2\na: 1\nz: 3\n" {
+		t.Errorf("ToYAML() wrong result after MoveKey: %q, %v", result, err)
+	}
+	if result, err := root.JSONPath("$.*"); err != nil || fullPath(result) != "[$['a'], $['m'], $['z']]" {
+		t.Errorf("JSONPath() wrong result after MoveKey: %s, %v", fullPath(result), err)
+	}
+}
+
+func TestNode_Swap(t *testing.T) {
+	tests := []struct {
+		name     string
+		i, j     int
+		expected string
+	}{
+		{name: "first and last", i: 0, j: 3, expected: `[{"id":4},2,"c",[1]]`},
+		{name: "negative", i: 0, j: -1, expected: `[{"id":4},2,"c",[1]]`},
+		{name: "reversed", i: -1, j: -4, expected: `[{"id":4},2,"c",[1]]`},
+		{name: "middle", i: 1, j: 2, expected: `[[1],"c",2,{"id":4}]`},
+		{name: "same", i: 2, j: -2, expected: `[[1],2,"c",{"id":4}]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(`[[1],2,"c",{"id":4}]`)))
+			if err := root.Swap(test.i, test.j); err != nil {
+				t.Errorf("Swap() unexpected error: %s", err)
+				return
+			}
+			if result, err := Marshal(root); err != nil || string(result) != test.expected {
+				t.Errorf("Swap() wrong result: %s, %v\nExpected: %s", result, err, test.expected)
+			}
+			for i, child := range root.MustArray() {
+				if child.Index() != i || child.Path() != fmt.Sprintf("$[%d]", i) {
+					t.Errorf("Swap() wrong index of the element %d: %d, %s", i, child.Index(), child.Path())
+				}
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`[{"a": [1]}, 2, {"b": {"c": 3}}]`)))
+	first, last := root.MustIndex(0), root.MustIndex(-1)
+	if err := root.Swap(0, -1); err != nil {
+		t.Errorf("Swap() unexpected error: %s", err)
+	}
+	if root.MustIndex(0) != last || root.MustIndex(2) != first {
+		t.Errorf("Swap() should move the same nodes")
+	}
+	if path := first.MustKey("a").MustIndex(0).Path(); path != "$[2]['a'][0]" {
+		t.Errorf("Swap() wrong path of the nested node: %s", path)
+	}
+	if path := last.MustKey("b").MustKey("c").Path(); path != "$[0]['b']['c']" {
+		t.Errorf("Swap() wrong path of the nested node: %s", path)
+	}
+	if !root.IsDirty() {
+		t.Errorf("Swap() should mark the node as dirty")
+	}
+
+	if err := root.Swap(0, 3); err == nil {
+		t.Errorf("Swap() expected error on wrong index")
+	}
+	if err := root.Swap(-4, 0); err == nil {
+		t.Errorf("Swap() expected error on wrong negative index")
+	}
+	if err := Must(Unmarshal([]byte(`{"0": 1, "1": 2}`))).Swap(0, 1); err == nil {
+		t.Errorf("Swap() expected error on object")
+	}
+	root.Freeze()
+	if err := root.Swap(0, 1); err == nil {
+		t.Errorf("Swap() expected error on frozen node")
+	}
+}
+
 func TestNode_PopKey(t *testing.T) {
 	tests := []struct {
 		json     string
@@ -1431,6 +1827,364 @@ func TestNode_Clone(t *testing.T) {
 	}
 }
 
+func TestNode_Clone_value(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"array":[1,2],"object":{"foo":"bar"}}`)))
+	root.MustKey("array").MustArray()
+	root.MustKey("object").MustObject()
+	clone := root.Clone()
+	for _, child := range clone.MustKey("array").MustArray() {
+		if child.Parent() != clone.MustKey("array") {
+			t.Errorf("Clone() array value contains original children")
+		}
+	}
+	for _, child := range clone.MustKey("object").MustObject() {
+		if child.Parent() != clone.MustKey("object") {
+			t.Errorf("Clone() object value contains original children")
+		}
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	root := Must(Unmarshal(jsonPathTestData))
+	count, err := ReplaceAll(root, "$.store.book[*].price", func(node *Node) (*Node, error) {
+		price, err := node.GetNumeric()
+		if err != nil {
+			return nil, err
+		}
+		return NumericNode("", price*100), nil
+	})
+	if err != nil {
+		t.Errorf("ReplaceAll() unexpected error: %s", err)
+	}
+	if count != 4 {
+		t.Errorf("ReplaceAll() wrong count: %d", count)
+	}
+	prices, err := root.JSONPath("$.store.book[*].price")
+	if err != nil {
+		t.Errorf("JSONPath() unexpected error: %s", err)
+	}
+	if actual := fullPath(prices); actual != "[$['store']['book'][0]['price'], $['store']['book'][1]['price'], $['store']['book'][2]['price'], $['store']['book'][3]['price']]" {
+		t.Errorf("Wrong paths: %s", actual)
+	}
+	for i, expected := range []float64{895, 1299, 899, 2299} {
+		if value := prices[i].MustNumeric(); math.Abs(value-expected) > 1e-9 {
+			t.Errorf("Wrong value of %s: %v, expected %v", prices[i].Path(), value, expected)
+		}
+	}
+	if value := root.MustKey("store").MustKey("bicycle").MustKey("price").MustNumeric(); value != 19.95 {
+		t.Errorf("Wrong value of not matched node: %v", value)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		path     string
+		count    int
+		expected string
+	}{
+		{name: "wildcard", input: `{"a": {"x": 1}, "b": [2], "c": 3}`, path: "$.*", count: 3, expected: `{"a":"***","b":"***","c":"***"}`},
+		{name: "array wildcard", input: `[{"p": 1}, {"p": 2}, {"q": 3}]`, path: "$[*].p", count: 2, expected: `[{"p":"***"},{"p":"***"},{"q":3}]`},
+		{name: "nested matches", input: `{"a": {"b": {"c": 1}}, "d": [1, [2]]}`, path: "$..*", count: 2, expected: `{"a":"***","d":"***"}`},
+		{name: "filter", input: `[1, 5, 2, 7]`, path: "$[?(@ > 3)]", count: 2, expected: `[1,"***",2,"***"]`},
+		{name: "root", input: `{"a": 1}`, path: "$", count: 1, expected: `"***"`},
+		{name: "nothing", input: `{"a": 1}`, path: "$.b", count: 0, expected: `{"a":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			count, err := ReplaceAll(root, test.path, func(node *Node) (*Node, error) {
+				return StringNode("", "***"), nil
+			})
+			if err != nil {
+				t.Errorf("ReplaceAll() unexpected error: %s", err)
+			}
+			if count != test.count {
+				t.Errorf("ReplaceAll() wrong count: %d, expected %d", count, test.count)
+			}
+			if ok, err := root.Eq(Must(Unmarshal([]byte(test.expected)))); err != nil || !ok {
+				t.Errorf("ReplaceAll() wrong result: %s, expected %s", root.Bytes(), test.expected)
+			}
+		})
+	}
+}
+
+func TestReplaceAll_errors(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[1, 2, 3]`)))
+	count, err := ReplaceAll(root, "$[*]", func(node *Node) (*Node, error) {
+		if node.MustNumeric() == 2 {
+			return nil, fmt.Errorf("stop")
+		}
+		return NullNode(""), nil
+	})
+	if err == nil || err.Error() != "stop" || count != 1 {
+		t.Errorf("ReplaceAll() wrong result: %d, %v", count, err)
+	}
+	if actual := string(root.Bytes()); actual != `[null,2,3]` {
+		t.Errorf("ReplaceAll() wrong result: %s", actual)
+	}
+	if _, err = ReplaceAll(root, "$[", func(node *Node) (*Node, error) { return node, nil }); err == nil {
+		t.Errorf("ReplaceAll() expected error on wrong path")
+	}
+	if _, err = ReplaceAll(root, "$[*]", func(node *Node) (*Node, error) { return nil, nil }); err == nil {
+		t.Errorf("ReplaceAll() expected error on nil value")
+	}
+	if count, err = ReplaceAll(root, "$[*]", func(node *Node) (*Node, error) { return node, nil }); err != nil || count != 3 {
+		t.Errorf("ReplaceAll() wrong result for the same nodes: %d, %v", count, err)
+	}
+	root.Freeze()
+	if _, err = ReplaceAll(root, "$[*]", func(node *Node) (*Node, error) { return NullNode(""), nil }); err == nil {
+		t.Errorf("ReplaceAll() expected error on frozen node")
+	}
+}
+
+func TestRemoveMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		path     string
+		count    int
+		expected string
+	}{
+		{name: "filter", input: `[1, 5, 2, 7, 3]`, path: "$[?(@ > 2)]", count: 3, expected: `[1,2]`},
+		{name: "filter of objects", input: `{"items": [{"id": 1, "debug": true}, {"id": 2}, {"id": 3, "debug": false}]}`, path: "$.items[?(@.debug != null)]", count: 2, expected: `{"items":[{"id":2}]}`},
+		{name: "wildcard keys", input: `{"a": {"x": 1, "y": [2]}, "b": {"z": 3}}`, path: "$.a.*", count: 2, expected: `{"a":{},"b":{"z":3}}`},
+		{name: "wildcard", input: `{"a": 1, "b": [2], "c": {"d": 3}}`, path: "$.*", count: 3, expected: `{}`},
+		{name: "glob keys", input: `{"debug_a": 1, "debug_b": {"c": 2}, "name": "foo"}`, path: "$.debug_*", count: 2, expected: `{"name":"foo"}`},
+		{name: "descent", input: `{"debug": 1, "data": [{"debug": 2, "id": 1}, {"internal": {"debug": 3}}]}`, path: "$..debug", count: 3, expected: `{"data":[{"id":1},{"internal":{}}]}`},
+		{name: "nested matches", input: `{"a": {"b": {"c": 1}}, "d": [1, [2]]}`, path: "$..*", count: 2, expected: `{}`},
+		{name: "slice", input: `[0, 1, 2, 3, 4, 5]`, path: "$[::2]", count: 3, expected: `[1,3,5]`},
+		{name: "union", input: `[0, 1, 2, 3]`, path: "$[0,-1]", count: 2, expected: `[1,2]`},
+		{name: "length", input: `{"a": [1, 2]}`, path: "$.a.length", count: 0, expected: `{"a":[1,2]}`},
+		{name: "nothing", input: `{"a": 1}`, path: "$.b", count: 0, expected: `{"a":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			count, err := RemoveMatches(root, test.path)
+			if err != nil {
+				t.Errorf("RemoveMatches() unexpected error: %s", err)
+			}
+			if count != test.count {
+				t.Errorf("RemoveMatches() wrong count: %d, expected %d", count, test.count)
+			}
+			if ok, err := root.Eq(Must(Unmarshal([]byte(test.expected)))); err != nil || !ok {
+				t.Errorf("RemoveMatches() wrong result: %s, expected %s", root.Bytes(), test.expected)
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`{"items": [{"id": 1}, {"id": 2, "tmp": 1}, {"id": 3, "tmp": 1}, {"id": 4}]}`)))
+	if count, err := RemoveMatches(root, "$.items[?(@.tmp)]"); err != nil || count != 2 {
+		t.Errorf("RemoveMatches() wrong result: %d, %v", count, err)
+	}
+	for i, item := range root.MustKey("items").MustArray() {
+		if item.Index() != i || item.Path() != fmt.Sprintf("$['items'][%d]", i) {
+			t.Errorf("RemoveMatches() wrong index of the element %d: %s", i, item.Path())
+		}
+	}
+	if actual := string(root.Bytes()); actual != `{"items":[{"id":1},{"id":4}]}` {
+		t.Errorf("RemoveMatches() wrong result: %s", actual)
+	}
+
+	if _, err := RemoveMatches(root, "$.items["); err == nil {
+		t.Errorf("RemoveMatches() expected error on wrong path")
+	}
+	if count, err := RemoveMatches(root, "$..*"); err != nil || count != 1 {
+		t.Errorf("RemoveMatches() wrong result of the descent: %d, %v", count, err)
+	}
+	root = Must(Unmarshal([]byte(`{"a": 1}`)))
+	if count, err := RemoveMatches(root, "$..[?(@ == 1)]"); err != nil || count != 1 {
+		t.Errorf("RemoveMatches() wrong result: %d, %v", count, err)
+	}
+	root = Must(Unmarshal([]byte(`{"a": 1}`)))
+	if count, err := RemoveMatches(root, "$.."); err == nil || count != 0 {
+		t.Errorf("RemoveMatches() expected error on the root node: %d, %v", count, err)
+	}
+	if string(root.Bytes()) != `{"a":1}` {
+		t.Errorf("RemoveMatches() should not remove anything with the root node: %s", root.Bytes())
+	}
+	if _, err := RemoveMatches(root, "$"); err == nil {
+		t.Errorf("RemoveMatches() expected error on the root node")
+	}
+	root.Freeze()
+	if _, err := RemoveMatches(root, "$.a"); err == nil {
+		t.Errorf("RemoveMatches() expected error on frozen node")
+	}
+}
+
+func TestNode_Pick(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		keys     []string
+		expected string
+		fail     bool
+	}{
+		{name: "present", json: `{"id":1,"name":"foo","secret":"bar"}`, keys: []string{"id", "name"}, expected: `{"id":1,"name":"foo"}`},
+		{name: "mixed", json: `{"id":1,"name":"foo","secret":"bar"}`, keys: []string{"id", "email", "name", "id"}, expected: `{"id":1,"name":"foo"}`},
+		{name: "absent", json: `{"id":1}`, keys: []string{"email"}, expected: `{}`},
+		{name: "none", json: `{"id":1}`, keys: nil, expected: `{}`},
+		{name: "nested", json: `{"user":{"id":1,"tags":["a"]},"meta":null}`, keys: []string{"user"}, expected: `{"user":{"id":1,"tags":["a"]}}`},
+		{name: "array", json: `[1,2]`, keys: []string{"0"}, fail: true},
+		{name: "scalar", json: `"id"`, keys: []string{"id"}, fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			result, err := root.Pick(test.keys...)
+			if test.fail {
+				if err == nil {
+					t.Errorf("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			actual, _ := result.Unpack()
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Pick() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+			if root.IsDirty() || string(root.Bytes()) != test.json {
+				t.Errorf("Pick() changed the original node")
+			}
+		})
+	}
+}
+
+func TestNode_Omit(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		keys     []string
+		expected string
+		fail     bool
+	}{
+		{name: "present", json: `{"id":1,"name":"foo","secret":"bar"}`, keys: []string{"secret"}, expected: `{"id":1,"name":"foo"}`},
+		{name: "mixed", json: `{"id":1,"name":"foo","secret":"bar"}`, keys: []string{"secret", "email", "secret"}, expected: `{"id":1,"name":"foo"}`},
+		{name: "all", json: `{"id":1}`, keys: []string{"id"}, expected: `{}`},
+		{name: "none", json: `{"id":1}`, keys: nil, expected: `{"id":1}`},
+		{name: "nested", json: `{"user":{"id":1,"tags":["a"]},"meta":null}`, keys: []string{"meta"}, expected: `{"user":{"id":1,"tags":["a"]}}`},
+		{name: "array", json: `[1,2]`, keys: []string{"0"}, fail: true},
+		{name: "null", json: `null`, keys: []string{"id"}, fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			result, err := root.Omit(test.keys...)
+			if test.fail {
+				if err == nil {
+					t.Errorf("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			actual, _ := result.Unpack()
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Omit() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+			if root.IsDirty() || string(root.Bytes()) != test.json {
+				t.Errorf("Omit() changed the original node")
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	document := `{
+		"meta": {"version": 2, "debug": {"trace": [1, 2]}},
+		"users": [
+			{"id": 1, "name": "foo", "roles": ["admin"], "address": {"city": "Paris", "zip": "75000"}},
+			{"id": 2, "name": "bar", "roles": []},
+			{"id": 3, "name": "baz", "address": {"city": "Berlin"}}
+		],
+		"total": 3
+	}`
+	tests := []struct {
+		name     string
+		paths    []string
+		expected string
+	}{
+		{name: "two disjoint paths", paths: []string{"$.meta.version", "$.users[*].address.city"}, expected: `{"meta":{"version":2},"users":[{"address":{"city":"Paris"}},{"address":{"city":"Berlin"}}]}`},
+		{name: "subtree", paths: []string{"$.meta.debug", "$.total"}, expected: `{"meta":{"debug":{"trace":[1,2]}},"total":3}`},
+		{name: "renumbered", paths: []string{"$.users[?(@.id > 1)].name"}, expected: `{"users":[{"name":"bar"},{"name":"baz"}]}`},
+		{name: "overlapped", paths: []string{"$.users[0]", "$.users[0].name", "$.users[2].id"}, expected: `{"users":[{"id":1,"name":"foo","roles":["admin"],"address":{"city":"Paris","zip":"75000"}},{"id":3}]}`},
+		{name: "empty array", paths: []string{"$.users[1].roles"}, expected: `{"users":[{"roles":[]}]}`},
+		{name: "array elements", paths: []string{"$.meta.debug.trace[-1]"}, expected: `{"meta":{"debug":{"trace":[2]}}}`},
+		{name: "length", paths: []string{"$.users.length", "$.total"}, expected: `{"total":3}`},
+		{name: "root", paths: []string{"$"}, expected: strings.Join(strings.Fields(document), "")},
+		{name: "nothing", paths: []string{"$.missing"}, expected: `{}`},
+		{name: "no paths", paths: nil, expected: `{}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(document)))
+			result, err := Prune(root, test.paths)
+			if err != nil {
+				t.Errorf("Prune() unexpected error: %s", err)
+				return
+			}
+			if actual := string(result.Bytes()); actual != test.expected {
+				t.Errorf("Prune() wrong result\nExpected: %s\nActual:   %s", test.expected, actual)
+			}
+			if result.Parent() != nil || result.Path() != "$" {
+				t.Errorf("Prune() result should be a new root: %s", result.Path())
+			}
+			if original, _ := Marshal(root); string(original) != document {
+				t.Errorf("Prune() should not change the root node")
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(document)))
+	result, err := Prune(root, []string{"$.users[*].address.city", "$.users[2].id"})
+	if err != nil {
+		t.Errorf("Prune() unexpected error: %s", err)
+		return
+	}
+	if path := result.MustKey("users").MustIndex(1).MustKey("address").Path(); path != "$['users'][1]['address']" {
+		t.Errorf("Prune() wrong path of the renumbered element: %s", path)
+	}
+	_ = result.MustKey("users").MustIndex(0).MustKey("address").MustKey("city").SetString("Lyon")
+	if root.MustKey("users").MustIndex(0).MustKey("address").MustKey("city").MustString() != "Paris" {
+		t.Errorf("Prune() result should be a copy")
+	}
+
+	if result, err = Prune(NumericNode("", 1), []string{"$.a"}); err != nil || !result.IsNull() {
+		t.Errorf("Prune() wrong result of the scalar root: %v, %v", result, err)
+	}
+	if result, err = Prune(NumericNode("", 1), []string{"$"}); err != nil || result.MustNumeric() != 1 {
+		t.Errorf("Prune() wrong result of the kept scalar root: %v, %v", result, err)
+	}
+	if _, err = Prune(root, []string{"$.users", "$.users["}); err == nil {
+		t.Errorf("Prune() expected error on wrong path")
+	}
+}
+
+func TestNode_Pick_copy(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"user":{"id":1},"meta":null}`)))
+	result, err := root.Pick("user")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+	if err = result.MustKey("user").AppendObject("name", StringNode("", "foo")); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if root.MustKey("user").HasKey("name") {
+		t.Errorf("Pick() result shares nodes with the original")
+	}
+	if result.Parent() != nil || result.MustKey("user").Path() != "$['user']" {
+		t.Errorf("Pick() wrong result path: %s", result.MustKey("user").Path())
+	}
+}
+
 func ExampleNode_Clone() {
 	root := Must(Unmarshal(jsonPathTestData))
 	nodes, _ := root.JSONPath("$..price")
@@ -1479,3 +2233,308 @@ func ExampleNode_Clone() {
 	// }
 	//
 }
+
+func TestNode_Merge(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		other    string
+		options  []MergeOption
+		expected string
+		wantErr  bool
+	}{
+		{name: "empty", base: `{}`, other: `{}`, expected: `{}`},
+		{name: "add keys", base: `{"a":1}`, other: `{"b":2}`, expected: `{"a":1,"b":2}`},
+		{name: "override scalar", base: `{"a":1,"b":"foo"}`, other: `{"b":"bar"}`, expected: `{"a":1,"b":"bar"}`},
+		{name: "override type", base: `{"a":{"b":1}}`, other: `{"a":[1,2]}`, expected: `{"a":[1,2]}`},
+		{name: "override with null", base: `{"a":{"b":1}}`, other: `{"a":null}`, expected: `{"a":null}`},
+		{name: "nested", base: `{"a":{"b":{"c":1,"d":2},"e":true}}`, other: `{"a":{"b":{"d":3,"f":4}}}`, expected: `{"a":{"b":{"c":1,"d":3,"f":4},"e":true}}`},
+		{name: "array replace", base: `{"a":[1,2,3]}`, other: `{"a":[4]}`, expected: `{"a":[4]}`},
+		{name: "array concat", base: `{"a":[1,2,3]}`, other: `{"a":[4]}`, options: []MergeOption{MergeArrayConcat}, expected: `{"a":[1,2,3,4]}`},
+		{name: "root array replace", base: `[1,2]`, other: `[{"a":3}]`, expected: `[{"a":3}]`},
+		{name: "root array concat", base: `[1,2]`, other: `[{"a":3}]`, options: []MergeOption{MergeArrayConcat}, expected: `[1,2,{"a":3}]`},
+		{name: "scalars", base: `1`, other: `"foo"`, expected: `"foo"`},
+		{name: "object and array", base: `{}`, other: `[]`, wantErr: true},
+		{name: "array and scalar", base: `[]`, other: `1`, wantErr: true},
+		{name: "scalar and object", base: `true`, other: `{}`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base := Must(Unmarshal([]byte(test.base)))
+			other := Must(Unmarshal([]byte(test.other)))
+			err := base.Merge(other, test.options...)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Merge() error = %v, wantErr %v", err, test.wantErr)
+				return
+			}
+			if test.wantErr {
+				return
+			}
+			actual, err := base.Unpack()
+			if err != nil {
+				t.Errorf("Unpack() error: %s", err)
+				return
+			}
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Merge() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+			if result, err := Marshal(other); err != nil {
+				t.Errorf("Marshal() error: %s", err)
+			} else if string(result) != test.other {
+				t.Errorf("Merge() other node was changed: %s", result)
+			}
+		})
+	}
+}
+
+func TestNode_Merge_loop(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a":{"b":1}}`)))
+	if err := root.Merge(root.MustKey("a")); err == nil {
+		t.Errorf("Merge() expected error on child node")
+	}
+	if err := root.MustKey("a").Merge(root); err == nil {
+		t.Errorf("Merge() expected error on parent node")
+	}
+}
+
+func TestNode_Freeze(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":[1,{"bar":null}],"baz":"qux"}`)))
+	root.Freeze()
+	array := root.MustKey("foo")
+	object := array.MustIndex(1)
+	for _, node := range []*Node{root, array, object, object.MustKey("bar")} {
+		if !node.IsFrozen() {
+			t.Errorf("IsFrozen() node %s is not frozen", node.Path())
+		}
+	}
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "SetNull", fn: func() error { return root.MustKey("baz").SetNull() }},
+		{name: "SetNumeric", fn: func() error { return root.MustKey("baz").SetNumeric(1) }},
+		{name: "SetString", fn: func() error { return root.MustKey("baz").SetString("") }},
+		{name: "SetBool", fn: func() error { return root.MustKey("baz").SetBool(true) }},
+		{name: "SetArray", fn: func() error { return array.SetArray(nil) }},
+		{name: "SetObject", fn: func() error { return object.SetObject(nil) }},
+		{name: "AppendArray", fn: func() error { return array.AppendArray(NullNode("")) }},
+		{name: "AppendArray empty", fn: func() error { return array.AppendArray() }},
+		{name: "AppendObject", fn: func() error { return object.AppendObject("new", NullNode("")) }},
+		{name: "AppendObject frozen value", fn: func() error { return ObjectNode("", nil).AppendObject("new", root) }},
+		{name: "AppendArray frozen child", fn: func() error { return ArrayNode("", nil).AppendArray(object) }},
+		{name: "DeleteKey", fn: func() error { return root.DeleteKey("baz") }},
+		{name: "DeleteIndex", fn: func() error { return array.DeleteIndex(0) }},
+		{name: "DeleteNode", fn: func() error { return array.DeleteNode(object) }},
+		{name: "Delete", fn: func() error { return object.Delete() }},
+		{name: "PopKey", fn: func() error { _, err := root.PopKey("baz"); return err }},
+		{name: "PopIndex", fn: func() error { _, err := array.PopIndex(0); return err }},
+		{name: "Merge", fn: func() error { return root.Merge(Must(Unmarshal([]byte(`{"new":1}`)))) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.fn(); err == nil {
+				t.Errorf("%s() expected error on frozen node", test.name)
+			}
+		})
+	}
+	if root.IsDirty() {
+		t.Errorf("IsDirty() frozen node was marked as dirty")
+	}
+	if result, err := Marshal(array); err != nil {
+		t.Errorf("Marshal() error: %s", err)
+	} else if string(result) != `[1,{"bar":null}]` {
+		t.Errorf("Marshal() frozen node was changed: %s", result)
+	}
+
+	clone := root.Clone()
+	if clone.IsFrozen() {
+		t.Errorf("IsFrozen() clone is frozen")
+	}
+	if err := clone.MustKey("foo").AppendArray(NullNode("")); err != nil {
+		t.Errorf("AppendArray() unexpected error on clone: %s", err)
+	}
+}
+
+func TestNode_Compact(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		flags    []CompactFlag
+		expected string
+	}{
+		{name: "scalar", json: `null`, expected: `null`},
+		{name: "empty", json: `{}`, expected: `{}`},
+		{name: "object", json: `{"a":null,"b":{},"c":[],"d":1}`, expected: `{"d":1}`},
+		{name: "array", json: `[null,1,{},2,[],null,3]`, expected: `[1,2,3]`},
+		{name: "nested", json: `{"a":{"b":{"c":null,"d":[[],{}]}},"e":[null,{"f":"g","h":null}]}`, expected: `{"e":[{"f":"g"}]}`},
+		{name: "becomes empty", json: `[[null,[{}]],{"a":[null]}]`, expected: `[]`},
+		{name: "null only", json: `{"a":null,"b":{"c":null},"d":[null]}`, flags: []CompactFlag{CompactNull}, expected: `{"b":{},"d":[]}`},
+		{name: "arrays only", json: `{"a":null,"b":{"c":[]},"d":[[]]}`, flags: []CompactFlag{CompactEmptyArray}, expected: `{"a":null,"b":{}}`},
+		{name: "objects only", json: `[null,{},{"a":{}},[]]`, flags: []CompactFlag{CompactEmptyObject}, expected: `[null,[]]`},
+		{name: "combined flags", json: `[null,{},{"a":{}},[]]`, flags: []CompactFlag{CompactEmptyObject, CompactNull}, expected: `[[]]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			if err := root.Compact(test.flags...); err != nil {
+				t.Errorf("Compact() error: %s", err)
+				return
+			}
+			actual, err := root.Unpack()
+			if err != nil {
+				t.Errorf("Unpack() error: %s", err)
+				return
+			}
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Compact() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestNode_Compact_reindex(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[null,"a",null,"b"]`)))
+	if err := root.Compact(); err != nil {
+		t.Errorf("Compact() error: %s", err)
+		return
+	}
+	if result, err := Marshal(root); err != nil {
+		t.Errorf("Marshal() error: %s", err)
+	} else if string(result) != `["a","b"]` {
+		t.Errorf("Marshal() wrong result: %s", result)
+	}
+	if root.MustIndex(1).Index() != 1 || root.MustIndex(1).MustString() != "b" {
+		t.Errorf("Compact() wrong index")
+	}
+	frozen := Must(Unmarshal([]byte(`[null]`)))
+	frozen.Freeze()
+	if err := frozen.Compact(); err == nil {
+		t.Errorf("Compact() expected error on frozen node")
+	}
+}
+
+func TestNode_Truncate(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		maxArrayLen  int
+		maxStringLen int
+		expected     string
+	}{
+		{name: "scalar", json: `1`, maxArrayLen: 0, maxStringLen: 0, expected: `1`},
+		{name: "string", json: `"foobar"`, maxArrayLen: 1, maxStringLen: 3, expected: `"foo…"`},
+		{name: "short string", json: `"foo"`, maxArrayLen: 1, maxStringLen: 3, expected: `"foo"`},
+		{name: "multi-byte string", json: `"привет, мир"`, maxArrayLen: 1, maxStringLen: 6, expected: `"привет…"`},
+		{name: "emoji", json: `"👍👍👍"`, maxArrayLen: 1, maxStringLen: 2, expected: `"👍👍…"`},
+		{name: "empty string", json: `"foo"`, maxArrayLen: 1, maxStringLen: 0, expected: `"…"`},
+		{name: "array", json: `[1,2,3,4]`, maxArrayLen: 2, maxStringLen: -1, expected: `[1,2]`},
+		{name: "empty array", json: `[1,2,3,4]`, maxArrayLen: 0, maxStringLen: -1, expected: `[]`},
+		{name: "nested arrays", json: `[[1,2,3],[4,5,6],[7]]`, maxArrayLen: 2, maxStringLen: -1, expected: `[[1,2],[4,5]]`},
+		{name: "nested objects", json: `{"a":{"b":["foo","barbaz","qux"]},"c":"quux"}`, maxArrayLen: 2, maxStringLen: 3, expected: `{"a":{"b":["foo","bar…"]},"c":"quu…"}`},
+		{name: "disabled", json: `{"a":[1,2,3],"b":"foobar"}`, maxArrayLen: -1, maxStringLen: -1, expected: `{"a":[1,2,3],"b":"foobar"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			if err := root.Truncate(test.maxArrayLen, test.maxStringLen); err != nil {
+				t.Errorf("Truncate() error: %s", err)
+				return
+			}
+			actual, err := root.Unpack()
+			if err != nil {
+				t.Errorf("Unpack() error: %s", err)
+				return
+			}
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Truncate() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestNode_Truncate_reindex(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[[1,2,3],"foobar",[4,5,6]]`)))
+	if err := root.Truncate(2, 3); err != nil {
+		t.Errorf("Truncate() error: %s", err)
+		return
+	}
+	if result, err := Marshal(root); err != nil {
+		t.Errorf("Marshal() error: %s", err)
+	} else if string(result) != `[[1,2],"foo…"]` {
+		t.Errorf("Marshal() wrong result: %s", result)
+	}
+	for i, child := range root.MustArray() {
+		if child.Index() != i {
+			t.Errorf("Truncate() wrong index %d of %d", child.Index(), i)
+		}
+	}
+	if err := valueNode(nil, "", String, 1).Truncate(1, 1); err == nil {
+		t.Errorf("Truncate() expected error on wrong value")
+	}
+}
+
+func TestNode_TransformScalars(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{
+		"name": "  foo ",
+		"tags": [" a", "b ", 1.25, true, null, ["  nested  "]],
+		"user": {"email": "\tbar@example.com\n", "age": 42, "address": {"city": " Paris "}},
+		"empty": {}
+	}`)))
+	visited := make([]string, 0)
+	err := root.TransformScalars(func(node *Node) error {
+		if node.IsContainer() {
+			t.Errorf("TransformScalars() unexpected container: %s", node.Path())
+		}
+		visited = append(visited, node.Path())
+		if node.IsString() {
+			return node.SetString(strings.TrimSpace(node.MustString()))
+		}
+		if node.IsNumeric() {
+			return node.SetNumeric(math.Round(node.MustNumeric()))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("TransformScalars() unexpected error: %s", err)
+	}
+	expected := `{"name":"foo","tags":["a","b",1,true,null,["nested"]],"user":{"email":"bar@example.com","age":42,"address":{"city":"Paris"}},"empty":{}}`
+	if actual := string(root.Bytes()); actual != expected {
+		t.Errorf("TransformScalars() wrong result\nExpected: %s\nActual:   %s", expected, actual)
+	}
+	if len(visited) != 10 || visited[0] != "$['name']" || visited[1] != "$['tags'][0]" {
+		t.Errorf("TransformScalars() wrong visited nodes: %v", visited)
+	}
+
+	count := 0
+	err = root.TransformScalars(func(node *Node) error {
+		count++
+		if node.IsBool() {
+			return node.SetArray([]*Node{StringNode("", "new")})
+		}
+		return nil
+	})
+	if err != nil || count != 10 {
+		t.Errorf("TransformScalars() should not visit the new children: %d, %v", count, err)
+	}
+
+	scalar := StringNode("", " x ")
+	if err = scalar.TransformScalars(func(node *Node) error { return node.SetString("y") }); err != nil || scalar.MustString() != "y" {
+		t.Errorf("TransformScalars() wrong result of the scalar: %s, %v", scalar, err)
+	}
+	err = root.TransformScalars(func(node *Node) error {
+		if node.IsNull() {
+			return fmt.Errorf("stop at %s", node.Path())
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "stop at $['tags'][4]" {
+		t.Errorf("TransformScalars() wrong error: %v", err)
+	}
+	root.Freeze()
+	if err = root.TransformScalars(func(node *Node) error { return node.SetNull() }); err == nil {
+		t.Errorf("TransformScalars() expected error on frozen node")
+	}
+}
@@ -3,8 +3,12 @@ package ajson
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"math"
+	"math/big"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -85,6 +89,109 @@ func TestNode_Unpack(t *testing.T) {
 	}
 }
 
+func TestNode_AsMap(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":{"bar":[1,"baz",null,true,{"qux":[]}]},"quux":1.5}`)))
+	value, err := root.AsMap()
+	if err != nil {
+		t.Errorf("AsMap(): unexpected error: %s", err.Error())
+		return
+	}
+	expected := map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": []interface{}{float64(1), "baz", nil, true, map[string]interface{}{"qux": []interface{}{}}},
+		},
+		"quux": 1.5,
+	}
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("AsMap(): wrong value:\nExpected: %#+v\nActual:   %#+v", expected, value)
+	}
+	if _, err = root.MustKey("quux").AsMap(); err == nil {
+		t.Errorf("AsMap(): expected error on Numeric node")
+	}
+	if _, err = root.MustKey("foo").MustKey("bar").AsMap(); err == nil {
+		t.Errorf("AsMap(): expected error on Array node")
+	}
+}
+
+func TestNode_AsSlice(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[1,"foo",null,false,{"bar":[2,{"baz":"qux"}]},[]]`)))
+	value, err := root.AsSlice()
+	if err != nil {
+		t.Errorf("AsSlice(): unexpected error: %s", err.Error())
+		return
+	}
+	expected := []interface{}{
+		float64(1), "foo", nil, false,
+		map[string]interface{}{"bar": []interface{}{float64(2), map[string]interface{}{"baz": "qux"}}},
+		[]interface{}{},
+	}
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("AsSlice(): wrong value:\nExpected: %#+v\nActual:   %#+v", expected, value)
+	}
+	if _, err = root.MustIndex(4).AsSlice(); err == nil {
+		t.Errorf("AsSlice(): expected error on Object node")
+	}
+	if _, err = valueNode(nil, "", Array, nil).AsSlice(); err != nil {
+		t.Errorf("AsSlice(): unexpected error on empty Array node: %s", err.Error())
+	}
+}
+
+func TestNode_Unmarshal(t *testing.T) {
+	type Author struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	type Book struct {
+		Title   string   `json:"title"`
+		Price   float64  `json:"price"`
+		Authors []Author `json:"authors"`
+		ISBN    *string  `json:"isbn"`
+	}
+	root := Must(Unmarshal([]byte(`{"store": {"books": [
+		{"title": "foo", "price": 8.95, "authors": [{"name": "bar", "tags": ["a", "b"]}], "isbn": null},
+		{"title": "baz", "price": 12, "authors": [], "extra": true}
+	]}}`)))
+
+	var books []Book
+	if err := root.MustKey("store").MustKey("books").Unmarshal(&books); err != nil {
+		t.Errorf("Unmarshal() error: %s", err)
+		return
+	}
+	expected := []Book{
+		{Title: "foo", Price: 8.95, Authors: []Author{{Name: "bar", Tags: []string{"a", "b"}}}},
+		{Title: "baz", Price: 12, Authors: []Author{}},
+	}
+	if !reflect.DeepEqual(books, expected) {
+		t.Errorf("Unmarshal() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, books)
+	}
+
+	nodes, err := root.JSONPath(`$..authors[0]`)
+	if err != nil || len(nodes) != 1 {
+		t.Errorf("JSONPath() error: %v", err)
+		return
+	}
+	if err = nodes[0].MustKey("tags").MustIndex(1).SetString("c"); err != nil {
+		t.Errorf("SetString() error: %s", err)
+	}
+	var author Author
+	if err = nodes[0].Unmarshal(&author); err != nil {
+		t.Errorf("Unmarshal() error: %s", err)
+	} else if !reflect.DeepEqual(author, Author{Name: "bar", Tags: []string{"a", "c"}}) {
+		t.Errorf("Unmarshal() wrong result of changed node: %#+v", author)
+	}
+
+	var number int
+	if err = NumericNode("", 2).Unmarshal(&number); err != nil || number != 2 {
+		t.Errorf("Unmarshal() wrong result of created node: %d, %v", number, err)
+	}
+	if err = root.Unmarshal(&books); err == nil {
+		t.Errorf("Unmarshal() expected error on wrong type")
+	}
+	if err = valueNode(nil, "", Numeric, "foo").Unmarshal(&number); err == nil {
+		t.Errorf("Unmarshal() expected error on wrong value")
+	}
+}
+
 func TestNode_getValue(t *testing.T) {
 	root, err := Unmarshal([]byte(`{ "category": null,
         "author": "Evelyn Waugh",
@@ -283,6 +390,79 @@ func TestNode_MustKey(t *testing.T) {
 	}
 }
 
+func TestNode_Get(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": {"b": [{"c": 1}, {"c": 2}, {"c": [3, [4, 5]]}]}, "d.e": 6, "": 7}`)))
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "empty", path: "", expected: "$"},
+		{name: "key", path: "a", expected: "$['a']"},
+		{name: "deep", path: "a.b[2].c", expected: "$['a']['b'][2]['c']"},
+		{name: "nested indices", path: "a.b[2].c[1][0]", expected: "$['a']['b'][2]['c'][1][0]"},
+		{name: "negative index", path: "a.b[-1]", expected: "$['a']['b'][2]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := root.Get(test.path)
+			if err != nil {
+				t.Errorf("Error on root.Get(%s): %s", test.path, err.Error())
+			} else if value.Path() != test.expected {
+				t.Errorf("root.Get(%s) wrong node: %s, expected %s", test.path, value.Path(), test.expected)
+			}
+		})
+	}
+
+	for _, path := range []string{"b", "a.c", "a.b[3]", "a.b[0].c.d", "a[0]", "a.b.c", "d.e"} {
+		value, err := root.Get(path)
+		if err == nil || err.Error() != "wrong request: path not found: "+path {
+			t.Errorf("root.Get(%s) expected not found error, got: %v", path, err)
+		}
+		if value != nil {
+			t.Errorf("root.Get(%s) wrong value", path)
+		}
+	}
+	for _, path := range []string{".a", "a.", "a..b", "a.b[", "a.b[x]", "a.b[]", "a.b[0]c"} {
+		if _, err := root.Get(path); err == nil || err.Error() != "wrong request: wrong path: "+path {
+			t.Errorf("root.Get(%s) expected wrong path error, got: %v", path, err)
+		}
+	}
+}
+
+func TestNode_Coalesce(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"displayName": null, "profile": {"name": null, "nick": ""}, "login": "foo", "list": [null, 0]}`)))
+	tests := []struct {
+		name     string
+		paths    []string
+		expected string
+	}{
+		{name: "fallback", paths: []string{"displayName", "name", "profile.name", "login"}, expected: "$['login']"},
+		{name: "empty string", paths: []string{"profile.name", "profile.nick", "login"}, expected: "$['profile']['nick']"},
+		{name: "first", paths: []string{"login", "displayName"}, expected: "$['login']"},
+		{name: "wrong path", paths: []string{"profile..name", "list[x]", "list[0]", "list[1]"}, expected: "$['list'][1]"},
+		{name: "container", paths: []string{"missing", "profile"}, expected: "$['profile']"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if node := root.Coalesce(test.paths...); node == nil {
+				t.Errorf("root.Coalesce() node not found")
+			} else if node.Path() != test.expected {
+				t.Errorf("root.Coalesce() wrong node: %s, expected %s", node.Path(), test.expected)
+			}
+		})
+	}
+	if node := root.Coalesce("displayName", "profile.name", "missing", "list[0]"); node != nil {
+		t.Errorf("root.Coalesce() expected nil, got %s", node.Path())
+	}
+	if node := root.Coalesce(); node != nil {
+		t.Errorf("root.Coalesce() expected nil without paths")
+	}
+	if node := root.MustKey("profile").Coalesce("name", "nick"); node == nil || node.MustString() != "" {
+		t.Errorf("Coalesce() wrong relative node")
+	}
+}
+
 func TestNode_GetNull(t *testing.T) {
 	root, err := Unmarshal([]byte(`null`))
 	if err != nil {
@@ -316,6 +496,98 @@ func TestNode_MustNull(t *testing.T) {
 	}
 }
 
+func TestNode_BigInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		fail     bool
+	}{
+		{name: "50 digits", input: `12345678901234567890123456789012345678901234567890`, expected: "12345678901234567890123456789012345678901234567890"},
+		{name: "negative", input: `-98765432109876543210987654321`, expected: "-98765432109876543210987654321"},
+		{name: "zero", input: `0`, expected: "0"},
+		{name: "exponent", input: `1e30`, expected: "1000000000000000000000000000000"},
+		{name: "fraction of zeros", input: `12345678901234567890.000`, expected: "12345678901234567890"},
+		{name: "fraction with exponent", input: `1.25E+2`, expected: "125"},
+		{name: "fraction", input: `1.5`, fail: true},
+		{name: "small", input: `1e-3`, fail: true},
+		{name: "string", input: `"1"`, fail: true},
+		{name: "null", input: `null`, fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := Must(Unmarshal([]byte(test.input))).BigInt()
+			if test.fail {
+				if err == nil {
+					t.Errorf("BigInt() expected error, got: %s", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("BigInt() unexpected error: %s", err)
+			} else if value.String() != test.expected {
+				t.Errorf("BigInt() wrong value: %s, expected: %s", value, test.expected)
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`[12345678901234567890123456789012345678901234567890]`)))
+	if value, err := root.MustIndex(0).BigInt(); err != nil || value.String() != "12345678901234567890123456789012345678901234567890" {
+		t.Errorf("BigInt() wrong value of the child: %s, %v", value, err)
+	}
+	if value, err := NumericNode("", 1e20).BigInt(); err != nil || value.String() != "100000000000000000000" {
+		t.Errorf("BigInt() wrong value of the modified node: %s, %v", value, err)
+	}
+	if _, err := NumericNode("", math.Inf(1)).BigInt(); err == nil {
+		t.Errorf("BigInt() expected error on non-finite number")
+	}
+}
+
+func TestNode_BigFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "high precision", input: `0.1000000000000000000000000000000000000001`, expected: "0.1000000000000000000000000000000000000001"},
+		{name: "pi", input: `3.14159265358979323846264338327950288419716939937510`, expected: "3.14159265358979323846264338327950288419716939937510"},
+		{name: "negative exponent", input: `-1.23456789012345678901234567890e-10`, expected: "-1.23456789012345678901234567890e-10"},
+		{name: "integer", input: `12345678901234567890123456789012345678901234567890`, expected: "1.2345678901234567890123456789012345678901234567890e+49"},
+		{name: "short", input: `1.5`, expected: "1.5"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := Must(Unmarshal([]byte(test.input)))
+			value, err := node.BigFloat()
+			if err != nil {
+				t.Errorf("BigFloat() unexpected error: %s", err)
+				return
+			}
+			expected, _, _ := big.ParseFloat(test.expected, 10, value.Prec(), big.ToNearestEven)
+			if value.Cmp(expected) != 0 {
+				t.Errorf("BigFloat() wrong value: %s, expected: %s", value.Text('g', 60), test.expected)
+			}
+			if float, _ := value.Float64(); float != node.MustNumeric() {
+				t.Errorf("BigFloat() wrong float64 value: %v", float)
+			}
+		})
+	}
+
+	value, err := Must(Unmarshal([]byte(`0.1000000000000000000000000000000000000001`))).BigFloat()
+	if err != nil || value.Text('f', 40) != "0.1000000000000000000000000000000000000001" {
+		t.Errorf("BigFloat() precision is lost: %s, %v", value.Text('f', 40), err)
+	}
+	if value, err = NumericNode("", 0.1).BigFloat(); err != nil || value.Text('g', -1) != "0.1" {
+		t.Errorf("BigFloat() wrong value of the modified node: %v, %v", value, err)
+	}
+	if _, err = StringNode("", "0.1").BigFloat(); err == nil {
+		t.Errorf("BigFloat() expected error on string")
+	}
+	if _, err = Must(Unmarshal([]byte(`NaN`), UnmarshalNonFinite)).BigFloat(); err == nil {
+		t.Errorf("BigFloat() expected error on NaN")
+	}
+}
+
 func TestNode_GetNumeric(t *testing.T) {
 	root, err := Unmarshal([]byte(`123`))
 	if err != nil {
@@ -343,6 +615,90 @@ func TestNode_GetNumeric(t *testing.T) {
 	}
 }
 
+func TestNode_GetInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *Node
+		expected int64
+		wantErr  bool
+	}{
+		{name: "integer", node: Must(Unmarshal([]byte(`123`))), expected: 123},
+		{name: "negative", node: Must(Unmarshal([]byte(`-123`))), expected: -123},
+		{name: "exponent", node: Must(Unmarshal([]byte(`1.5e3`))), expected: 1500},
+		{name: "zero fraction", node: Must(Unmarshal([]byte(`2.0`))), expected: 2},
+		{name: "max", node: Must(Unmarshal([]byte(`9223372036854775807`))), expected: math.MaxInt64},
+		{name: "min", node: Must(Unmarshal([]byte(`-9223372036854775808`))), expected: math.MinInt64},
+		{name: "created", node: NumericNode("", 1<<53), expected: 1 << 53},
+		{name: "created min", node: NumericNode("", math.MinInt64), expected: math.MinInt64},
+		{name: "fraction", node: Must(Unmarshal([]byte(`1.5`))), wantErr: true},
+		{name: "created fraction", node: NumericNode("", -0.5), wantErr: true},
+		{name: "overflow", node: Must(Unmarshal([]byte(`1e20`))), wantErr: true},
+		{name: "max overflow", node: Must(Unmarshal([]byte(`9223372036854775808`))), wantErr: true},
+		{name: "min overflow", node: Must(Unmarshal([]byte(`-9223372036854777856`))), wantErr: true},
+		{name: "created overflow", node: NumericNode("", math.MaxInt64), wantErr: true},
+		{name: "infinity", node: NumericNode("", math.Inf(1)), wantErr: true},
+		{name: "string", node: StringNode("", "1"), wantErr: true},
+		{name: "wrong data", node: valueNode(nil, "", Numeric, "foo"), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := test.node.GetInt()
+			if (err != nil) != test.wantErr {
+				t.Errorf("GetInt() error = %v, wantErr %v", err, test.wantErr)
+			} else if value != test.expected {
+				t.Errorf("GetInt() wrong result: expected %d, got %d", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestNode_GetUint(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *Node
+		expected uint64
+		wantErr  bool
+	}{
+		{name: "integer", node: Must(Unmarshal([]byte(`123`))), expected: 123},
+		{name: "zero", node: Must(Unmarshal([]byte(`-0`))), expected: 0},
+		{name: "exponent", node: Must(Unmarshal([]byte(`1E3`))), expected: 1000},
+		{name: "max", node: Must(Unmarshal([]byte(`18446744073709551615`))), expected: math.MaxUint64},
+		{name: "max int64", node: Must(Unmarshal([]byte(`9223372036854775808`))), expected: 1 << 63},
+		{name: "created", node: NumericNode("", 1<<63), expected: 1 << 63},
+		{name: "negative", node: Must(Unmarshal([]byte(`-1`))), wantErr: true},
+		{name: "fraction", node: Must(Unmarshal([]byte(`0.5`))), wantErr: true},
+		{name: "overflow", node: Must(Unmarshal([]byte(`18446744073709551616`))), wantErr: true},
+		{name: "created overflow", node: NumericNode("", math.MaxUint64), wantErr: true},
+		{name: "string", node: StringNode("", "1"), wantErr: true},
+		{name: "wrong data", node: valueNode(nil, "", Numeric, "foo"), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := test.node.GetUint()
+			if (err != nil) != test.wantErr {
+				t.Errorf("GetUint() error = %v, wantErr %v", err, test.wantErr)
+			} else if value != test.expected {
+				t.Errorf("GetUint() wrong result: expected %d, got %d", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestNode_GetFloat(t *testing.T) {
+	value, err := Must(Unmarshal([]byte(`1.5`))).GetFloat()
+	if err != nil {
+		t.Errorf("Error on root.GetFloat(): %s", err.Error())
+	} else if value != 1.5 {
+		t.Errorf("root.GetFloat() is corrupted")
+	}
+	if _, err = BoolNode("", true).GetFloat(); err == nil {
+		t.Errorf("Error on root.GetFloat() using BoolNode")
+	}
+	if _, err = Must(Unmarshal([]byte(`1e400`))).GetFloat(); err == nil {
+		t.Errorf("Error on root.GetFloat() overflow")
+	}
+}
+
 func TestNode_MustNumeric(t *testing.T) {
 	root, err := Unmarshal([]byte(`123`))
 	if err != nil {
@@ -559,6 +915,40 @@ func TestNode_IsNumeric(t *testing.T) {
 	}
 }
 
+func TestNode_IsInt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: `5`, expected: true},
+		{input: `5.0`, expected: true},
+		{input: `-5.00`, expected: true},
+		{input: `5e2`, expected: true},
+		{input: `0`, expected: true},
+		{input: `12345678901234567890123`, expected: true},
+		{input: `5.5`, expected: false},
+		{input: `5e-1`, expected: false},
+		{input: `-1.23e-2`, expected: false},
+		{input: `"5"`, expected: false},
+		{input: `true`, expected: false},
+		{input: `null`, expected: false},
+		{input: `[5]`, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			if actual := Must(Unmarshal([]byte(test.input))).IsInt(); actual != test.expected {
+				t.Errorf("IsInt() wrong result: %v, expected %v", actual, test.expected)
+			}
+		})
+	}
+	if !NumericNode("", 10).IsInt() || NumericNode("", 0.1).IsInt() {
+		t.Errorf("IsInt() wrong result for the created nodes")
+	}
+	if (&Node{_type: Numeric}).IsInt() {
+		t.Errorf("IsInt() wrong result for the broken node")
+	}
+}
+
 func TestNode_IsBool(t *testing.T) {
 	root, err := Unmarshal([]byte(`true`))
 	if err != nil {
@@ -611,6 +1001,37 @@ func TestNode_IsNull(t *testing.T) {
 	}
 }
 
+func TestNode_IsContainer(t *testing.T) {
+	tests := []struct {
+		json      string
+		container bool
+	}{
+		{json: `null`, container: false},
+		{json: `1`, container: false},
+		{json: `"foo"`, container: false},
+		{json: `true`, container: false},
+		{json: `[]`, container: true},
+		{json: `[1]`, container: true},
+		{json: `{}`, container: true},
+		{json: `{"foo":1}`, container: true},
+	}
+	for _, test := range tests {
+		t.Run(test.json, func(t *testing.T) {
+			root, err := Unmarshal([]byte(test.json))
+			if err != nil {
+				t.Errorf("Error on Unmarshal(): %s", err.Error())
+				return
+			}
+			if root.IsContainer() != test.container {
+				t.Errorf("Wrong root.IsContainer()")
+			}
+			if root.IsLeaf() == test.container {
+				t.Errorf("Wrong root.IsLeaf()")
+			}
+		})
+	}
+}
+
 func TestNode_Keys(t *testing.T) {
 	root, err := Unmarshal([]byte(`{"foo":true,"bar":null}`))
 	if err != nil {
@@ -627,6 +1048,38 @@ func TestNode_Keys(t *testing.T) {
 	if value[1] != "foo" && value[1] != "bar" {
 		t.Errorf("Wrong value in 1")
 	}
+
+	root = Must(Unmarshal([]byte(`{"zeta": 1, "alpha": 2, "mid": 3, "beta": 4}`)))
+	if err = root.AppendObject("gamma", NullNode("")); err != nil {
+		t.Errorf("AppendObject() unexpected error: %s", err)
+	}
+	if err = root.DeleteKey("mid"); err != nil {
+		t.Errorf("DeleteKey() unexpected error: %s", err)
+	}
+	if expected := []string{"zeta", "alpha", "beta", "gamma"}; !sliceEqual(root.Keys(), expected) {
+		t.Errorf("Keys() wrong order\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(root.Keys()))
+	}
+	array := Must(Unmarshal([]byte(`[0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10]`)))
+	if expected := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}; !sliceEqual(array.Keys(), expected) {
+		t.Errorf("Keys() wrong order of the array\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(array.Keys()))
+	}
+	if len(NumericNode("", 1).Keys()) != 0 {
+		t.Errorf("Keys() of the scalar should be empty")
+	}
+}
+
+func TestNode_KeysSorted(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"zeta": 1, "alpha": 2, "Mid": 3, "beta": 4, "10": 5, "9": 6}`)))
+	if expected := []string{"10", "9", "Mid", "alpha", "beta", "zeta"}; !sliceEqual(root.KeysSorted(), expected) {
+		t.Errorf("KeysSorted() wrong order\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(root.KeysSorted()))
+	}
+	if expected := []string{"zeta", "alpha", "Mid", "beta", "10", "9"}; !sliceEqual(root.Keys(), expected) {
+		t.Errorf("Keys() wrong order\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(root.Keys()))
+	}
+	array := Must(Unmarshal([]byte(`[0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10]`)))
+	if !sliceEqual(array.KeysSorted(), array.Keys()) {
+		t.Errorf("KeysSorted() wrong order of the array: %s", sliceString(array.KeysSorted()))
+	}
 }
 
 func TestNode_Size(t *testing.T) {
@@ -641,6 +1094,32 @@ func TestNode_Size(t *testing.T) {
 	}
 }
 
+func TestNode_Depth(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected int
+	}{
+		{name: "null", json: `null`, expected: 0},
+		{name: "string", json: `"foo"`, expected: 0},
+		{name: "empty array", json: `[]`, expected: 1},
+		{name: "empty object", json: `{}`, expected: 1},
+		{name: "object of scalars", json: `{"foo":1,"bar":"baz"}`, expected: 1},
+		{name: "array of scalars", json: `[1,2,3]`, expected: 1},
+		{name: "nested", json: `{"foo":[1,{"bar":[]}],"baz":{}}`, expected: 4},
+		{name: "unbalanced", json: `[[[[1]]],[2],3]`, expected: 4},
+		{name: "chain", json: strings.Repeat(`{"a":[`, 50) + `1` + strings.Repeat(`]}`, 50), expected: 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			if value := root.Depth(); value != test.expected {
+				t.Errorf("Wrong root.Depth(): expected %d, got %d", test.expected, value)
+			}
+		})
+	}
+}
+
 func TestNode_Parent(t *testing.T) {
 	root, err := Unmarshal([]byte(`{"foo":true,"bar":null}`))
 	if err != nil {
@@ -669,6 +1148,40 @@ func TestNode_Source(t *testing.T) {
 	}
 }
 
+func TestNode_Source_nested(t *testing.T) {
+	data := []byte("{\n  \"foo\": {\n    \"bar\" : [ 1.50000000000000000001, \"\\u0062az\" ],\n    \"qux\": {}\n  }\n}")
+	root := Must(Unmarshal(data))
+	tests := []struct {
+		name     string
+		node     *Node
+		expected string
+	}{
+		{name: "object", node: root.MustKey("foo"), expected: "{\n    \"bar\" : [ 1.50000000000000000001, \"\\u0062az\" ],\n    \"qux\": {}\n  }"},
+		{name: "array", node: root.MustKey("foo").MustKey("bar"), expected: `[ 1.50000000000000000001, "\u0062az" ]`},
+		{name: "numeric", node: root.MustKey("foo").MustKey("bar").MustIndex(0), expected: `1.50000000000000000001`},
+		{name: "string", node: root.MustKey("foo").MustKey("bar").MustIndex(1), expected: `"\u0062az"`},
+		{name: "empty", node: root.MustKey("foo").MustKey("qux"), expected: `{}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if value := test.node.Source(); string(value) != test.expected {
+				t.Errorf("Wrong Source():\nExpected: %q\nActual:   %q", test.expected, value)
+			}
+		})
+	}
+
+	node := root.MustKey("foo").MustKey("bar")
+	if err := node.MustIndex(0).SetNumeric(1); err != nil {
+		t.Errorf("SetNumeric() error: %s", err)
+	}
+	if value := node.Source(); value != nil {
+		t.Errorf("Wrong Source() of changed node: %q", value)
+	}
+	if value := root.MustKey("foo").MustKey("qux").Source(); string(value) != `{}` {
+		t.Errorf("Wrong Source() of unchanged node: %q", value)
+	}
+}
+
 func TestNode_String(t *testing.T) {
 	root, err := Unmarshal([]byte(`{"foo":true,"bar":null}`))
 	if err != nil {
@@ -693,6 +1206,90 @@ func TestNode_String(t *testing.T) {
 	}
 }
 
+func TestNode_Bytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *Node
+		expected string
+	}{
+		{name: "parsed", node: Must(Unmarshal([]byte("{ \"foo\" : [ 1,\n\t2 ] , \"bar\": \" \\\" baz \" }"))), expected: `{"foo":[1,2],"bar":" \" baz "}`},
+		{name: "string", node: StringNode("", "foo bar"), expected: `"foo bar"`},
+		{name: "null", node: NullNode(""), expected: `null`},
+		{name: "array", node: ArrayNode("", []*Node{NumericNode("", 1), Must(Unmarshal([]byte(`[ true , "a b" ]`)))}), expected: `[1,[true,"a b"]]`},
+		{name: "wrong value", node: valueNode(nil, "", Numeric, "foo"), expected: ``},
+		{name: "nil", node: nil, expected: ``},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if value := test.node.Bytes(); string(value) != test.expected {
+				t.Errorf("Wrong root.Bytes():\nExpected: %s\nActual:   %s", test.expected, value)
+			}
+		})
+	}
+	if value := valueNode(nil, "", Numeric, "foo").Bytes(); value != nil {
+		t.Errorf("Wrong root.Bytes(): expected nil, got %s", value)
+	}
+}
+
+func TestNode_Hash(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  string
+		right string
+		equal bool
+	}{
+		{name: "same", left: `{"a":1}`, right: `{"a":1}`, equal: true},
+		{name: "keys order", left: `{"a":1,"b":[1,2]}`, right: `{"b":[1,2],"a":1}`, equal: true},
+		{name: "whitespaces", left: `{"a": [1, 2], "b": {"c": null}}`, right: "{\n\t\"b\":{\"c\":null},\"a\":[1,2]}", equal: true},
+		{name: "numbers", left: `[1, 1.0, 1e0, 10, -0]`, right: `[1, 1, 1, 1e1, 0]`, equal: true},
+		{name: "strings", left: `"\u0061\/"`, right: `"a/"`, equal: true},
+		{name: "scalar", left: `{"a":{"b":[1,2,3]}}`, right: `{"a":{"b":[1,2,4]}}`, equal: false},
+		{name: "string and number", left: `["1"]`, right: `[1]`, equal: false},
+		{name: "arrays order", left: `[1,2]`, right: `[2,1]`, equal: false},
+		{name: "key", left: `{"a":1}`, right: `{"b":1}`, equal: false},
+		{name: "nesting", left: `[[1],2]`, right: `[[1,2]]`, equal: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			left := Must(Unmarshal([]byte(test.left)))
+			right := Must(Unmarshal([]byte(test.right)))
+			if eq, err := left.Eq(right); err != nil || eq != test.equal {
+				t.Errorf("Eq() = %v, %v", eq, err)
+			}
+			lhash, lerr := left.Hash()
+			rhash, rerr := right.Hash()
+			if lerr != nil || rerr != nil {
+				t.Errorf("Hash() unexpected error: %v, %v", lerr, rerr)
+			} else if equal := lhash == rhash; equal != test.equal {
+				t.Errorf("Hash() equality: expected %v, got %v", test.equal, equal)
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`{"a":{"b":[1,2,3]}}`)))
+	hash, _ := root.Hash()
+	if err := root.MustKey("a").MustKey("b").MustIndex(1).SetNumeric(5); err != nil {
+		t.Fatalf("SetNumeric() error: %s", err)
+	}
+	if changed, _ := root.Hash(); changed == hash {
+		t.Errorf("Hash() should be changed after the update")
+	}
+	if err := root.MustKey("a").MustKey("b").MustIndex(1).SetNumeric(2); err != nil {
+		t.Fatalf("SetNumeric() error: %s", err)
+	}
+	if restored, _ := root.Hash(); restored != hash {
+		t.Errorf("Hash() should be restored after the update")
+	}
+	if _, err := root.Opt("missing").Hash(); err == nil {
+		t.Errorf("Hash() expected error on the missing node")
+	}
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := ArrayNode("", []*Node{NumericNode("", value)}).Hash(); err == nil {
+			t.Errorf("Hash() expected error on %v", value)
+		}
+	}
+}
+
 func TestNode_Type(t *testing.T) {
 	tests := []struct {
 		_type NodeType
@@ -738,6 +1335,165 @@ func TestNode_HasKey(t *testing.T) {
 	}
 }
 
+func TestNode_Or(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"name":"foo","port":8080,"debug":true,"empty":null,"list":[]}`)))
+	var nilNode *Node
+	tests := []struct {
+		name    string
+		node    *Node
+		str     string
+		numeric float64
+		boolean bool
+	}{
+		{name: "string", node: root.Opt("name"), str: "foo", numeric: 1, boolean: false},
+		{name: "numeric", node: root.Opt("port"), str: "def", numeric: 8080, boolean: false},
+		{name: "bool", node: root.Opt("debug"), str: "def", numeric: 1, boolean: true},
+		{name: "null", node: root.Opt("empty"), str: "def", numeric: 1, boolean: false},
+		{name: "array", node: root.Opt("list"), str: "def", numeric: 1, boolean: false},
+		{name: "object", node: root, str: "def", numeric: 1, boolean: false},
+		{name: "missing", node: root.Opt("missing").Opt("port"), str: "def", numeric: 1, boolean: false},
+		{name: "nil", node: nilNode, str: "def", numeric: 1, boolean: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if value := test.node.StringOr("def"); value != test.str {
+				t.Errorf("Wrong StringOr(): %s", value)
+			}
+			if value := test.node.NumericOr(1); value != test.numeric {
+				t.Errorf("Wrong NumericOr(): %v", value)
+			}
+			if value := test.node.BoolOr(false); value != test.boolean {
+				t.Errorf("Wrong BoolOr(): %v", value)
+			}
+		})
+	}
+	if !root.Opt("port").BoolOr(true) || root.Opt("debug").StringOr("") != "" {
+		t.Errorf("Default value is expected for the wrong type")
+	}
+}
+
+func TestNode_Opt(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":{"bar":[{"baz":"qux"},null]},"num":1}`)))
+	tests := []struct {
+		name     string
+		node     *Node
+		expected string
+		missing  bool
+	}{
+		{name: "key", node: root.Opt("foo"), expected: "$['foo']"},
+		{name: "chain", node: root.Opt("foo").Opt("bar").OptIndex(0).Opt("baz"), expected: "$['foo']['bar'][0]['baz']"},
+		{name: "negative index", node: root.Opt("foo").Opt("bar").OptIndex(-1), expected: "$['foo']['bar'][1]"},
+		{name: "null", node: root.Opt("foo").Opt("bar").OptIndex(1), expected: "$['foo']['bar'][1]"},
+		{name: "missing key", node: root.Opt("bar"), missing: true},
+		{name: "missing chain", node: root.Opt("bar").Opt("baz").OptIndex(0), missing: true},
+		{name: "key of array", node: root.Opt("foo").Opt("bar").Opt("0"), missing: true},
+		{name: "index of object", node: root.OptIndex(0), missing: true},
+		{name: "key of scalar", node: root.Opt("num").Opt("foo"), missing: true},
+		{name: "key of null", node: root.Opt("foo").Opt("bar").OptIndex(1).Opt("foo"), missing: true},
+		{name: "index out of range", node: root.Opt("foo").Opt("bar").OptIndex(2), missing: true},
+		{name: "negative index out of range", node: root.Opt("foo").Opt("bar").OptIndex(-3), missing: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.node.IsMissing() != test.missing {
+				t.Errorf("Wrong IsMissing(): %v", test.node.IsMissing())
+			} else if !test.missing && test.node.Path() != test.expected {
+				t.Errorf("Wrong Path(): %s", test.node.Path())
+			}
+		})
+	}
+	if value, err := root.Opt("foo").Opt("bar").OptIndex(0).Opt("baz").GetString(); err != nil || value != "qux" {
+		t.Errorf("Wrong GetString(): %s, %v", value, err)
+	}
+}
+
+func TestNode_Opt_missing(t *testing.T) {
+	missing := Must(Unmarshal([]byte(`{}`))).Opt("foo")
+	isMissing := func(err error) bool {
+		if err, ok := err.(Error); ok {
+			return err.Type == Missing
+		}
+		return false
+	}
+	for name, fn := range map[string]func() error{
+		"GetNull":    func() error { _, err := missing.GetNull(); return err },
+		"GetNumeric": func() error { _, err := missing.GetNumeric(); return err },
+		"GetString":  func() error { _, err := missing.GetString(); return err },
+		"GetBool":    func() error { _, err := missing.GetBool(); return err },
+		"GetArray":   func() error { _, err := missing.GetArray(); return err },
+		"GetObject":  func() error { _, err := missing.GetObject(); return err },
+		"GetInt":     func() error { _, err := missing.GetInt(); return err },
+		"GetUint":    func() error { _, err := missing.GetUint(); return err },
+		"GetFloat":   func() error { _, err := missing.GetFloat(); return err },
+		"Value":      func() error { _, err := missing.Value(); return err },
+		"Unpack":     func() error { _, err := missing.Unpack(); return err },
+		"AsMap":      func() error { _, err := missing.AsMap(); return err },
+		"AsSlice":    func() error { _, err := missing.AsSlice(); return err },
+		"GetKey":     func() error { _, err := missing.GetKey("foo"); return err },
+		"GetIndex":   func() error { _, err := missing.GetIndex(0); return err },
+		"Marshal":    func() error { _, err := Marshal(missing); return err },
+		"Unmarshal":  func() error { var value interface{}; return missing.Unmarshal(&value) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := fn(); !isMissing(err) {
+				t.Errorf("%s() wrong error: %v", name, err)
+			}
+		})
+	}
+	if missing.IsNull() || missing.IsNumeric() || missing.IsString() || missing.IsBool() || missing.IsArray() || missing.IsObject() || missing.IsContainer() {
+		t.Errorf("Missing node must not have a type")
+	}
+	if err := missing.SetString("foo"); err == nil {
+		t.Errorf("SetString() expected error on missing node")
+	}
+	if err := missing.AppendObject("foo", NullNode("")); err == nil {
+		t.Errorf("AppendObject() expected error on missing node")
+	}
+	if missing.Bytes() != nil || !missing.Opt("foo").IsMissing() || missing.Size() != 0 || missing.Parent() != nil {
+		t.Errorf("Missing node must be empty")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustString() expected panic on missing node")
+		}
+	}()
+	missing.MustString()
+}
+
+func TestNode_Opt_shared(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{}`)))
+	array := ArrayNode("", []*Node{root.Opt("foo")})
+	object := ObjectNode("", map[string]*Node{"x": root.Opt("bar")})
+	if !array.MustIndex(0).IsMissing() || array.MustIndex(0).Parent() != array {
+		t.Errorf("ArrayNode() wrong missing element")
+	}
+	if !object.MustKey("x").IsMissing() || object.MustKey("x").Key() != "x" {
+		t.Errorf("ObjectNode() wrong missing element")
+	}
+	missing := root.Opt("baz")
+	if missing.Parent() != nil || missing.key != nil || missing.Path() != "$" {
+		t.Errorf("Missing node is changed by the containers: %s", missing.Path())
+	}
+	if err := array.AppendArray(missing); err == nil {
+		t.Errorf("AppendArray() expected error on missing node")
+	}
+	if missing.Parent() != nil {
+		t.Errorf("Missing node is changed by AppendArray")
+	}
+
+	for _, clone := range []*Node{missing.Clone(), array.Clone().MustIndex(0), object.Clone().MustKey("x")} {
+		if !clone.IsMissing() {
+			t.Errorf("Clone() of missing node is not missing")
+		}
+		if _, err := clone.GetString(); err == nil || err.(Error).Type != Missing {
+			t.Errorf("Clone() of missing node wrong error: %v", err)
+		}
+	}
+	if missing.Clone() != missing {
+		t.Errorf("Clone() of missing node should return the missing node")
+	}
+}
+
 func TestNode_Path(t *testing.T) {
 	data := []byte(`{
         "Image": {
@@ -767,6 +1523,154 @@ func TestNode_Path(t *testing.T) {
 	}
 }
 
+func TestNode_Path_escaped(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"O'Brien":{"back\\slash":[1]}}`)))
+	element := root.MustKey("O'Brien").MustKey("back\\slash").MustIndex(0)
+	if path := element.Path(); path != `$['O\'Brien']['back\\slash'][0]` {
+		t.Errorf("Wrong element.Path(): %s", path)
+	}
+	if nodes, err := root.JSONPath(element.Path()); err != nil {
+		t.Errorf("JSONPath() error: %s", err)
+	} else if len(nodes) != 1 || nodes[0] != element {
+		t.Errorf("JSONPath() wrong result of element.Path()")
+	}
+}
+
+func TestNode_Path_special(t *testing.T) {
+	keys := []string{"a.b", "a b", " a.b c ", "a[0]", "*", "b*", "$", "@", "..", "a,b", "a:b", "?(@.a)", "(1)", "length", "0", "\"", ""}
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(`{}`)))
+			element := StringNode("", "value")
+			if err := root.AppendObject(key, element); err != nil {
+				t.Errorf("AppendObject() error: %s", err)
+				return
+			}
+			if err := root.AppendObject(key+"_", NullNode("")); err != nil {
+				t.Errorf("AppendObject() error: %s", err)
+				return
+			}
+			path := element.Path()
+			if !strings.HasPrefix(path, "$['") || !strings.HasSuffix(path, "']") {
+				t.Errorf("Wrong element.Path(): %s", path)
+			}
+			if nodes, err := root.JSONPath(path); err != nil {
+				t.Errorf("JSONPath(%s) error: %s", path, err)
+			} else if len(nodes) != 1 || nodes[0] != element {
+				t.Errorf("JSONPath(%s) wrong result: %s", path, fullPath(nodes))
+			}
+		})
+	}
+}
+
+func TestNode_WalkPath(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{
+		"user": {"name": "foo", "password": "bar", "tokens": [{"password": "baz"}, 1]},
+		"it's": {"password": null},
+		"list": []
+	}`)))
+	paths := make([]string, 0)
+	err := root.WalkPath(func(path string, node *Node) error {
+		paths = append(paths, path)
+		if path != node.Path() {
+			t.Errorf("WalkPath() wrong path: %s, expected: %s", path, node.Path())
+		}
+		nodes, err := root.JSONPath(path)
+		if err != nil {
+			t.Errorf("JSONPath(%s) error: %s", path, err)
+		} else if len(nodes) != 1 || nodes[0] != node {
+			t.Errorf("JSONPath(%s) wrong result", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("WalkPath() error: %s", err)
+	}
+	expected := []string{
+		"$",
+		"$['user']",
+		"$['user']['name']",
+		"$['user']['password']",
+		"$['user']['tokens']",
+		"$['user']['tokens'][0]",
+		"$['user']['tokens'][0]['password']",
+		"$['user']['tokens'][1]",
+		"$['it\\'s']",
+		"$['it\\'s']['password']",
+		"$['list']",
+	}
+	if !sliceEqual(paths, expected) {
+		t.Errorf("WalkPath() wrong paths:\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(paths))
+	}
+
+	paths = paths[:0]
+	err = root.MustKey("user").MustKey("tokens").WalkPath(func(path string, node *Node) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("WalkPath() error: %s", err)
+	} else if expected = []string{"$['user']['tokens']", "$['user']['tokens'][0]", "$['user']['tokens'][0]['password']", "$['user']['tokens'][1]"}; !sliceEqual(paths, expected) {
+		t.Errorf("WalkPath() wrong paths of the child: %s", sliceString(paths))
+	}
+}
+
+func TestNode_WalkPath_error(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a":{"password":"foo"},"b":{"password":"bar"}}`)))
+	count := 0
+	err := root.WalkPath(func(path string, node *Node) error {
+		count++
+		if node.key != nil && node.Key() == "password" {
+			return errorRequest("password found at %s", path)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "wrong request: password found at $['a']['password']" {
+		t.Errorf("WalkPath() wrong error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("WalkPath() must stop on error, visited: %d", count)
+	}
+}
+
+func TestAllKeys(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"b": [{"a": 1, "c": {"b": null}}, [{"d": true}], 2], "a": {}, "e": "a"}`)))
+	if actual, expected := AllKeys(root), []string{"a", "b", "c", "d", "e"}; !sliceEqual(actual, expected) {
+		t.Errorf("AllKeys() wrong result\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(actual))
+	}
+	if actual := AllKeys(Must(Unmarshal([]byte(`[1, [2]]`)))); len(actual) != 0 {
+		t.Errorf("AllKeys() wrong result: %s", sliceString(actual))
+	}
+	if actual, expected := AllPaths(root), []string{
+		"$['b'][0]['a']",
+		"$['b'][0]['c']['b']",
+		"$['b'][1][0]['d']",
+		"$['b'][2]",
+		"$['e']",
+	}; !sliceEqual(actual, expected) {
+		t.Errorf("AllPaths() wrong result\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(actual))
+	}
+	if actual, expected := AllPaths(Must(Unmarshal([]byte(`"foo"`)))), []string{"$"}; !sliceEqual(actual, expected) {
+		t.Errorf("AllPaths() wrong result\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(actual))
+	}
+}
+
+func ExampleNode_WalkPath() {
+	root := Must(Unmarshal([]byte(`{"user":{"name":"foo","password":"bar"},"tokens":[{"password":"baz"}]}`)))
+	_ = root.WalkPath(func(path string, node *Node) error {
+		if node.key != nil && node.Key() == "password" {
+			fmt.Println(path)
+			return node.SetString("***")
+		}
+		return nil
+	})
+	fmt.Printf("%s", root.MustKey("tokens").Bytes())
+	// Output:
+	// $['user']['password']
+	// $['tokens'][0]['password']
+	// [{"password":"***"}]
+}
+
 func TestNode_Eq(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -923,6 +1827,60 @@ func TestNode_Eq(t *testing.T) {
 	}
 }
 
+func TestNode_Contains(t *testing.T) {
+	response := Must(Unmarshal([]byte(`{
+		"status": "ok",
+		"code": 200,
+		"data": {
+			"user": {"id": 1, "name": "foo", "roles": ["admin", "user"], "active": true, "manager": null},
+			"items": [{"id": 1, "tags": ["a", "b"]}, {"id": 2, "tags": []}, {"id": 3, "tags": ["c"]}]
+		}
+	}`)))
+	tests := []struct {
+		name     string
+		subset   string
+		expected bool
+	}{
+		{name: "empty object", subset: `{}`, expected: true},
+		{name: "same", subset: `{"status": "ok", "code": 200.0}`, expected: true},
+		{name: "nested", subset: `{"data": {"user": {"name": "foo", "active": true, "manager": null}}}`, expected: true},
+		{name: "nested wrong value", subset: `{"data": {"user": {"name": "bar"}}}`, expected: false},
+		{name: "nested missing key", subset: `{"data": {"user": {"email": "foo"}}}`, expected: false},
+		{name: "wrong type", subset: `{"code": "200"}`, expected: false},
+		{name: "null is not missing", subset: `{"data": {"user": {"manager": null, "boss": null}}}`, expected: false},
+		{name: "array subset", subset: `{"data": {"user": {"roles": ["user"]}}}`, expected: true},
+		{name: "array in any order", subset: `{"data": {"user": {"roles": ["user", "admin"]}}}`, expected: true},
+		{name: "array empty", subset: `{"data": {"items": []}}`, expected: true},
+		{name: "array missing element", subset: `{"data": {"user": {"roles": ["root"]}}}`, expected: false},
+		{name: "array of objects", subset: `{"data": {"items": [{"id": 3}, {"tags": ["b"]}]}}`, expected: true},
+		{name: "array of objects in one element", subset: `{"data": {"items": [{"id": 3, "tags": ["b"]}]}}`, expected: false},
+		{name: "array of empty array", subset: `{"data": {"items": [{"tags": [[]]}]}}`, expected: false},
+		{name: "array instead of object", subset: `{"data": []}`, expected: false},
+		{name: "scalar", subset: `"ok"`, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := response.Contains(Must(Unmarshal([]byte(test.subset)))); actual != test.expected {
+				t.Errorf("Contains(%s) wrong result: %v", test.subset, actual)
+			}
+		})
+	}
+
+	if !response.Contains(response) {
+		t.Errorf("Contains() node should contain itself")
+	}
+	if !StringNode("", "ok").Contains(response.MustKey("status")) || NumericNode("", 1).Contains(StringNode("", "1")) {
+		t.Errorf("Contains() wrong result of the scalar values")
+	}
+	if response.Contains(nil) || response.Opt("missing").Contains(response.Opt("missing")) {
+		t.Errorf("Contains() should be false for nil and missing nodes")
+	}
+	var node *Node
+	if node.Contains(response) {
+		t.Errorf("Contains() should be false for nil node")
+	}
+}
+
 func TestNode_Neq(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1317,6 +2275,67 @@ func TestNode_Le(t *testing.T) {
 	}
 }
 
+func TestNode_Less_Equals(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right string
+		less, equal bool
+		error       bool
+	}{
+		{name: "numbers less", left: `1`, right: `2.5`, less: true},
+		{name: "numbers equal", left: `2.50`, right: `25e-1`, equal: true},
+		{name: "numbers greater", left: `3`, right: `-3`},
+		{name: "strings less", left: `"abc"`, right: `"abd"`, less: true},
+		{name: "strings equal", left: `"abc"`, right: `"abc"`, equal: true},
+		{name: "strings greater", left: `"b"`, right: `"abc"`},
+		{name: "numeric string", left: `"1"`, right: `2`},
+		{name: "numeric string equal", left: `"2"`, right: `2`},
+		{name: "string and bool", left: `"true"`, right: `true`},
+		{name: "number and null", left: `0`, right: `null`},
+		{name: "bools equal", left: `true`, right: `true`, equal: true, error: true},
+		{name: "nulls", left: `null`, right: `null`, equal: true, error: true},
+		{name: "arrays", left: `[1, "a"]`, right: `[1, "a"]`, equal: true, error: true},
+		{name: "objects", left: `{"a": [1]}`, right: `{"a": [2]}`, error: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(`{"l": ` + test.left + `, "r": ` + test.right + `}`)))
+			left, right := root.MustKey("l"), root.MustKey("r")
+
+			less, err := left.Less(right)
+			if (err != nil) != test.error {
+				t.Errorf("Less() unexpected error: %v", err)
+			} else if less != test.less {
+				t.Errorf("Less() wrong result: %v", less)
+			}
+			filter, err := Eval(root, "@.l < @.r")
+			if (err != nil) != test.error {
+				t.Errorf("Eval(<) unexpected error: %v", err)
+			} else if err == nil && filter.MustBool() != less {
+				t.Errorf("Less() doesn't match the filter: %v", filter.MustBool())
+			}
+
+			if equal := left.Equals(right); equal != test.equal {
+				t.Errorf("Equals() wrong result: %v", equal)
+			}
+			filter, err = Eval(root, "@.l == @.r")
+			if err != nil {
+				t.Errorf("Eval(==) unexpected error: %v", err)
+			} else if filter.MustBool() != left.Equals(right) {
+				t.Errorf("Equals() doesn't match the filter: %v", filter.MustBool())
+			}
+		})
+	}
+
+	root := Must(Unmarshal([]byte(`{"l": "1", "r": 2}`)))
+	if _, err := root.MustKey("l").Less(root.MustKey("r")); err != nil {
+		t.Errorf("Less() unexpected error: %v", err)
+	}
+	if NumericNode("", 1).Equals(root.MustKey("l")) {
+		t.Errorf("Equals() should compare strictly")
+	}
+}
+
 func TestNode_Leq(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1491,6 +2510,19 @@ func TestObjectNode(t *testing.T) {
 		} else if !ok {
 			t.Errorf("Failed: compare '%s' & '%s'", val, objects[i])
 		}
+		if val.Key() != i {
+			t.Errorf("Failed: wrong key '%s' of '%s'", val.Key(), i)
+		}
+	}
+
+	node = NullNode("")
+	if err := node.SetObject(map[string]*Node{"a": NullNode(""), "b": NullNode(""), "c": NullNode("")}); err != nil {
+		t.Errorf("Failed: %s", err.Error())
+	}
+	for key, val := range node.MustObject() {
+		if val.Key() != key {
+			t.Errorf("Failed: wrong key '%s' of '%s' after SetObject", val.Key(), key)
+		}
 	}
 }
 
@@ -1545,6 +2577,47 @@ func TestNode_Inheritors(t *testing.T) {
 	}
 }
 
+func TestNode_Find(t *testing.T) {
+	root := Must(Unmarshal(jsonPathTestData))
+	visited := make([]string, 0)
+	found := root.Find(func(node *Node) bool {
+		visited = append(visited, node.Path())
+		return node.IsNumeric()
+	})
+	if found == nil {
+		t.Errorf("Find() nothing found")
+		return
+	}
+	if found.Path() != "$['store']['book'][0]['price']" {
+		t.Errorf("Find() wrong node: %s", found.Path())
+	}
+	expected := []string{"$['store']", "$['store']['book']", "$['store']['book'][0]", "$['store']['book'][0]['category']",
+		"$['store']['book'][0]['author']", "$['store']['book'][0]['title']", "$['store']['book'][0]['price']"}
+	if !sliceEqual(visited, expected) {
+		t.Errorf("Find() wrong visited nodes:\nExpected: %s\nActual:   %s", sliceString(expected), sliceString(visited))
+	}
+	if found = root.Find(func(node *Node) bool { return node.key != nil && node.Key() == "foo" }); found != nil {
+		t.Errorf("Find() unexpected node: %s", found.Path())
+	}
+	if found = NumericNode("", 1).Find(func(node *Node) bool { return true }); found != nil {
+		t.Errorf("Find() unexpected node on scalar: %s", found.Path())
+	}
+}
+
+func TestNode_FindAll(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"b":[1,{"c":2}],"a":{"d":3},"e":"f"}`)))
+	result := root.FindAll(func(node *Node) bool {
+		return node.IsNumeric()
+	})
+	expected := "[$['b'][0], $['b'][1]['c'], $['a']['d']]"
+	if fullPath(result) != expected {
+		t.Errorf("FindAll() wrong result:\nExpected: %s\nActual:   %s", expected, fullPath(result))
+	}
+	if result = root.FindAll(func(node *Node) bool { return node.IsBool() }); len(result) != 0 {
+		t.Errorf("FindAll() unexpected result: %s", fullPath(result))
+	}
+}
+
 func TestNode_JSONPath(t *testing.T) {
 	root, err := Unmarshal(jsonPathTestData)
 	if err != nil {
@@ -1567,12 +2640,53 @@ func TestNode_JSONPath_error(t *testing.T) {
 		t.Errorf("Error: %s", err.Error())
 		return
 	}
-	_, err = root.MustKey("store").MustKey("book").JSONPath("XXX")
+	_, err = root.MustKey("store").MustKey("book").JSONPath("XXX[")
 	if err == nil {
 		t.Errorf("JSONPath() Expected error")
 	}
 }
 
+func TestNode_FilterNodes(t *testing.T) {
+	root := Must(Unmarshal(jsonPathTestData))
+	books := root.MustKey("store").MustKey("book")
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: "@.price < 10", expected: "[$['store']['book'][0], $['store']['book'][2]]"},
+		{expr: "@.isbn", expected: "[$['store']['book'][2], $['store']['book'][3]]"},
+		{expr: "@.category == 'fiction' && @.price > 10", expected: "[$['store']['book'][1], $['store']['book'][3]]"},
+		{expr: "@.price > $.store.bicycle.price", expected: "[$['store']['book'][3]]"},
+		{expr: "@.price > 100", expected: "[]"},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			result, err := books.FilterNodes(test.expr)
+			if err != nil {
+				t.Errorf("FilterNodes() unexpected error: %s", err)
+				return
+			}
+			if fullPath(result) != test.expected {
+				t.Errorf("FilterNodes() wrong result\nExpected: %s\nActual:   %s", test.expected, fullPath(result))
+			}
+			expected, _ := books.JSONPath("@[?(" + test.expr + ")]")
+			if fullPath(result) != fullPath(expected) {
+				t.Errorf("FilterNodes() doesn't match JSONPath: %s", fullPath(expected))
+			}
+		})
+	}
+
+	if result, err := root.MustKey("store").FilterNodes("@.color == 'red'"); err != nil || fullPath(result) != "[$['store']['bicycle']]" {
+		t.Errorf("FilterNodes() wrong result for Object: %s, %v", fullPath(result), err)
+	}
+	if _, err := books.FilterNodes("@.price <"); err == nil {
+		t.Errorf("FilterNodes() expected error on wrong expression")
+	}
+	if _, err := books.MustIndex(0).MustKey("price").FilterNodes("@ > 1"); err == nil {
+		t.Errorf("FilterNodes() expected error on Numeric")
+	}
+}
+
 func TestNode_IsDirty(t *testing.T) {
 	root, err := Unmarshal(jsonPathTestData)
 	if err != nil {
@@ -1814,3 +2928,40 @@ func TestNode_Value(t *testing.T) {
 		})
 	}
 }
+
+func TestNode_concurrent_read(t *testing.T) {
+	root := Must(Unmarshal(jsonPathTestData))
+	root.Freeze()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				nodes, err := root.JSONPath("$..book[?(@.price > 10)].title")
+				if err != nil {
+					t.Errorf("JSONPath() error: %s", err)
+					return
+				}
+				if len(nodes) != 2 {
+					t.Errorf("JSONPath() wrong result: %s", fullPath(nodes))
+				}
+				for _, node := range nodes {
+					if _, err = node.GetString(); err != nil {
+						t.Errorf("GetString() error: %s", err)
+					}
+				}
+				if _, err = root.Unpack(); err != nil {
+					t.Errorf("Unpack() error: %s", err)
+				}
+				if _, err = Marshal(root); err != nil {
+					t.Errorf("Marshal() error: %s", err)
+				}
+				if _, err = Eval(root, "avg($..price)"); err != nil {
+					t.Errorf("Eval() error: %s", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
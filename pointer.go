@@ -0,0 +1,175 @@
+package ajson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PointerOption is an option of writing values in Node.SetByPointer
+type PointerOption int
+
+const (
+	// PointerCreateParents means that missing parents will be created: Array if the next token is "-", or Object otherwise
+	PointerCreateParents PointerOption = iota
+)
+
+// JSONPointer returns the node, addressed by the JSON Pointer (RFC 6901) from current node, e.g. "/store/book/0/title".
+//
+// Empty pointer addresses current node itself.
+func (n *Node) JSONPointer(pointer string) (*Node, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := n
+	for _, token := range tokens {
+		child, ok := current.pointerChild(token)
+		if !ok {
+			return nil, errorRequest("pointer not found: %s", pointer)
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// SetByPointer sets value at the location, addressed by the JSON Pointer (RFC 6901) from current node.
+//
+// Existing value of Object key or Array index will be replaced, "-" token or the index equal to the size of Array appends value to the Array.
+// Missing parents cause an error, use PointerCreateParents option to create them.
+// Empty pointer replaces the value of current node with the value of the given one.
+func (n *Node) SetByPointer(pointer string, value *Node, options ...PointerOption) error {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return n.replaceValue(value)
+	}
+	create := false
+	for _, option := range options {
+		if option == PointerCreateParents {
+			create = true
+		}
+	}
+	current := n
+	for i, token := range tokens[:len(tokens)-1] {
+		child, ok := current.pointerChild(token)
+		if !ok {
+			if !create || !current.IsContainer() {
+				return errorRequest("parent not found: %s", pointer)
+			}
+			if tokens[i+1] == "-" {
+				child = ArrayNode("", nil)
+			} else {
+				child = ObjectNode("", nil)
+			}
+			if err = current.pointerSet(token, child); err != nil {
+				return err
+			}
+		}
+		current = child
+	}
+	return current.pointerSet(tokens[len(tokens)-1], value)
+}
+
+// pointerChild returns child of current node by the JSON Pointer reference token
+func (n *Node) pointerChild(token string) (child *Node, ok bool) {
+	switch n._type {
+	case Object:
+		child, ok = n.children[token]
+	case Array:
+		var index int
+		if index, ok = pointerIndex(token); ok {
+			child, ok = n.children[strconv.Itoa(index)]
+		}
+	}
+	return
+}
+
+// pointerSet sets child of current node by the JSON Pointer reference token
+func (n *Node) pointerSet(token string, value *Node) error {
+	switch n._type {
+	case Object:
+		return n.AppendObject(token, value)
+	case Array:
+		if token == "-" {
+			return n.AppendArray(value)
+		}
+		index, ok := pointerIndex(token)
+		if !ok || index > n.Size() {
+			return errorRequest("wrong index: %s", token)
+		}
+		if index == n.Size() {
+			return n.AppendArray(value)
+		}
+		return n.setIndex(index, value)
+	}
+	return errorType()
+}
+
+// replaceValue updates current node value with the value of the given node; children of the given node will be moved
+func (n *Node) replaceValue(value *Node) error {
+	if n == value {
+		return nil
+	}
+	if n.isParentNode(value) || value.isParentNode(n) {
+		return errorRequest("try to create infinite loop")
+	}
+	if value.IsContainer() && value.frozen {
+		return errorFrozen()
+	}
+	switch value._type {
+	case Array:
+		return n.SetArray(value.Inheritors())
+	case Object:
+		children := make(map[string]*Node, len(value.children))
+		for key, child := range value.children {
+			children[key] = child
+		}
+		order := value.orderedKeys()
+		if err := n.SetObject(children); err != nil {
+			return err
+		}
+		n.order = order
+		return nil
+	}
+	val, err := value.Value()
+	if err != nil {
+		return err
+	}
+	return n.update(value._type, val)
+}
+
+// parseJSONPointer returns unescaped reference tokens of the JSON Pointer
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if pointer[0] != division {
+		return nil, errorRequest("wrong pointer: %s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, errorRequest("wrong pointer: %s", pointer)
+			}
+		}
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// pointerIndex returns Array index from the reference token: digits without leading zeros
+func pointerIndex(token string) (int, bool) {
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return 0, false
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(token)
+	return index, err == nil
+}
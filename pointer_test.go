@@ -0,0 +1,160 @@
+package ajson
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNode_JSONPointer(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8,"01":9}`)))
+	tests := []struct {
+		pointer  string
+		expected string
+	}{
+		{pointer: ``, expected: `$`},
+		{pointer: `/foo`, expected: `$['foo']`},
+		{pointer: `/foo/0`, expected: `$['foo'][0]`},
+		{pointer: `/`, expected: `$['']`},
+		{pointer: `/a~1b`, expected: `$['a/b']`},
+		{pointer: `/c%d`, expected: `$['c%d']`},
+		{pointer: `/e^f`, expected: `$['e^f']`},
+		{pointer: `/g|h`, expected: `$['g|h']`},
+		{pointer: `/i\j`, expected: `$['i\\j']`},
+		{pointer: `/k"l`, expected: `$['k"l']`},
+		{pointer: `/ `, expected: `$[' ']`},
+		{pointer: `/m~0n`, expected: `$['m~n']`},
+		{pointer: `/01`, expected: `$['01']`},
+	}
+	for _, test := range tests {
+		t.Run(test.pointer, func(t *testing.T) {
+			node, err := root.JSONPointer(test.pointer)
+			if err != nil {
+				t.Errorf("JSONPointer() error: %s", err)
+			} else if node.Path() != test.expected {
+				t.Errorf("JSONPointer() wrong result:\nExpected: %s\nActual:   %s", test.expected, node.Path())
+			}
+		})
+	}
+}
+
+func TestNode_JSONPointer_errors(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":["bar","baz"],"qux":1}`)))
+	tests := []string{`foo`, `/bar`, `/foo/2`, `/foo/-`, `/foo/01`, `/foo/-1`, `/foo/0/bar`, `/qux/0`, `/foo~2`, `/foo~`}
+	for _, pointer := range tests {
+		t.Run(pointer, func(t *testing.T) {
+			if _, err := root.JSONPointer(pointer); err == nil {
+				t.Errorf("JSONPointer() expected error")
+			}
+		})
+	}
+}
+
+func TestNode_SetByPointer(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		pointer  string
+		value    *Node
+		options  []PointerOption
+		expected string
+	}{
+		{name: "existing key", json: `{"foo":{"bar":1}}`, pointer: `/foo/bar`, value: NumericNode("", 2), expected: `{"foo":{"bar":2}}`},
+		{name: "new key", json: `{"foo":{"bar":1}}`, pointer: `/foo/baz`, value: StringNode("", "qux"), expected: `{"foo":{"bar":1,"baz":"qux"}}`},
+		{name: "escaped key", json: `{}`, pointer: `/a~1b~0c`, value: NullNode(""), expected: `{"a/b~c":null}`},
+		{name: "existing index", json: `{"foo":[1,2,3]}`, pointer: `/foo/1`, value: BoolNode("", true), expected: `{"foo":[1,true,3]}`},
+		{name: "append", json: `{"foo":[1,2,3]}`, pointer: `/foo/-`, value: NumericNode("", 4), expected: `{"foo":[1,2,3,4]}`},
+		{name: "append by index", json: `{"foo":[1,2,3]}`, pointer: `/foo/3`, value: NumericNode("", 4), expected: `{"foo":[1,2,3,4]}`},
+		{name: "append to empty", json: `[]`, pointer: `/-`, value: NumericNode("", 1), expected: `[1]`},
+		{name: "container", json: `{"foo":[1]}`, pointer: `/foo/0`, value: Must(Unmarshal([]byte(`{"bar":[2]}`))), expected: `{"foo":[{"bar":[2]}]}`},
+		{name: "root", json: `{"foo":[1]}`, pointer: ``, value: Must(Unmarshal([]byte(`[1,{"bar":2}]`))), expected: `[1,{"bar":2}]`},
+		{name: "root scalar", json: `{"foo":[1]}`, pointer: ``, value: StringNode("", "bar"), expected: `"bar"`},
+		{name: "create parents", json: `{}`, pointer: `/foo/bar/baz`, value: NumericNode("", 1), options: []PointerOption{PointerCreateParents}, expected: `{"foo":{"bar":{"baz":1}}}`},
+		{name: "create array parent", json: `{"foo":[]}`, pointer: `/foo/-/bar/-`, value: NumericNode("", 1), options: []PointerOption{PointerCreateParents}, expected: `{"foo":[{"bar":[1]}]}`},
+		{name: "create existing parents", json: `{"foo":[{}]}`, pointer: `/foo/0/bar`, value: NumericNode("", 1), options: []PointerOption{PointerCreateParents}, expected: `{"foo":[{"bar":1}]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			if err := root.SetByPointer(test.pointer, test.value, test.options...); err != nil {
+				t.Errorf("SetByPointer() error: %s", err)
+				return
+			}
+			actual, err := root.Unpack()
+			if err != nil {
+				t.Errorf("Unpack() error: %s", err)
+				return
+			}
+			expected, _ := Must(Unmarshal([]byte(test.expected))).Unpack()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("SetByPointer() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, actual)
+			}
+			if test.pointer != "" {
+				if node, err := root.JSONPointer(test.pointer); test.pointer[len(test.pointer)-1] != '-' && (err != nil || node != test.value) {
+					t.Errorf("JSONPointer() wrong node: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestNode_SetByPointer_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		pointer string
+		options []PointerOption
+	}{
+		{name: "wrong pointer", json: `{}`, pointer: `foo`},
+		{name: "wrong escape", json: `{}`, pointer: `/foo~`},
+		{name: "missing parent", json: `{"foo":{}}`, pointer: `/foo/bar/baz`},
+		{name: "missing array parent", json: `{"foo":[]}`, pointer: `/foo/0/bar`},
+		{name: "scalar parent", json: `{"foo":1}`, pointer: `/foo/bar`},
+		{name: "scalar parent created", json: `{"foo":1}`, pointer: `/foo/bar/baz`, options: []PointerOption{PointerCreateParents}},
+		{name: "wrong index", json: `{"foo":[1]}`, pointer: `/foo/bar`},
+		{name: "leading zero", json: `{"foo":[1]}`, pointer: `/foo/00`},
+		{name: "out of range", json: `{"foo":[1]}`, pointer: `/foo/2`},
+		{name: "negative", json: `{"foo":[1]}`, pointer: `/foo/-1`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.json)))
+			if err := root.SetByPointer(test.pointer, NumericNode("", 1), test.options...); err == nil {
+				t.Errorf("SetByPointer() expected error")
+			}
+		})
+	}
+}
+
+func TestNode_SetByPointer_move(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[1,2,3]`)))
+	first := root.MustIndex(0)
+	if err := root.SetByPointer("/2", first); err != nil {
+		t.Errorf("SetByPointer() error: %s", err)
+		return
+	}
+	if result := string(root.Bytes()); result != `[2,1]` {
+		t.Errorf("SetByPointer() wrong result: %s", result)
+	}
+	for i, child := range root.MustArray() {
+		if child.Index() != i {
+			t.Errorf("SetByPointer() wrong index %d of %d", child.Index(), i)
+		}
+	}
+
+	root.Freeze()
+	if err := root.SetByPointer("/0", NumericNode("", 1)); err == nil {
+		t.Errorf("SetByPointer() expected error on frozen node")
+	}
+}
+
+func ExampleNode_SetByPointer() {
+	root := Must(Unmarshal([]byte(`{"foo":["bar"]}`)))
+	_ = root.SetByPointer("/foo/-", StringNode("", "baz"))
+	_ = root.SetByPointer("/foo/0", StringNode("", "qux"))
+	_ = root.SetByPointer("/foo~1bar", NumericNode("", 1))
+	node, _ := root.JSONPointer("/foo")
+	fmt.Printf("%s", node.Bytes())
+	// Output:
+	// ["qux","baz"]
+}
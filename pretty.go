@@ -0,0 +1,109 @@
+package ajson
+
+import (
+	"io"
+	"os"
+)
+
+// Palette is a set of ANSI escape sequences, used by PrettyPalette to color the values of each type. Empty sequence means no color.
+type Palette struct {
+	Key    string
+	String string
+	Number string
+	Bool   string
+	Null   string
+}
+
+// DefaultPalette is the palette of PrettyColor, similar to the one of jq
+var DefaultPalette = Palette{
+	Key:    "\x1b[34;1m",
+	String: "\x1b[32m",
+	Number: "\x1b[36m",
+	Bool:   "\x1b[33m",
+	Null:   "\x1b[90m",
+}
+
+// colorReset is the ANSI escape sequence, which resets the color
+const colorReset = "\x1b[0m"
+
+// isTerminal returns true if the writer is a character device, like a terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ColorEnabled returns true if w is a terminal and the NO_COLOR environment variable is not set, see https://no-color.org
+func ColorEnabled(w io.Writer) bool {
+	return colorEnabled(isTerminal(w))
+}
+
+// colorEnabled returns true if the output is a terminal and the NO_COLOR environment variable is not set
+func colorEnabled(terminal bool) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return terminal
+}
+
+// PrettyColor writes indented JSON of the node to w, with the DefaultPalette colors if ColorEnabled(w), or without colors otherwise.
+func (n *Node) PrettyColor(w io.Writer) error {
+	return n.prettyTerminal(w, isTerminal(w))
+}
+
+// prettyTerminal writes indented JSON of the node to w, with the DefaultPalette colors if the colors are enabled for the terminal
+func (n *Node) prettyTerminal(w io.Writer, terminal bool) error {
+	if colorEnabled(terminal) {
+		return n.PrettyPalette(w, DefaultPalette)
+	}
+	return n.PrettyPalette(w, Palette{})
+}
+
+// PrettyPalette writes indented JSON of the node to w, with the colors of the palette, regardless of the type of w.
+// Containers are indented by two spaces, output ends with a new line.
+func (n *Node) PrettyPalette(w io.Writer, palette Palette) error {
+	result, err := encodeIndent(n, prettyFormat(palette))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(result, skipN))
+	return err
+}
+
+// prettyFormat returns the format of PrettyPalette: JSON with the keys and the values wrapped with the colors of the palette
+func prettyFormat(palette Palette) encoderFormat {
+	return encoderFormat{
+		key: func(result []byte, key string) []byte {
+			return prettyColor(result, doubleQuoted(nil, key), palette.Key)
+		},
+		scalar: func(result []byte, node *Node) ([]byte, error) {
+			value, err := Marshal(node)
+			if err != nil {
+				return nil, err
+			}
+			switch node.Type() {
+			case String:
+				return prettyColor(result, value, palette.String), nil
+			case Numeric:
+				return prettyColor(result, value, palette.Number), nil
+			case Bool:
+				return prettyColor(result, value, palette.Bool), nil
+			default:
+				return prettyColor(result, value, palette.Null), nil
+			}
+		},
+	}
+}
+
+// prettyColor appends the value wrapped with the color sequence and the reset sequence, if the color is set
+func prettyColor(result, value []byte, color string) []byte {
+	if color == "" {
+		return append(result, value...)
+	}
+	result = append(result, color...)
+	result = append(result, value...)
+	return append(result, colorReset...)
+}
@@ -0,0 +1,95 @@
+package ajson
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestNode_PrettyPalette(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"name": "foo", "tags": ["a", 1.50, true, null], "empty": {}, "list": [], "nested": {"x": false}}`)))
+	expected := `{
+  "name": "foo",
+  "tags": [
+    "a",
+    1.50,
+    true,
+    null
+  ],
+  "empty": {},
+  "list": [],
+  "nested": {
+    "x": false
+  }
+}
+`
+	buf := new(bytes.Buffer)
+	if err := root.PrettyPalette(buf, Palette{}); err != nil {
+		t.Errorf("PrettyPalette() unexpected error: %s", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Wrong result\nExpected: %s\nActual:   %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	palette := Palette{Key: "<k>", String: "<s>", Number: "<n>", Bool: "<b>", Null: "<0>"}
+	if err := root.MustKey("tags").PrettyPalette(buf, palette); err != nil {
+		t.Errorf("PrettyPalette() unexpected error: %s", err)
+	}
+	expected = "[\n  <s>\"a\"\x1b[0m,\n  <n>1.50\x1b[0m,\n  <b>true\x1b[0m,\n  <0>null\x1b[0m\n]\n"
+	if buf.String() != expected {
+		t.Errorf("Wrong colored result\nExpected: %q\nActual:   %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := root.MustKey("nested").PrettyPalette(buf, palette); err != nil {
+		t.Errorf("PrettyPalette() unexpected error: %s", err)
+	}
+	expected = "{\n  <k>\"x\"\x1b[0m: <b>false\x1b[0m\n}\n"
+	if buf.String() != expected {
+		t.Errorf("Wrong colored key\nExpected: %q\nActual:   %q", expected, buf.String())
+	}
+
+	if err := NumericNode("", 1).PrettyPalette(failWriter{}, palette); err == nil {
+		t.Errorf("PrettyPalette() expected error of the writer")
+	}
+	var node *Node
+	if err := node.PrettyPalette(buf, palette); err == nil {
+		t.Errorf("PrettyPalette() expected error on nil node")
+	}
+	if err := ArrayNode("", []*Node{valueNode(nil, "", Numeric, "foo")}).PrettyPalette(buf, palette); err == nil {
+		t.Errorf("PrettyPalette() expected error on wrong value")
+	}
+}
+
+func TestNode_PrettyColor(t *testing.T) {
+	value, exists := os.LookupEnv("NO_COLOR")
+	defer func() {
+		if exists {
+			_ = os.Setenv("NO_COLOR", value)
+		} else {
+			_ = os.Unsetenv("NO_COLOR")
+		}
+	}()
+	_ = os.Unsetenv("NO_COLOR")
+	node := StringNode("", "foo")
+
+	buf := new(bytes.Buffer)
+	if err := node.PrettyColor(buf); err != nil || buf.String() != "\"foo\"\n" {
+		t.Errorf("PrettyColor() should not color the buffer: %q, %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	if err := node.prettyTerminal(buf, true); err != nil || buf.String() != DefaultPalette.String+"\"foo\""+colorReset+"\n" {
+		t.Errorf("PrettyColor() should color the terminal: %q, %v", buf.String(), err)
+	}
+
+	_ = os.Setenv("NO_COLOR", "1")
+	buf.Reset()
+	if err := node.prettyTerminal(buf, true); err != nil || buf.String() != "\"foo\"\n" {
+		t.Errorf("PrettyColor() should not color with NO_COLOR: %q, %v", buf.String(), err)
+	}
+	if ColorEnabled(buf) {
+		t.Errorf("ColorEnabled() should be false with NO_COLOR")
+	}
+}
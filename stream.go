@@ -0,0 +1,167 @@
+package ajson
+
+import (
+	"io"
+	"strconv"
+)
+
+// StreamWriter writes JSON into the io.Writer incrementally, without building the whole tree of nodes in memory.
+//
+// Arrays and objects are opened and closed by the BeginArray, EndArray, BeginObject and EndObject methods,
+// values are written by WriteNode, and each value of the object should be preceded by WriteKey. Commas are placed automatically.
+// Only one value is allowed on the top level. The first error is returned by all the following calls.
+type StreamWriter struct {
+	writer  io.Writer
+	stack   []streamLevel
+	written bool
+	err     error
+}
+
+// streamLevel is a state of the opened container of StreamWriter
+type streamLevel struct {
+	object bool
+	count  int
+	key    bool
+}
+
+// NewStreamWriter returns a new StreamWriter, which writes to w. Output is not buffered, wrap w with bufio.Writer if it's needed.
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{writer: w}
+}
+
+// BeginArray opens an Array as the next value
+func (s *StreamWriter) BeginArray() error {
+	return s.begin(bracketL, false)
+}
+
+// EndArray closes the last opened Array
+func (s *StreamWriter) EndArray() error {
+	return s.end(bracketR, false)
+}
+
+// BeginObject opens an Object as the next value
+func (s *StreamWriter) BeginObject() error {
+	return s.begin(bracesL, true)
+}
+
+// EndObject closes the last opened Object
+func (s *StreamWriter) EndObject() error {
+	return s.end(bracesR, true)
+}
+
+// WriteKey writes the key of the next value of the opened Object
+func (s *StreamWriter) WriteKey(key string) error {
+	if s.err != nil {
+		return s.err
+	}
+	level := s.last()
+	if level == nil || !level.object || level.key {
+		return s.fail(errorRequest("stream: unexpected key %s", strconv.Quote(key)))
+	}
+	data := make([]byte, 0, len(key)+4)
+	if level.count > 0 {
+		data = append(data, coma)
+	}
+	data = append(data, quotes)
+	data = append(data, quoteString(key, true)...)
+	data = append(data, quotes, colon)
+	level.key = true
+	return s.write(data)
+}
+
+// WriteNode writes the node as the next value, the same as Marshal does
+func (s *StreamWriter) WriteNode(node *Node) error {
+	if s.err != nil {
+		return s.err
+	}
+	value, err := Marshal(node)
+	if err != nil {
+		return s.fail(err)
+	}
+	prefix, err := s.value()
+	if err != nil {
+		return err
+	}
+	return s.write(append(prefix, value...))
+}
+
+// Close returns an error, if there are not closed containers or nothing was written. The underlying io.Writer is not closed.
+func (s *StreamWriter) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.stack) != 0 || !s.written {
+		return s.fail(errorRequest("stream: unexpected end of data"))
+	}
+	return nil
+}
+
+// begin opens a container as the next value
+func (s *StreamWriter) begin(symbol byte, object bool) error {
+	if s.err != nil {
+		return s.err
+	}
+	prefix, err := s.value()
+	if err != nil {
+		return err
+	}
+	s.stack = append(s.stack, streamLevel{object: object})
+	return s.write(append(prefix, symbol))
+}
+
+// end closes the last opened container, if it has the same type
+func (s *StreamWriter) end(symbol byte, object bool) error {
+	if s.err != nil {
+		return s.err
+	}
+	level := s.last()
+	if level == nil || level.object != object || level.key {
+		return s.fail(errorRequest("stream: unexpected '%c'", symbol))
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+	return s.write([]byte{symbol})
+}
+
+// value registers the next value in the current container and returns the separator, which should be written before it
+func (s *StreamWriter) value() ([]byte, error) {
+	level := s.last()
+	if level == nil {
+		if s.written {
+			return nil, s.fail(errorRequest("stream: only one value is allowed on the top level"))
+		}
+		s.written = true
+		return nil, nil
+	}
+	if level.object {
+		if !level.key {
+			return nil, s.fail(errorRequest("stream: key is expected"))
+		}
+		level.key = false
+		level.count++
+		return nil, nil
+	}
+	level.count++
+	if level.count > 1 {
+		return []byte{coma}, nil
+	}
+	return nil, nil
+}
+
+func (s *StreamWriter) last() *streamLevel {
+	if len(s.stack) == 0 {
+		return nil
+	}
+	return &s.stack[len(s.stack)-1]
+}
+
+func (s *StreamWriter) write(data []byte) error {
+	if _, err := s.writer.Write(data); err != nil {
+		return s.fail(err)
+	}
+	return nil
+}
+
+func (s *StreamWriter) fail(err error) error {
+	s.err = err
+	return err
+}
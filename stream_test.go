@@ -0,0 +1,204 @@
+package ajson
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestStreamWriter_array(t *testing.T) {
+	const count = 100000
+	buf := new(bytes.Buffer)
+	writer := NewStreamWriter(buf)
+	if err := writer.BeginArray(); err != nil {
+		t.Errorf("BeginArray() unexpected error: %s", err)
+	}
+	for i := 0; i < count; i++ {
+		record := ObjectNode("", map[string]*Node{
+			"id":   NumericNode("", float64(i)),
+			"name": StringNode("", "item "+strconv.Itoa(i)),
+		})
+		if err := writer.WriteNode(record); err != nil {
+			t.Errorf("WriteNode() unexpected error: %s", err)
+			return
+		}
+	}
+	if err := writer.EndArray(); err != nil {
+		t.Errorf("EndArray() unexpected error: %s", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Errorf("Close() unexpected error: %s", err)
+	}
+
+	root, err := Unmarshal(buf.Bytes())
+	if err != nil {
+		t.Errorf("Unmarshal() unexpected error: %s", err)
+		return
+	}
+	if root.Size() != count {
+		t.Errorf("Wrong size of the array: %d", root.Size())
+	}
+	for _, i := range []int{0, 1, count / 2, count - 1} {
+		element := root.MustIndex(i)
+		if element.MustKey("id").MustNumeric() != float64(i) || element.MustKey("name").MustString() != "item "+strconv.Itoa(i) {
+			t.Errorf("Wrong element %d: %s", i, element)
+		}
+	}
+}
+
+func TestStreamWriter_nested(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewStreamWriter(buf)
+	steps := []func() error{
+		writer.BeginObject,
+		func() error { return writer.WriteKey("name") },
+		func() error { return writer.WriteNode(StringNode("", "<foo>")) },
+		func() error { return writer.WriteKey("items") },
+		writer.BeginArray,
+		writer.BeginArray,
+		writer.EndArray,
+		func() error { return writer.WriteNode(NullNode("")) },
+		writer.BeginObject,
+		writer.EndObject,
+		func() error { return writer.WriteNode(Must(Unmarshal([]byte(`[1, {"a": true}]`)))) },
+		writer.EndArray,
+		func() error { return writer.WriteKey("quoted \"key\"") },
+		writer.BeginObject,
+		func() error { return writer.WriteKey("x") },
+		func() error { return writer.WriteNode(NumericNode("", 1.5)) },
+		writer.EndObject,
+		writer.EndObject,
+		writer.Close,
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Errorf("Step %d: unexpected error: %s", i, err)
+			return
+		}
+	}
+	expected := `{"name":"\u003cfoo\u003e","items":[[],null,{},[1, {"a": true}]],"quoted \"key\"":{"x":1.5}}`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Wrong result\nExpected: %s\nActual:   %s", expected, actual)
+	}
+	if _, err := Unmarshal(buf.Bytes()); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %s", err)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("fail")
+}
+
+func TestStreamWriter_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(writer *StreamWriter) error
+		err   string
+	}{
+		{
+			name:  "empty",
+			steps: func(writer *StreamWriter) error { return writer.Close() },
+			err:   "wrong request: stream: unexpected end of data",
+		},
+		{
+			name: "not closed",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.BeginArray()
+				return writer.Close()
+			},
+			err: "wrong request: stream: unexpected end of data",
+		},
+		{
+			name: "two values",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.WriteNode(NullNode(""))
+				return writer.BeginArray()
+			},
+			err: "wrong request: stream: only one value is allowed on the top level",
+		},
+		{
+			name: "wrong end",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.BeginArray()
+				return writer.EndObject()
+			},
+			err: "wrong request: stream: unexpected '}'",
+		},
+		{
+			name:  "end on the top level",
+			steps: func(writer *StreamWriter) error { return writer.EndArray() },
+			err:   "wrong request: stream: unexpected ']'",
+		},
+		{
+			name: "value without key",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.BeginObject()
+				return writer.WriteNode(NullNode(""))
+			},
+			err: "wrong request: stream: key is expected",
+		},
+		{
+			name: "key without value",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.BeginObject()
+				_ = writer.WriteKey("a")
+				return writer.EndObject()
+			},
+			err: "wrong request: stream: unexpected '}'",
+		},
+		{
+			name: "two keys",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.BeginObject()
+				_ = writer.WriteKey("a")
+				return writer.WriteKey("b")
+			},
+			err: `wrong request: stream: unexpected key "b"`,
+		},
+		{
+			name: "key in array",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.BeginArray()
+				return writer.WriteKey("a")
+			},
+			err: `wrong request: stream: unexpected key "a"`,
+		},
+		{
+			name: "sticky error",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.EndArray()
+				_ = writer.BeginArray()
+				return writer.EndArray()
+			},
+			err: "wrong request: stream: unexpected ']'",
+		},
+		{
+			name: "wrong node",
+			steps: func(writer *StreamWriter) error {
+				_ = writer.BeginArray()
+				return writer.WriteNode(NumericNode("", math.NaN()))
+			},
+			err: "wrong request: non-finite number: NaN",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.steps(NewStreamWriter(new(bytes.Buffer)))
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Wrong error: %v, expected: %s", err, test.err)
+			}
+		})
+	}
+
+	writer := NewStreamWriter(failWriter{})
+	if err := writer.BeginArray(); err == nil || err.Error() != "fail" {
+		t.Errorf("Wrong error of the writer: %v", err)
+	}
+	if err := writer.EndArray(); err == nil || err.Error() != "fail" {
+		t.Errorf("Wrong sticky error of the writer: %v", err)
+	}
+}
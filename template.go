@@ -0,0 +1,75 @@
+package ajson
+
+import (
+	"text/template"
+)
+
+// TemplateFuncs returns functions for the text/template (or html/template) package, to use JSONPath inside the templates.
+//
+// Registered functions are:
+//
+// 	jsonpath     {{ jsonpath . "$.user.name" }}      returns unpacked value of the single found node, or unpacked values of all found nodes as []interface{}
+// 	jsonpathAll  {{ jsonpathAll . "$..name" }}       returns unpacked values of all found nodes as []interface{}
+// 	eval         {{ eval . "avg($..price)" }}         returns unpacked value of the evaluated expression
+//
+// Data for each function could be *Node, []byte or string with JSON.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"jsonpath": func(data interface{}, path string) (interface{}, error) {
+			values, err := templateJSONPath(data, path)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) == 1 {
+				return values[0], nil
+			}
+			return values, nil
+		},
+		"jsonpathAll": templateJSONPath,
+		"eval": func(data interface{}, expression string) (interface{}, error) {
+			root, err := templateNode(data)
+			if err != nil {
+				return nil, err
+			}
+			result, err := Eval(root, expression)
+			if err != nil {
+				return nil, err
+			}
+			return result.Unpack()
+		},
+	}
+}
+
+func templateJSONPath(data interface{}, path string) (result []interface{}, err error) {
+	root, err := templateNode(data)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := root.JSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	result = make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		result[i], err = node.Unpack()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func templateNode(data interface{}) (*Node, error) {
+	switch value := data.(type) {
+	case *Node:
+		if value == nil {
+			return nil, errorUnparsed()
+		}
+		return value, nil
+	case []byte:
+		return Unmarshal(value)
+	case string:
+		return Unmarshal([]byte(value))
+	}
+	return nil, errorType()
+}
@@ -0,0 +1,77 @@
+package ajson
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	const text = `{{ jsonpath . "$.store.bicycle.color" }}: {{ jsonpath . "$.store.bicycle.price" }}
+{{ range jsonpathAll . "$.store.book[?(@.price < 10)]" }}{{ .title }} by {{ .author }}
+{{ end }}{{ jsonpath . "$..isbn" }}
+{{ jsonpath . "$.foo" }}
+{{ eval . "round(avg($..book..price))" }}
+`
+	const expected = `red: 19.95
+Sayings of the Century by Nigel Rees
+Moby Dick by Herman Melville
+[0-553-21311-3 0-395-19395-8]
+[]
+13
+`
+	tmpl, err := template.New("test").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		t.Errorf("Parse() error: %s", err)
+		return
+	}
+	for name, data := range map[string]interface{}{
+		"node":   Must(Unmarshal(jsonPathTestData)),
+		"bytes":  jsonPathTestData,
+		"string": string(jsonPathTestData),
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := tmpl.Execute(buf, data); err != nil {
+				t.Errorf("Execute() error: %s", err)
+			} else if buf.String() != expected {
+				t.Errorf("Execute() wrong result:\nExpected: %q\nActual:   %q", expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestTemplateFuncs_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		data interface{}
+	}{
+		{name: "wrong data type", text: `{{ jsonpath . "$" }}`, data: 1},
+		{name: "nil node", text: `{{ jsonpath . "$" }}`, data: (*Node)(nil)},
+		{name: "wrong json", text: `{{ jsonpathAll . "$" }}`, data: `{`},
+		{name: "wrong path", text: `{{ jsonpath . "$[" }}`, data: `{}`},
+		{name: "wrong expression", text: `{{ eval . "foo(1)" }}`, data: `{}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("test").Funcs(TemplateFuncs()).Parse(test.text))
+			if err := tmpl.Execute(new(bytes.Buffer), test.data); err == nil {
+				t.Errorf("Execute() expected error")
+			}
+		})
+	}
+}
+
+func ExampleTemplateFuncs() {
+	tmpl := template.Must(template.New("example").Funcs(TemplateFuncs()).Parse(
+		`{{ range jsonpathAll . "$.store.book[*]" }}{{ .author }}: {{ .price }}{{ "\n" }}{{ end }}`,
+	))
+	_ = tmpl.Execute(os.Stdout, jsonPathTestData)
+	// Output:
+	// Nigel Rees: 8.95
+	// Evelyn Waugh: 12.99
+	// Herman Melville: 8.99
+	// J. R. R. Tolkien: 22.99
+}
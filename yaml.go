@@ -0,0 +1,150 @@
+package ajson
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// yamlReserved is a list of plain scalars, which will be resolved by YAML parsers as non string values
+var yamlReserved = map[string]bool{
+	"y": true, "n": true,
+	"yes": true, "no": true,
+	"on": true, "off": true,
+	"true": true, "false": true,
+	"null": true,
+}
+
+// ToYAML returns slice of bytes, with current value presented as YAML document in block style.
+//
+// Keys of objects are in order of Keys, like in Marshal, strings are presented as plain scalars if they can't be resolved as the other type, or as double-quoted scalars otherwise.
+func ToYAML(node *Node) (result []byte, err error) {
+	if node == nil {
+		return nil, errorUnparsed()
+	}
+	return yamlBlock(make([]byte, 0), node, "", "")
+}
+
+// MarshalYAML is implementation of yaml.Marshaler interface of the gopkg.in/yaml packages, returns unpacked value of the current node
+func (n *Node) MarshalYAML() (interface{}, error) {
+	return n.Unpack()
+}
+
+// yamlBlock appends node in block style: the first line starts with the first prefix, all the next lines starts with indent
+func yamlBlock(result []byte, node *Node, first, indent string) ([]byte, error) {
+	var err error
+	switch {
+	case node.IsObject() && !node.Empty():
+		for i, child := range node.Inheritors() {
+			if i == 0 {
+				result = append(result, first...)
+			} else {
+				result = append(result, indent...)
+			}
+			result = append(result, yamlString(child.Key())...)
+			result = append(result, colon)
+			if child.IsContainer() && !child.Empty() {
+				result = append(result, skipN)
+				result, err = yamlBlock(result, child, indent+"  ", indent+"  ")
+			} else {
+				result = append(result, skipS)
+				result, err = yamlScalar(result, child)
+				result = append(result, skipN)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	case node.IsArray() && !node.Empty():
+		for i, child := range node.Inheritors() {
+			if i == 0 {
+				result, err = yamlBlock(result, child, first+"- ", indent+"  ")
+			} else {
+				result, err = yamlBlock(result, child, indent+"- ", indent+"  ")
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		result = append(result, first...)
+		result, err = yamlScalar(result, node)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, skipN)
+	}
+	return result, nil
+}
+
+// yamlScalar appends scalar value or empty container in flow style
+func yamlScalar(result []byte, node *Node) ([]byte, error) {
+	switch node.Type() {
+	case Null:
+		result = append(result, _null...)
+	case Numeric:
+		value, err := node.GetNumeric()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, formatNumber(value)...)
+	case String:
+		value, err := node.GetString()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, yamlString(value)...)
+	case Bool:
+		value, err := node.GetBool()
+		if err != nil {
+			return nil, err
+		}
+		if value {
+			result = append(result, _true...)
+		} else {
+			result = append(result, _false...)
+		}
+	case Array:
+		result = append(result, bracketL, bracketR)
+	case Object:
+		result = append(result, bracesL, bracesR)
+	default:
+		return nil, errorType()
+	}
+	return result, nil
+}
+
+// formatNumber returns the whole number without the exponent, like `1234567`, and any other number in the shortest form, like `1.5e-7`
+func formatNumber(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// yamlString returns string as plain scalar if it is safe, or as double-quoted scalar
+func yamlString(value string) []byte {
+	if yamlPlain(value) {
+		return []byte(value)
+	}
+	result := make([]byte, 0, len(value)+2)
+	result = append(result, quotes)
+	result = append(result, quoteString(value, false)...)
+	return append(result, quotes)
+}
+
+func yamlPlain(value string) bool {
+	if value == "" || yamlReserved[strings.ToLower(value)] || value[len(value)-1] == skipS {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_':
+		case i != 0 && ((c >= '0' && c <= '9') || c == dot || c == minus || c == division || c == skipS):
+		default:
+			return false
+		}
+	}
+	return true
+}
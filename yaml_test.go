@@ -0,0 +1,84 @@
+package ajson
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestToYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{name: "null", json: `null`, expected: "null\n"},
+		{name: "numeric", json: `1.5e3`, expected: "1500\n"},
+		{name: "numeric large", json: `[1234567, -98765432100, 1e20, 1e21, 0.000001234]`, expected: "- 1234567\n- -98765432100\n- 100000000000000000000\n- 1e+21\n- 1.234e-06\n"},
+		{name: "bool", json: `true`, expected: "true\n"},
+		{name: "string", json: `"foo bar"`, expected: "foo bar\n"},
+		{name: "string empty", json: `""`, expected: "\"\"\n"},
+		{name: "string reserved", json: `"Yes"`, expected: "\"Yes\"\n"},
+		{name: "string numeric", json: `"123"`, expected: "\"123\"\n"},
+		{name: "string special", json: `"foo: bar # baz"`, expected: "\"foo: bar # baz\"\n"},
+		{name: "string escaped", json: `"foo\n\"bar\""`, expected: "\"foo\\n\\\"bar\\\"\"\n"},
+		{name: "string trailing space", json: `"foo "`, expected: "\"foo \"\n"},
+		{name: "empty array", json: `[]`, expected: "[]\n"},
+		{name: "empty object", json: `{}`, expected: "{}\n"},
+		{name: "array", json: `[1,"a",null]`, expected: "- 1\n- a\n- null\n"},
+		{name: "object", json: `{"b":1,"a":"c"}`, expected: "b: 1\na: c\n"},
+		{name: "nested arrays", json: `[[1,2],[],[[3]]]`, expected: "- - 1\n  - 2\n- []\n- - - 3\n"},
+		{name: "objects in array", json: `[{"a":1,"b":{"c":[true]}},{}]`, expected: "- a: 1\n  b:\n    c:\n      - true\n- {}\n"},
+		{name: "keys", json: `{"":1,"foo bar":2,"1":3,"null":4}`, expected: "\"\": 1\nfoo bar: 2\n\"1\": 3\n\"null\": 4\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ToYAML(Must(Unmarshal([]byte(test.json))))
+			if err != nil {
+				t.Errorf("ToYAML() error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("ToYAML() wrong result:\nExpected: %q\nActual:   %q", test.expected, string(result))
+			}
+		})
+	}
+}
+
+func TestToYAML_errors(t *testing.T) {
+	if _, err := ToYAML(nil); err == nil {
+		t.Errorf("ToYAML() expected error on nil")
+	}
+	if _, err := ToYAML(ArrayNode("", []*Node{valueNode(nil, "", Numeric, "foo")})); err == nil {
+		t.Errorf("ToYAML() expected error on wrong value")
+	}
+}
+
+func TestNode_MarshalYAML(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo":[1,{"bar":null}],"baz":"qux"}`)))
+	value, err := root.MarshalYAML()
+	if err != nil {
+		t.Errorf("MarshalYAML() error: %s", err)
+		return
+	}
+	expected, _ := root.Unpack()
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("MarshalYAML() wrong result:\nExpected: %#+v\nActual:   %#+v", expected, value)
+	}
+}
+
+func ExampleToYAML() {
+	root := Must(Unmarshal(jsonPathTestData))
+	nodes, _ := root.JSONPath("$.store.book[?(@.isbn)]")
+	result, _ := ToYAML(ArrayNode("", nodes))
+	fmt.Printf("%s", result)
+	// Output:
+	// - category: fiction
+	//   author: Herman Melville
+	//   title: Moby Dick
+	//   isbn: "0-553-21311-3"
+	//   price: 8.99
+	// - category: fiction
+	//   author: J. R. R. Tolkien
+	//   title: The Lord of the Rings
+	//   isbn: "0-395-19395-8"
+	//   price: 22.99
+}